@@ -8,11 +8,14 @@ import (
 )
 
 type Config struct {
-	Api      Api
-	Database Database
-	Redis    Redis
-	RabbitMQ RabbitMQ
-	Mailjet  Mailjet
+	Api       Api
+	Database  Database
+	Redis     Redis
+	RabbitMQ  RabbitMQ
+	Mailjet   Mailjet
+	Links     Links
+	Telegram  Telegram
+	PagerDuty PagerDuty
 }
 
 // New loads environment variables from the root file (.env or .env.test if testing is set to true) and returns
@@ -36,10 +39,13 @@ func New(testing bool) Config {
 	}
 
 	return Config{
-		Api:      apiCfg(),
-		Database: databaseCfg(),
-		Redis:    redisCfg(),
-		RabbitMQ: rabbitMQCfg(),
-		Mailjet:  mailjetCfg(),
+		Api:       apiCfg(),
+		Database:  databaseCfg(),
+		Redis:     redisCfg(),
+		RabbitMQ:  rabbitMQCfg(),
+		Mailjet:   mailjetCfg(),
+		Links:     linksCfg(),
+		Telegram:  telegramCfg(),
+		PagerDuty: pagerDutyCfg(),
 	}
 }
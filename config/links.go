@@ -0,0 +1,25 @@
+package config
+
+import (
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+// Links configures links to the API which are sent to users, e.g. in notification emails
+type Links struct {
+	BaseURL string        `default:"http://localhost:3000" split_words:"true"` // public URL of the API
+	Secret  string        `required:"true"`                                    // key of links signatures
+	TTL     time.Duration `default:"168h"`                                     // time the signed links are valid for
+}
+
+func linksCfg() Links {
+	var links Links
+	envconfig.MustProcess("LINKS", &links)
+	// envconfig accepts set but empty variable, and links signed with empty key could be forged by anyone
+	if links.Secret == "" {
+		log.Fatal().Msg("LINKS_SECRET must not be empty")
+	}
+
+	return links
+}
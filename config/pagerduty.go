@@ -0,0 +1,17 @@
+package config
+
+import (
+	"github.com/kelseyhightower/envconfig"
+)
+
+// PagerDuty configures Events API v2 which events of pagerduty channels are sent to
+type PagerDuty struct {
+	EventsURL string `default:"https://events.pagerduty.com/v2/enqueue" split_words:"true"` // URL of the events endpoint
+}
+
+func pagerDutyCfg() PagerDuty {
+	var pagerDuty PagerDuty
+	envconfig.MustProcess("PAGERDUTY", &pagerDuty)
+
+	return pagerDuty
+}
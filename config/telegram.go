@@ -0,0 +1,17 @@
+package config
+
+import (
+	"github.com/kelseyhightower/envconfig"
+)
+
+// Telegram configures Bot API which messages of telegram channels are sent through
+type Telegram struct {
+	ApiURL string `default:"https://api.telegram.org" split_words:"true"` // base URL of the Bot API
+}
+
+func telegramCfg() Telegram {
+	var telegram Telegram
+	envconfig.MustProcess("TELEGRAM", &telegram)
+
+	return telegram
+}
@@ -0,0 +1,6 @@
+ALTER TABLE checks
+    DROP COLUMN schedule,
+    DROP COLUMN cron,
+    DROP COLUMN timezone;
+
+DROP TYPE IF EXISTS schedule_kind;
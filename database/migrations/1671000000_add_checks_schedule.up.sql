@@ -0,0 +1,6 @@
+CREATE TYPE schedule_kind AS ENUM ('simple', 'cron');
+
+ALTER TABLE checks
+    ADD COLUMN schedule schedule_kind NOT NULL DEFAULT 'simple',
+    ADD COLUMN cron     varchar(100)  NOT NULL DEFAULT '',
+    ADD COLUMN timezone varchar(64)   NOT NULL DEFAULT 'UTC';
@@ -0,0 +1,7 @@
+ALTER TABLE flips
+    DROP COLUMN reason;
+
+DROP TYPE IF EXISTS flip_reason;
+
+ALTER TABLE checks
+    DROP COLUMN max_duration;
@@ -0,0 +1,7 @@
+ALTER TABLE checks
+    ADD COLUMN max_duration integer;
+
+CREATE TYPE flip_reason AS ENUM ('missed', 'fail', 'timeout');
+
+ALTER TABLE flips
+    ADD COLUMN reason flip_reason;
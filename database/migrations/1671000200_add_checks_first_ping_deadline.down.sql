@@ -0,0 +1,5 @@
+ALTER TABLE checks
+    DROP COLUMN created_at,
+    DROP COLUMN first_ping_deadline;
+
+-- enum values can't be dropped, so flip_reason keeps 'no_first_ping'
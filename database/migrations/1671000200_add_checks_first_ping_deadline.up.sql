@@ -0,0 +1,5 @@
+ALTER TABLE checks
+    ADD COLUMN created_at          timestamptz NOT NULL DEFAULT current_timestamp,
+    ADD COLUMN first_ping_deadline integer;
+
+ALTER TYPE flip_reason ADD VALUE 'no_first_ping';
@@ -0,0 +1,7 @@
+ALTER TABLE checks
+    DROP COLUMN pause_policy,
+    DROP COLUMN resume_at;
+
+DROP TYPE IF EXISTS pause_policy;
+
+-- enum values can't be dropped, so flip_state keeps 'resumed'
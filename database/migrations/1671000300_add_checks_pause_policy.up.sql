@@ -0,0 +1,7 @@
+CREATE TYPE pause_policy AS ENUM ('resume', 'ignore');
+
+ALTER TABLE checks
+    ADD COLUMN pause_policy pause_policy NOT NULL DEFAULT 'resume',
+    ADD COLUMN resume_at    timestamptz;
+
+ALTER TYPE flip_state ADD VALUE 'resumed';
@@ -0,0 +1,5 @@
+ALTER TABLE checks
+    DROP COLUMN slug;
+
+ALTER TABLE users
+    DROP COLUMN ping_key;
@@ -0,0 +1,6 @@
+ALTER TABLE users
+    ADD COLUMN ping_key text UNIQUE NOT NULL DEFAULT replace(gen_random_uuid()::text, '-', '');
+
+ALTER TABLE checks
+    ADD COLUMN slug varchar(100),
+    ADD UNIQUE (used_id, slug);
@@ -0,0 +1,5 @@
+ALTER TABLE flips
+    DROP COLUMN exit_code;
+
+ALTER TABLE pings
+    DROP COLUMN exit_code;
@@ -0,0 +1,5 @@
+ALTER TABLE pings
+    ADD COLUMN exit_code integer;
+
+ALTER TABLE flips
+    ADD COLUMN exit_code integer;
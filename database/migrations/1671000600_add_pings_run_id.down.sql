@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS pings_check_id_run_id_idx;
+
+ALTER TABLE pings
+    DROP COLUMN run_id;
@@ -0,0 +1,4 @@
+ALTER TABLE pings
+    ADD COLUMN run_id uuid;
+
+CREATE INDEX pings_check_id_run_id_idx ON pings (check_id, run_id);
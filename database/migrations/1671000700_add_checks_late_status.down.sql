@@ -0,0 +1,5 @@
+ALTER TABLE channels
+    DROP COLUMN notify_late;
+
+-- enum values can't be dropped, so check_status keeps 'late'
+UPDATE checks SET status = 'up' WHERE status = 'late';
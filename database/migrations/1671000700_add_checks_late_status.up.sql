@@ -0,0 +1,4 @@
+ALTER TYPE check_status ADD VALUE 'late';
+
+ALTER TABLE channels
+    ADD COLUMN notify_late boolean NOT NULL DEFAULT false;
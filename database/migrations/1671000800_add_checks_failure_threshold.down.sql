@@ -0,0 +1,3 @@
+ALTER TABLE checks
+    DROP COLUMN failure_threshold,
+    DROP COLUMN failures;
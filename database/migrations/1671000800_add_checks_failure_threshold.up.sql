@@ -0,0 +1,3 @@
+ALTER TABLE checks
+    ADD COLUMN failure_threshold integer NOT NULL DEFAULT 1,
+    ADD COLUMN failures          integer NOT NULL DEFAULT 0;
@@ -0,0 +1,10 @@
+ALTER TABLE flips
+    DROP COLUMN suppressed;
+
+ALTER TABLE checks
+    DROP COLUMN flap_threshold,
+    DROP COLUMN flap_window,
+    DROP COLUMN flap_stable,
+    DROP COLUMN flapping_since;
+
+-- enum values can't be dropped, so flip_state keeps 'flapping' and 'stable'
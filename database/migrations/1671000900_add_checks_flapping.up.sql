@@ -0,0 +1,11 @@
+ALTER TABLE checks
+    ADD COLUMN flap_threshold integer,
+    ADD COLUMN flap_window    integer NOT NULL DEFAULT 3600,
+    ADD COLUMN flap_stable    integer NOT NULL DEFAULT 3600,
+    ADD COLUMN flapping_since timestamptz;
+
+ALTER TYPE flip_state ADD VALUE 'flapping';
+ALTER TYPE flip_state ADD VALUE 'stable';
+
+ALTER TABLE flips
+    ADD COLUMN suppressed bool NOT NULL DEFAULT false;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS maintenance_windows_checks;
+
+DROP TABLE IF EXISTS maintenance_windows;
+
+ALTER TABLE checks
+    DROP COLUMN tags;
@@ -0,0 +1,26 @@
+ALTER TABLE checks
+    ADD COLUMN tags varchar(500) NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS maintenance_windows
+(
+    id         int GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    name       varchar(255) NOT NULL,
+    starts_at  timestamptz,
+    cron       varchar(100) NOT NULL DEFAULT '',
+    timezone   varchar(64)  NOT NULL DEFAULT 'UTC',
+    duration   integer      NOT NULL,
+    tags       varchar(500) NOT NULL DEFAULT '',
+    next_start timestamptz,
+    next_end   timestamptz,
+    user_id    integer      NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS maintenance_windows_checks
+(
+    window_id int  NOT NULL,
+    check_id  uuid NOT NULL,
+    PRIMARY KEY (window_id, check_id),
+    FOREIGN KEY (window_id) REFERENCES maintenance_windows (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (check_id) REFERENCES checks (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS escalations;
+
+ALTER TABLE checks
+    DROP COLUMN escalation_policy_id;
+
+DROP TABLE IF EXISTS escalation_steps;
+
+DROP TABLE IF EXISTS escalation_policies;
@@ -0,0 +1,37 @@
+CREATE TABLE IF NOT EXISTS escalation_policies
+(
+    id      int GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    name    varchar(255) NOT NULL,
+    user_id integer      NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS escalation_steps
+(
+    policy_id  int     NOT NULL,
+    position   int     NOT NULL,
+    delay      integer NOT NULL,
+    channel_id int     NOT NULL,
+    PRIMARY KEY (policy_id, position),
+    FOREIGN KEY (policy_id) REFERENCES escalation_policies (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+ALTER TABLE checks
+    ADD COLUMN escalation_policy_id int REFERENCES escalation_policies (id) ON DELETE SET NULL ON UPDATE CASCADE;
+
+CREATE TABLE IF NOT EXISTS escalations
+(
+    id         int GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    check_id   uuid        NOT NULL,
+    flip_id    int         NOT NULL,
+    policy_id  int         NOT NULL,
+    started_at timestamptz NOT NULL,
+    next_step  int         NOT NULL DEFAULT 0,
+    closed     bool        NOT NULL DEFAULT false,
+    FOREIGN KEY (check_id) REFERENCES checks (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (flip_id) REFERENCES flips (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (policy_id) REFERENCES escalation_policies (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE INDEX escalations_open_idx ON escalations (check_id) WHERE closed = false;
@@ -0,0 +1,11 @@
+ALTER TABLE checks_channels
+    DROP COLUMN reminders,
+    DROP COLUMN last_reminder;
+
+ALTER TABLE channels
+    DROP COLUMN reminder_interval;
+
+ALTER TABLE checks
+    DROP COLUMN reminder_interval,
+    DROP COLUMN reminder_limit,
+    DROP COLUMN reminders_stopped_at;
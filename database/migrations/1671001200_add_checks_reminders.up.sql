@@ -0,0 +1,11 @@
+ALTER TABLE checks
+    ADD COLUMN reminder_interval    integer,
+    ADD COLUMN reminder_limit       integer NOT NULL DEFAULT 10,
+    ADD COLUMN reminders_stopped_at timestamptz;
+
+ALTER TABLE channels
+    ADD COLUMN reminder_interval integer;
+
+ALTER TABLE checks_channels
+    ADD COLUMN reminders     integer NOT NULL DEFAULT 0,
+    ADD COLUMN last_reminder timestamptz;
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS incidents
+(
+    id              int GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    check_id        uuid        NOT NULL,
+    cause           flip_reason,
+    started_at      timestamptz NOT NULL,
+    ended_at        timestamptz,
+    acknowledged_at timestamptz,
+    acknowledged_by varchar(255),
+    FOREIGN KEY (check_id) REFERENCES checks (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE INDEX incidents_check_id_started_at_idx ON incidents (check_id, started_at);
+
+CREATE TABLE IF NOT EXISTS incident_notes
+(
+    id          int GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    incident_id int           NOT NULL,
+    "text"      varchar(2000) NOT NULL,
+    author      varchar(255)  NOT NULL,
+    created_at  timestamptz   NOT NULL DEFAULT current_timestamp,
+    FOREIGN KEY (incident_id) REFERENCES incidents (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- derive incidents from down flips which already exist, each ends with the next up flip of the check
+INSERT INTO incidents (check_id, cause, started_at, ended_at)
+SELECT d.check_id,
+       d.reason,
+       d.date,
+       (SELECT min(u.date) FROM flips u WHERE u.check_id = d.check_id AND u."to" = 'up' AND u.date > d.date)
+FROM flips d
+WHERE d."to" = 'down';
@@ -0,0 +1,9 @@
+DROP INDEX pings_slow_unprocessed_idx;
+
+ALTER TABLE pings
+    DROP COLUMN slow_threshold,
+    DROP COLUMN slow_processed;
+
+ALTER TABLE checks
+    DROP COLUMN slow_duration,
+    DROP COLUMN slow_factor;
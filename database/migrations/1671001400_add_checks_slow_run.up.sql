@@ -0,0 +1,9 @@
+ALTER TABLE checks
+    ADD COLUMN slow_duration integer,
+    ADD COLUMN slow_factor   double precision;
+
+ALTER TABLE pings
+    ADD COLUMN slow_threshold integer,
+    ADD COLUMN slow_processed boolean NOT NULL DEFAULT false;
+
+CREATE INDEX pings_slow_unprocessed_idx ON pings (id) WHERE slow_threshold IS NOT NULL AND NOT slow_processed;
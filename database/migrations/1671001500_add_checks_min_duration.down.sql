@@ -0,0 +1,17 @@
+DROP INDEX pings_warning_unprocessed_idx;
+
+ALTER TABLE pings
+    RENAME COLUMN warning_processed TO slow_processed;
+
+ALTER TABLE pings
+    DROP COLUMN fast_threshold;
+
+CREATE INDEX pings_slow_unprocessed_idx ON pings (id) WHERE slow_threshold IS NOT NULL AND NOT slow_processed;
+
+ALTER TABLE checks
+    DROP COLUMN min_duration,
+    DROP COLUMN min_duration_action;
+
+DROP TYPE IF EXISTS min_duration_action;
+
+-- enum values can't be dropped, so flip_reason keeps 'too_fast'
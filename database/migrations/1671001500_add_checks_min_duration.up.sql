@@ -0,0 +1,19 @@
+CREATE TYPE min_duration_action AS ENUM ('fail', 'warn');
+
+ALTER TABLE checks
+    ADD COLUMN min_duration        integer,
+    ADD COLUMN min_duration_action min_duration_action NOT NULL DEFAULT 'fail';
+
+-- success pings which have finished too fast runs are warned about along with slow ones
+DROP INDEX pings_slow_unprocessed_idx;
+
+ALTER TABLE pings
+    ADD COLUMN fast_threshold integer;
+
+ALTER TABLE pings
+    RENAME COLUMN slow_processed TO warning_processed;
+
+CREATE INDEX pings_warning_unprocessed_idx ON pings (id)
+    WHERE (slow_threshold IS NOT NULL OR (fast_threshold IS NOT NULL AND "type" = 'success')) AND NOT warning_processed;
+
+ALTER TYPE flip_reason ADD VALUE 'too_fast';
@@ -0,0 +1,6 @@
+ALTER TABLE pings
+    DROP COLUMN body_rule;
+
+DROP TABLE IF EXISTS body_rules;
+
+DROP TYPE IF EXISTS body_rule_match;
@@ -0,0 +1,16 @@
+CREATE TYPE body_rule_match AS ENUM ('substring', 'regex');
+
+CREATE TABLE IF NOT EXISTS body_rules
+(
+    check_id uuid            NOT NULL,
+    position int             NOT NULL,
+    match    body_rule_match NOT NULL,
+    pattern  varchar(500)    NOT NULL,
+    outcome  ping_type       NOT NULL,
+    PRIMARY KEY (check_id, position),
+    FOREIGN KEY (check_id) REFERENCES checks (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- rule which has decided outcome of the ping
+ALTER TABLE pings
+    ADD COLUMN body_rule jsonb;
@@ -0,0 +1,10 @@
+ALTER TABLE pings
+    DROP COLUMN metric_rule;
+
+DROP TABLE IF EXISTS metric_values;
+
+DROP TABLE IF EXISTS metric_rules;
+
+DROP TYPE IF EXISTS metric_op;
+
+DROP TABLE IF EXISTS check_metrics;
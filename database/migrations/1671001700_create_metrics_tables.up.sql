@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS check_metrics
+(
+    check_id uuid         NOT NULL,
+    position int          NOT NULL,
+    name     varchar(64)  NOT NULL,
+    path     varchar(255) NOT NULL,
+    PRIMARY KEY (check_id, position),
+    UNIQUE (check_id, name),
+    FOREIGN KEY (check_id) REFERENCES checks (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TYPE metric_op AS ENUM ('lt', 'lte', 'gt', 'gte', 'eq', 'ne');
+
+CREATE TABLE IF NOT EXISTS metric_rules
+(
+    check_id  uuid             NOT NULL,
+    position  int              NOT NULL,
+    metric    varchar(64)      NOT NULL,
+    op        metric_op        NOT NULL,
+    threshold double precision NOT NULL,
+    outcome   ping_type        NOT NULL,
+    PRIMARY KEY (check_id, position),
+    FOREIGN KEY (check_id) REFERENCES checks (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS metric_values
+(
+    check_id uuid             NOT NULL,
+    name     varchar(64)      NOT NULL,
+    value    double precision NOT NULL,
+    date     timestamptz      NOT NULL,
+    FOREIGN KEY (check_id) REFERENCES checks (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE INDEX metric_values_check_id_name_date_idx ON metric_values (check_id, name, date);
+
+-- rule which has decided outcome of the ping
+ALTER TABLE pings
+    ADD COLUMN metric_rule jsonb;
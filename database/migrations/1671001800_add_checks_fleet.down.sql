@@ -0,0 +1,13 @@
+ALTER TABLE pings
+    DROP COLUMN instance;
+
+DROP TABLE IF EXISTS check_instances;
+
+DROP TYPE IF EXISTS instance_status;
+
+ALTER TABLE checks
+    DROP COLUMN fleet,
+    DROP COLUMN fleet_tolerance,
+    DROP COLUMN instance_ttl;
+
+-- enum values can't be dropped, so flip_reason keeps 'instances_missing'
@@ -0,0 +1,23 @@
+ALTER TABLE checks
+    ADD COLUMN fleet           boolean NOT NULL DEFAULT false,
+    ADD COLUMN fleet_tolerance integer NOT NULL DEFAULT 0,
+    ADD COLUMN instance_ttl    integer NOT NULL DEFAULT 86400;
+
+CREATE TYPE instance_status AS ENUM ('up', 'down');
+
+CREATE TABLE IF NOT EXISTS check_instances
+(
+    check_id  uuid            NOT NULL,
+    name      varchar(100)    NOT NULL,
+    status    instance_status NOT NULL,
+    last_ping timestamptz     NOT NULL,
+    next_ping timestamptz,
+    PRIMARY KEY (check_id, name),
+    FOREIGN KEY (check_id) REFERENCES checks (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- instance which has sent the ping, set for pings of fleet checks only
+ALTER TABLE pings
+    ADD COLUMN instance varchar(100);
+
+ALTER TYPE flip_reason ADD VALUE 'instances_missing';
@@ -0,0 +1,5 @@
+ALTER TABLE channels
+    DROP COLUMN webhook_url,
+    DROP COLUMN webhook_method,
+    DROP COLUMN webhook_headers,
+    DROP COLUMN webhook_body;
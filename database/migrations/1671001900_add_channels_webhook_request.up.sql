@@ -0,0 +1,7 @@
+-- webhook_url replaces webhook_url_up and webhook_url_down for all notifications if set, the body template tells
+-- them apart
+ALTER TABLE channels
+    ADD COLUMN webhook_url     text,
+    ADD COLUMN webhook_method  varchar(10) NOT NULL DEFAULT 'GET',
+    ADD COLUMN webhook_headers jsonb,
+    ADD COLUMN webhook_body    text;
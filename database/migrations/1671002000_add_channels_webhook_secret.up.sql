@@ -0,0 +1,4 @@
+-- webhook requests are signed with the secret, so receivers can verify they come from the channel
+ALTER TABLE channels
+    ADD COLUMN webhook_secret text NOT NULL DEFAULT
+        replace(gen_random_uuid()::text, '-', '') || replace(gen_random_uuid()::text, '-', '');
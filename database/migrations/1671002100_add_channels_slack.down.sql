@@ -0,0 +1,4 @@
+ALTER TABLE channels
+    DROP COLUMN slack_webhook_url;
+
+-- enum values can't be dropped, so channel_kind keeps 'slack'
@@ -0,0 +1,5 @@
+ALTER TYPE channel_kind ADD VALUE 'slack';
+
+-- incoming webhook of slack channel, messages are posted to it
+ALTER TABLE channels
+    ADD COLUMN slack_webhook_url text;
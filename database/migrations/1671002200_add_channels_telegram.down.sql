@@ -0,0 +1,5 @@
+ALTER TABLE channels
+    DROP COLUMN telegram_bot_token,
+    DROP COLUMN telegram_chat_id;
+
+-- enum values can't be dropped, so channel_kind keeps 'telegram'
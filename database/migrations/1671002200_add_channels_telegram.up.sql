@@ -0,0 +1,6 @@
+ALTER TYPE channel_kind ADD VALUE 'telegram';
+
+-- telegram channel sends messages to the chat through the bot
+ALTER TABLE channels
+    ADD COLUMN telegram_bot_token text,
+    ADD COLUMN telegram_chat_id   text;
@@ -0,0 +1,9 @@
+ALTER TABLE checks
+    DROP COLUMN severity;
+
+DROP TYPE IF EXISTS check_severity;
+
+ALTER TABLE channels
+    DROP COLUMN pagerduty_routing_key;
+
+-- enum values can't be dropped, so channel_kind keeps 'pagerduty'
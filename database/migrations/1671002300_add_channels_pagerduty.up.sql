@@ -0,0 +1,11 @@
+ALTER TYPE channel_kind ADD VALUE 'pagerduty';
+
+-- events of pagerduty channel are sent to the service integration with the routing key
+ALTER TABLE channels
+    ADD COLUMN pagerduty_routing_key text;
+
+CREATE TYPE check_severity AS ENUM ('critical', 'error', 'warning', 'info');
+
+-- severity of incidents which check's down flips trigger in pagerduty
+ALTER TABLE checks
+    ADD COLUMN severity check_severity NOT NULL DEFAULT 'critical';
@@ -0,0 +1,3 @@
+-- status which started check had before its run started, failures of the run below the threshold restore it
+ALTER TABLE checks
+    ADD COLUMN status_before_start check_status;
@@ -14,3 +14,7 @@ type CheckResponse struct {
 	Id    int    `json:"id" validate:"required"`
 	Email string `json:"email" validate:"required"`
 }
+
+type PingKeyResponse struct {
+	PingKey string `json:"pingKey" validate:"required"`
+}
@@ -25,6 +25,8 @@ func RegisterHandler(router *chi.Mux, service *service, validator *validate.Vali
 		router.Put("/signup", h.SignUp)
 		router.With(authMiddleware).Put("/signout", h.SignOut)
 		router.With(authMiddleware).Get("/check", h.Check)
+		router.With(authMiddleware).Get("/ping-key", h.GetPingKey)
+		router.With(authMiddleware).Put("/ping-key", h.RegeneratePingKey)
 	})
 }
 
@@ -125,3 +127,43 @@ func (h handler) Check(w http.ResponseWriter, r *http.Request) {
 	user := session.User(r.Context())
 	respond.JSON(r.Context(), w, http.StatusOK, CheckResponse{Id: user.Id, Email: user.Email})
 }
+
+// GetPingKey returns user's ping key
+// @Tags Auth
+// @Summary Get ping key
+// @Description Ping key is used in ping URLs like /v1/ping/{pingKey}/{slug}
+// @Security cookieAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} PingKeyResponse
+// @router /v1/auth/ping-key [get]
+func (h handler) GetPingKey(w http.ResponseWriter, r *http.Request) {
+	user := session.User(r.Context())
+	pingKey, err := h.service.GetPingKey(r.Context(), user.Id)
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	respond.JSON(r.Context(), w, http.StatusOK, PingKeyResponse{PingKey: pingKey})
+}
+
+// RegeneratePingKey replaces user's ping key with a new one
+// @Tags Auth
+// @Summary Regenerate ping key
+// @Description Ping URLs with the old key stop working
+// @Security cookieAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} PingKeyResponse
+// @router /v1/auth/ping-key [put]
+func (h handler) RegeneratePingKey(w http.ResponseWriter, r *http.Request) {
+	user := session.User(r.Context())
+	pingKey, err := h.service.RegeneratePingKey(r.Context(), user.Id)
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	respond.JSON(r.Context(), w, http.StatusOK, PingKeyResponse{PingKey: pingKey})
+}
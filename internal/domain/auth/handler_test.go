@@ -210,3 +210,33 @@ func TestHandler_Check_AuthorizedUser(t *testing.T) {
 		t.Errorf("want user email %v, got %v", dto.Email, user.Email)
 	}
 }
+
+func TestHandler_RegeneratePingKey(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+
+	req, _ := http.NewRequest("GET", "/v1/auth/ping-key", nil)
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var old auth.PingKeyResponse
+	err := json.Unmarshal(response.Body.Bytes(), &old)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ = http.NewRequest("PUT", "/v1/auth/ping-key", nil)
+	req.Header.Set("Cookie", cookie)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var regenerated auth.PingKeyResponse
+	err = json.Unmarshal(response.Body.Bytes(), &regenerated)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(regenerated.PingKey) != 32 || regenerated.PingKey == old.PingKey {
+		t.Errorf("want new 32 characters ping key, got %q (old %q)", regenerated.PingKey, old.PingKey)
+	}
+}
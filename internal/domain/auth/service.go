@@ -60,3 +60,11 @@ func (svc *service) SignIn(ctx context.Context, user SignInBody) (string, error)
 func (svc *service) SignOut(ctx context.Context, sessionId string) error {
 	return svc.sessionRepo.Destroy(ctx, sessionId)
 }
+
+func (svc *service) GetPingKey(ctx context.Context, userId int) (string, error) {
+	return svc.r.User.GetPingKey(ctx, userId)
+}
+
+func (svc *service) RegeneratePingKey(ctx context.Context, userId int) (string, error) {
+	return svc.r.User.RegeneratePingKey(ctx, userId)
+}
@@ -3,10 +3,20 @@ package channel
 import "gitlab.com/grygoryz/uptime-checker/internal/entity"
 
 type CreateChannelBody struct {
-	Kind           entity.ChannelKind `json:"kind" validate:"required,oneof=email webhook"`
-	Email          string             `json:"email" validate:"required_if=Kind email,omitempty,email"`
-	WebhookURLUp   string             `json:"webhookURLUp" validate:"required_if=Kind webhook,omitempty"`
-	WebhookURLDown string             `json:"webhookURLDown" validate:"required_if=Kind webhook,omitempty"`
+	Kind                entity.ChannelKind `json:"kind" validate:"required,oneof=email webhook slack telegram pagerduty"`
+	Email               string             `json:"email" validate:"required_if=Kind email,omitempty,email"`
+	WebhookURLUp        string             `json:"webhookURLUp"`                                                       // required for webhook without single url
+	WebhookURLDown      string             `json:"webhookURLDown"`                                                     // required for webhook without single url
+	WebhookURL          string             `json:"webhookURL"`                                                         // replaces up and down urls for all notifications
+	WebhookMethod       string             `json:"webhookMethod" validate:"omitempty,oneof=GET POST PUT PATCH DELETE"` // GET by default
+	WebhookHeaders      map[string]string  `json:"webhookHeaders" validate:"omitempty,max=20,dive,keys,required,max=100,endkeys,max=1000"`
+	WebhookBody         string             `json:"webhookBody" validate:"omitempty,max=10000"`                      // text/template with .CheckId, .CheckName, .Status, .Reason, .FlipDate and .LastPingBody, json function encodes value as JSON string
+	SlackWebhookURL     string             `json:"slackWebhookURL" validate:"required_if=Kind slack,omitempty,url"` // incoming webhook of slack channel
+	TelegramBotToken    string             `json:"telegramBotToken" validate:"required_if=Kind telegram,omitempty,telegram_token"`
+	TelegramChatId      string             `json:"telegramChatId" validate:"required_if=Kind telegram,omitempty,telegram_chat"`         // numeric id of the chat or @username of the channel
+	PagerDutyRoutingKey string             `json:"pagerDutyRoutingKey" validate:"required_if=Kind pagerduty,omitempty,len=32,alphanum"` // integration key of Events API v2 integration of the service
+	NotifyLate          bool               `json:"notifyLate"`                                                                          // send "running late" notifications
+	ReminderInterval    *int               `json:"reminderInterval" validate:"omitempty,min=300,max=604800"`                            // seconds between "still down" reminders, used if check has no interval
 }
 
 type CreateChannelResponse struct {
@@ -14,16 +24,41 @@ type CreateChannelResponse struct {
 }
 
 type UpdateChannelBody struct {
-	Kind           entity.ChannelKind `json:"kind" validate:"required,oneof=email webhook"`
-	Email          string             `json:"email" validate:"required_if=Kind email,omitempty,email"`
-	WebhookURLUp   string             `json:"webhookURLUp" validate:"required_if=Kind webhook,omitempty"`
-	WebhookURLDown string             `json:"webhookURLDown" validate:"required_if=Kind webhook,omitempty"`
+	Kind                entity.ChannelKind `json:"kind" validate:"required,oneof=email webhook slack telegram pagerduty"`
+	Email               string             `json:"email" validate:"required_if=Kind email,omitempty,email"`
+	WebhookURLUp        string             `json:"webhookURLUp"`                                                       // required for webhook without single url
+	WebhookURLDown      string             `json:"webhookURLDown"`                                                     // required for webhook without single url
+	WebhookURL          string             `json:"webhookURL"`                                                         // replaces up and down urls for all notifications
+	WebhookMethod       string             `json:"webhookMethod" validate:"omitempty,oneof=GET POST PUT PATCH DELETE"` // GET by default
+	WebhookHeaders      map[string]string  `json:"webhookHeaders" validate:"omitempty,max=20,dive,keys,required,max=100,endkeys,max=1000"`
+	WebhookBody         string             `json:"webhookBody" validate:"omitempty,max=10000"`                      // text/template with .CheckId, .CheckName, .Status, .Reason, .FlipDate and .LastPingBody, json function encodes value as JSON string
+	SlackWebhookURL     string             `json:"slackWebhookURL" validate:"required_if=Kind slack,omitempty,url"` // incoming webhook of slack channel
+	TelegramBotToken    string             `json:"telegramBotToken" validate:"required_if=Kind telegram,omitempty,telegram_token"`
+	TelegramChatId      string             `json:"telegramChatId" validate:"required_if=Kind telegram,omitempty,telegram_chat"`         // numeric id of the chat or @username of the channel
+	PagerDutyRoutingKey string             `json:"pagerDutyRoutingKey" validate:"required_if=Kind pagerduty,omitempty,len=32,alphanum"` // integration key of Events API v2 integration of the service
+	NotifyLate          bool               `json:"notifyLate"`                                                                          // send "running late" notifications
+	ReminderInterval    *int               `json:"reminderInterval" validate:"omitempty,min=300,max=604800"`                            // seconds between "still down" reminders, used if check has no interval
 }
 
 type GetChannelsResponseItem struct {
-	Id             int                `json:"id" validate:"required"`
-	Kind           entity.ChannelKind `json:"kind" validate:"required"`
-	Email          *string            `json:"email,omitempty"`
-	WebhookURLUp   *string            `json:"webhookURLUp,omitempty"`
-	WebhookURLDown *string            `json:"webhookURLDown,omitempty"`
+	Id                  int                `json:"id" validate:"required"`
+	Kind                entity.ChannelKind `json:"kind" validate:"required"`
+	Email               *string            `json:"email,omitempty"`
+	WebhookURLUp        *string            `json:"webhookURLUp,omitempty"`
+	WebhookURLDown      *string            `json:"webhookURLDown,omitempty"`
+	WebhookURL          *string            `json:"webhookURL,omitempty"`
+	WebhookMethod       string             `json:"webhookMethod,omitempty"`
+	WebhookHeaders      map[string]string  `json:"webhookHeaders,omitempty"`
+	WebhookBody         *string            `json:"webhookBody,omitempty"`
+	WebhookSecret       string             `json:"webhookSecret,omitempty"` // key of HMAC-SHA256 signatures of webhook requests
+	SlackWebhookURL     *string            `json:"slackWebhookURL,omitempty"`
+	TelegramBotToken    *string            `json:"telegramBotToken,omitempty"`
+	TelegramChatId      *string            `json:"telegramChatId,omitempty"`
+	PagerDutyRoutingKey *string            `json:"pagerDutyRoutingKey,omitempty"`
+	NotifyLate          bool               `json:"notifyLate"`
+	ReminderInterval    *int               `json:"reminderInterval,omitempty"`
+}
+
+type WebhookSecretResponse struct {
+	WebhookSecret string `json:"webhookSecret" validate:"required"`
 }
@@ -26,6 +26,7 @@ func RegisterHandler(router *chi.Mux, service *service, validator *validate.Vali
 		router.Post("/", h.CreateChannel)
 		router.Put("/{id}", h.UpdateChannel)
 		router.Delete("/{id}", h.DeleteChannel)
+		router.Put("/{id}/webhook-secret", h.RotateWebhookSecret)
 	})
 }
 
@@ -47,11 +48,23 @@ func (h handler) CreateChannel(w http.ResponseWriter, r *http.Request) {
 
 	user := session.User(r.Context())
 	id, err := h.service.CreateChannel(r.Context(), entity.CreateChannel{
-		Kind:           body.Kind,
-		Email:          body.Email,
-		WebhookURLUp:   body.WebhookURLUp,
-		WebhookURLDown: body.WebhookURLDown,
-		UserId:         user.Id,
+		Kind:                body.Kind,
+		Email:               body.Email,
+		WebhookURLUp:        body.WebhookURLUp,
+		WebhookURLDown:      body.WebhookURLDown,
+		SlackWebhookURL:     body.SlackWebhookURL,
+		TelegramBotToken:    body.TelegramBotToken,
+		TelegramChatId:      body.TelegramChatId,
+		PagerDutyRoutingKey: body.PagerDutyRoutingKey,
+		NotifyLate:          body.NotifyLate,
+		ReminderInterval:    body.ReminderInterval,
+		UserId:              user.Id,
+		WebhookRequest: entity.WebhookRequest{
+			URL:     body.WebhookURL,
+			Method:  webhookMethod(body.WebhookMethod),
+			Headers: body.WebhookHeaders,
+			Body:    body.WebhookBody,
+		},
 	})
 	if err != nil {
 		respond.Error(r.Context(), w, err)
@@ -86,12 +99,24 @@ func (h handler) UpdateChannel(w http.ResponseWriter, r *http.Request) {
 
 	user := session.User(r.Context())
 	err = h.service.UpdateChannel(r.Context(), entity.Channel{
-		Id:             id,
-		Kind:           body.Kind,
-		Email:          body.Email,
-		WebhookURLUp:   body.WebhookURLUp,
-		WebhookURLDown: body.WebhookURLDown,
-		UserId:         user.Id,
+		Id:                  id,
+		Kind:                body.Kind,
+		Email:               body.Email,
+		WebhookURLUp:        body.WebhookURLUp,
+		WebhookURLDown:      body.WebhookURLDown,
+		SlackWebhookURL:     body.SlackWebhookURL,
+		TelegramBotToken:    body.TelegramBotToken,
+		TelegramChatId:      body.TelegramChatId,
+		PagerDutyRoutingKey: body.PagerDutyRoutingKey,
+		NotifyLate:          body.NotifyLate,
+		ReminderInterval:    body.ReminderInterval,
+		UserId:              user.Id,
+		WebhookRequest: entity.WebhookRequest{
+			URL:     body.WebhookURL,
+			Method:  webhookMethod(body.WebhookMethod),
+			Headers: body.WebhookHeaders,
+			Body:    body.WebhookBody,
+		},
 	})
 	if err != nil {
 		respond.Error(r.Context(), w, err)
@@ -120,16 +145,56 @@ func (h handler) GetChannels(w http.ResponseWriter, r *http.Request) {
 	response := make([]GetChannelsResponseItem, len(channels))
 	for i, channel := range channels {
 		response[i] = GetChannelsResponseItem{
-			Id:             channel.Id,
-			Kind:           channel.Kind,
-			Email:          channel.Email,
-			WebhookURLUp:   channel.WebhookURLUp,
-			WebhookURLDown: channel.WebhookURLDown,
+			Id:                  channel.Id,
+			Kind:                channel.Kind,
+			Email:               channel.Email,
+			WebhookURLUp:        channel.WebhookURLUp,
+			WebhookURLDown:      channel.WebhookURLDown,
+			SlackWebhookURL:     channel.SlackWebhookURL,
+			TelegramBotToken:    channel.TelegramBotToken,
+			TelegramChatId:      channel.TelegramChatId,
+			PagerDutyRoutingKey: channel.PagerDutyRoutingKey,
+			NotifyLate:          channel.NotifyLate,
+			ReminderInterval:    channel.ReminderInterval,
+		}
+		if channel.Kind == entity.WebhookChannel {
+			response[i].WebhookURL = channel.WebhookURL
+			response[i].WebhookMethod = channel.WebhookMethod
+			response[i].WebhookHeaders = channel.WebhookHeaders
+			response[i].WebhookBody = channel.WebhookBody
+			response[i].WebhookSecret = channel.WebhookSecret
 		}
 	}
 	respond.JSON(r.Context(), w, http.StatusOK, response)
 }
 
+// RotateWebhookSecret replaces secret of webhook channel with a new one
+// @Tags Channels
+// @Summary Rotate webhook secret
+// @Description Requests of the channel are signed with the new secret, signatures made with the old one stop being valid
+// @Security cookieAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "channel id"
+// @Success 200 {object} WebhookSecretResponse
+// @router /v1/channels/{id}/webhook-secret [put]
+func (h handler) RotateWebhookSecret(w http.ResponseWriter, r *http.Request) {
+	id, err := request.IntParam(r, "id")
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	user := session.User(r.Context())
+	secret, err := h.service.RotateWebhookSecret(r.Context(), entity.RotateWebhookSecret{Id: id, UserId: user.Id})
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	respond.JSON(r.Context(), w, http.StatusOK, WebhookSecretResponse{WebhookSecret: secret})
+}
+
 // DeleteChannel deletes channel by id
 // @Tags Channels
 // @Summary Delete channel
@@ -155,3 +220,11 @@ func (h handler) DeleteChannel(w http.ResponseWriter, r *http.Request) {
 
 	respond.Status(w, http.StatusOK)
 }
+
+// webhookMethod returns method of webhook requests from the request body or the default one, which is GET
+func webhookMethod(method string) string {
+	if method == "" {
+		return http.MethodGet
+	}
+	return method
+}
@@ -3,6 +3,7 @@ package channel_test
 import (
 	"bytes"
 	"encoding/json"
+	"github.com/google/go-cmp/cmp"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"gitlab.com/grygoryz/uptime-checker/config"
 	"gitlab.com/grygoryz/uptime-checker/internal/domain/channel"
@@ -122,6 +123,62 @@ func TestHandler_CreateChannel_InalidInput(t *testing.T) {
 			name: "invalid email field",
 			dto:  channel.CreateChannelBody{Kind: entity.EmailChannel, Email: "invalidemail"},
 		},
+		{
+			name: "webhook kind without WebhookURLDown field",
+			dto:  channel.CreateChannelBody{Kind: entity.WebhookChannel, WebhookURLUp: "https://test.com/up"},
+		},
+		{
+			name: "invalid WebhookMethod field",
+			dto: channel.CreateChannelBody{
+				Kind:          entity.WebhookChannel,
+				WebhookURL:    "https://test.com/hook",
+				WebhookMethod: "CONNECT",
+			},
+		},
+		{
+			name: "invalid WebhookBody template",
+			dto: channel.CreateChannelBody{
+				Kind:        entity.WebhookChannel,
+				WebhookURL:  "https://test.com/hook",
+				WebhookBody: `{"name": {{json .CheckName}`,
+			},
+		},
+		{
+			name: "slack kind without SlackWebhookURL field",
+			dto:  channel.CreateChannelBody{Kind: entity.SlackChannel, WebhookURL: "https://test.com/hook"},
+		},
+		{
+			name: "invalid SlackWebhookURL field",
+			dto:  channel.CreateChannelBody{Kind: entity.SlackChannel, SlackWebhookURL: "invalidurl"},
+		},
+		{
+			name: "telegram kind without TelegramChatId field",
+			dto:  channel.CreateChannelBody{Kind: entity.TelegramChannel, TelegramBotToken: "123456:ABC-DEF1234ghIkl"},
+		},
+		{
+			name: "invalid TelegramBotToken field",
+			dto: channel.CreateChannelBody{
+				Kind:             entity.TelegramChannel,
+				TelegramBotToken: "123456/../ABC",
+				TelegramChatId:   "-1001234567890",
+			},
+		},
+		{
+			name: "invalid TelegramChatId field",
+			dto: channel.CreateChannelBody{
+				Kind:             entity.TelegramChannel,
+				TelegramBotToken: "123456:ABC-DEF1234ghIkl",
+				TelegramChatId:   "chat",
+			},
+		},
+		{
+			name: "pagerduty kind without PagerDutyRoutingKey field",
+			dto:  channel.CreateChannelBody{Kind: entity.PagerDutyChannel, Email: "test@test.com"},
+		},
+		{
+			name: "invalid PagerDutyRoutingKey field",
+			dto:  channel.CreateChannelBody{Kind: entity.PagerDutyChannel, PagerDutyRoutingKey: "shortkey"},
+		},
 	}
 
 	for _, c := range cases {
@@ -209,6 +266,10 @@ func TestHandler_UpdateChannel_InvalidInput(t *testing.T) {
 			name: "invalid email field",
 			dto:  channel.UpdateChannelBody{Kind: entity.EmailChannel, Email: "invalidemail"},
 		},
+		{
+			name: "slack kind without SlackWebhookURL field",
+			dto:  channel.UpdateChannelBody{Kind: entity.SlackChannel, Email: "test@test.com"},
+		},
 	}
 
 	for _, c := range cases {
@@ -280,3 +341,233 @@ func TestHandler_DeleteChannel_WithDependentCheck(t *testing.T) {
 	response = test.ExecuteRequest(s, req)
 	test.CheckCode(t, http.StatusBadRequest, response.Code)
 }
+
+func TestHandler_CreateChannel_NotifyLate(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+
+	dto := channel.CreateChannelBody{Kind: entity.EmailChannel, Email: "late@test.com", NotifyLate: true}
+	ch := createChannel(t, cookie, dto)
+
+	req, _ := http.NewRequest("GET", "/v1/channels", nil)
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var channels []channel.GetChannelsResponseItem
+	err := json.Unmarshal(response.Body.Bytes(), &channels)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range channels {
+		if c.Id == ch.Id && !c.NotifyLate {
+			t.Error("want channel NotifyLate to be true")
+		}
+		if c.Id != ch.Id && c.NotifyLate {
+			t.Error("want default channel NotifyLate to be false")
+		}
+	}
+}
+
+func TestHandler_CreateChannel_WebhookRequest(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+
+	dto := channel.CreateChannelBody{
+		Kind:           entity.WebhookChannel,
+		WebhookURL:     "https://test.com/hook",
+		WebhookMethod:  http.MethodPost,
+		WebhookHeaders: map[string]string{"Content-Type": "application/json"},
+		WebhookBody:    `{"check": {{json .CheckName}}, "status": "{{.Status}}"}`,
+	}
+	ch := createChannel(t, cookie, dto)
+
+	req, _ := http.NewRequest("GET", "/v1/channels", nil)
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var channels []channel.GetChannelsResponseItem
+	err := json.Unmarshal(response.Body.Bytes(), &channels)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range channels {
+		if c.Id != ch.Id {
+			continue
+		}
+		got := channel.CreateChannelBody{Kind: c.Kind, WebhookMethod: c.WebhookMethod, WebhookHeaders: c.WebhookHeaders}
+		if c.WebhookURL != nil {
+			got.WebhookURL = *c.WebhookURL
+		}
+		if c.WebhookBody != nil {
+			got.WebhookBody = *c.WebhookBody
+		}
+		if diff := cmp.Diff(dto, got); diff != "" {
+			t.Errorf("channel mismatch (-want +got):\n%s", diff)
+		}
+		return
+	}
+	t.Errorf("want channel %v to exist", ch.Id)
+}
+
+func TestHandler_SlackChannel(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+
+	ch := createChannel(t, cookie, channel.CreateChannelBody{Kind: entity.EmailChannel, Email: "test1@test.com"})
+	dto := channel.UpdateChannelBody{Kind: entity.SlackChannel, SlackWebhookURL: "https://hooks.slack.com/services/T0/B0/X"}
+	body, err := json.Marshal(dto)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest("PUT", "/v1/channels/"+strconv.Itoa(ch.Id), bytes.NewReader(body))
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	req, _ = http.NewRequest("GET", "/v1/channels", nil)
+	req.Header.Set("Cookie", cookie)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var channels []channel.GetChannelsResponseItem
+	err = json.Unmarshal(response.Body.Bytes(), &channels)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range channels {
+		if c.Id != ch.Id {
+			continue
+		}
+		if c.Kind != entity.SlackChannel || c.SlackWebhookURL == nil || *c.SlackWebhookURL != dto.SlackWebhookURL {
+			t.Errorf("want slack channel with url %v, got %+v", dto.SlackWebhookURL, c)
+		}
+		if c.Email != nil {
+			t.Errorf("want email of slack channel to be reset, got %v", *c.Email)
+		}
+		return
+	}
+	t.Errorf("want channel %v to exist", ch.Id)
+}
+
+func TestHandler_TelegramChannel(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+
+	dto := channel.CreateChannelBody{
+		Kind:             entity.TelegramChannel,
+		TelegramBotToken: "123456:ABC-DEF1234ghIkl",
+		TelegramChatId:   "@uptime_alerts",
+	}
+	ch := createChannel(t, cookie, dto)
+
+	req, _ := http.NewRequest("GET", "/v1/channels", nil)
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var channels []channel.GetChannelsResponseItem
+	err := json.Unmarshal(response.Body.Bytes(), &channels)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range channels {
+		if c.Id != ch.Id {
+			continue
+		}
+		got := channel.CreateChannelBody{Kind: c.Kind}
+		if c.TelegramBotToken != nil {
+			got.TelegramBotToken = *c.TelegramBotToken
+		}
+		if c.TelegramChatId != nil {
+			got.TelegramChatId = *c.TelegramChatId
+		}
+		if diff := cmp.Diff(dto, got); diff != "" {
+			t.Errorf("channel mismatch (-want +got):\n%s", diff)
+		}
+		return
+	}
+	t.Errorf("want channel %v to exist", ch.Id)
+}
+
+func TestHandler_PagerDutyChannel(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+
+	dto := channel.CreateChannelBody{Kind: entity.PagerDutyChannel, PagerDutyRoutingKey: "R0123456789abcdef0123456789abcde"}
+	ch := createChannel(t, cookie, dto)
+
+	req, _ := http.NewRequest("GET", "/v1/channels", nil)
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var channels []channel.GetChannelsResponseItem
+	err := json.Unmarshal(response.Body.Bytes(), &channels)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range channels {
+		if c.Id != ch.Id {
+			continue
+		}
+		if c.Kind != entity.PagerDutyChannel || c.PagerDutyRoutingKey == nil || *c.PagerDutyRoutingKey != dto.PagerDutyRoutingKey {
+			t.Errorf("want pagerduty channel with routing key %v, got %+v", dto.PagerDutyRoutingKey, c)
+		}
+		return
+	}
+	t.Errorf("want channel %v to exist", ch.Id)
+}
+
+func TestHandler_RotateWebhookSecret(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+
+	ch := createChannel(t, cookie, channel.CreateChannelBody{Kind: entity.WebhookChannel, WebhookURL: "https://test.com/hook"})
+	chEmail := createChannel(t, cookie, channel.CreateChannelBody{Kind: entity.EmailChannel, Email: "test1@test.com"})
+
+	getSecret := func() string {
+		req, _ := http.NewRequest("GET", "/v1/channels", nil)
+		req.Header.Set("Cookie", cookie)
+		response := test.ExecuteRequest(s, req)
+		test.CheckCode(t, http.StatusOK, response.Code)
+
+		var channels []channel.GetChannelsResponseItem
+		err := json.Unmarshal(response.Body.Bytes(), &channels)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range channels {
+			if c.Id == ch.Id {
+				return c.WebhookSecret
+			}
+		}
+		t.Fatalf("want channel %v to exist", ch.Id)
+		return ""
+	}
+
+	old := getSecret()
+	if len(old) != 64 {
+		t.Errorf("want webhook secret of 64 characters, got %q", old)
+	}
+
+	req, _ := http.NewRequest("PUT", "/v1/channels/"+strconv.Itoa(ch.Id)+"/webhook-secret", nil)
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var rotated channel.WebhookSecretResponse
+	err := json.Unmarshal(response.Body.Bytes(), &rotated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotated.WebhookSecret == old || rotated.WebhookSecret != getSecret() {
+		t.Errorf("want webhook secret to be replaced with %q, old %q", rotated.WebhookSecret, old)
+	}
+
+	req, _ = http.NewRequest("PUT", "/v1/channels/"+strconv.Itoa(chEmail.Id)+"/webhook-secret", nil)
+	req.Header.Set("Cookie", cookie)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusNotFound, response.Code)
+}
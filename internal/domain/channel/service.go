@@ -6,6 +6,7 @@ import (
 	"gitlab.com/grygoryz/uptime-checker/internal/entity"
 	"gitlab.com/grygoryz/uptime-checker/internal/repository"
 	"gitlab.com/grygoryz/uptime-checker/internal/utility/errors"
+	"gitlab.com/grygoryz/uptime-checker/internal/webhook"
 )
 
 type service struct {
@@ -17,10 +18,24 @@ func NewService(repositoryRegistry *repository.Registry) *service {
 }
 
 func (s *service) CreateChannel(ctx context.Context, channel entity.CreateChannel) (int, error) {
+	if channel.Kind == entity.WebhookChannel {
+		err := checkWebhook(channel.WebhookURLUp, channel.WebhookURLDown, channel.WebhookRequest)
+		if err != nil {
+			return 0, err
+		}
+	}
+
 	return s.r.Channel.Create(ctx, channel)
 }
 
 func (s *service) UpdateChannel(ctx context.Context, channel entity.Channel) error {
+	if channel.Kind == entity.WebhookChannel {
+		err := checkWebhook(channel.WebhookURLUp, channel.WebhookURLDown, channel.WebhookRequest)
+		if err != nil {
+			return err
+		}
+	}
+
 	return s.r.Channel.Update(ctx, channel)
 }
 
@@ -28,6 +43,10 @@ func (s *service) GetChannels(ctx context.Context, userId int) ([]entity.Channel
 	return s.r.Channel.GetMany(ctx, userId)
 }
 
+func (s *service) RotateWebhookSecret(ctx context.Context, params entity.RotateWebhookSecret) (string, error) {
+	return s.r.Channel.RotateWebhookSecret(ctx, params)
+}
+
 func (s *service) DeleteChannel(ctx context.Context, channel entity.DeleteChannel) error {
 	return s.r.WithTx(ctx, func(ctx context.Context) error {
 		ids, err := s.r.Channel.GetChecksDependentOnChannel(ctx, channel.Id)
@@ -47,3 +66,18 @@ func (s *service) DeleteChannel(ctx context.Context, channel entity.DeleteChanne
 		return nil
 	})
 }
+
+// checkWebhook returns error if webhook channel has neither single url nor both up and down ones, or if its body
+// template is not valid
+func checkWebhook(urlUp string, urlDown string, request entity.WebhookRequest) error {
+	if request.URL == "" && (urlUp == "" || urlDown == "") {
+		return errors.E(errors.Validation, "webhookURL or both webhookURLUp and webhookURLDown are required")
+	}
+
+	_, err := webhook.Parse(request.Body)
+	if err != nil {
+		return errors.E(errors.Validation, fmt.Sprintf("invalid webhook body template: %v", err))
+	}
+
+	return nil
+}
@@ -6,16 +6,55 @@ import (
 )
 
 type Check struct {
-	Id          string             `json:"id" validate:"required"`
-	Name        string             `json:"name" validate:"required"`
-	Description string             `json:"description" validate:"required"`
-	Interval    int                `json:"interval" validate:"required"`
-	Grace       int                `json:"grace" validate:"required"`
-	LastPing    *time.Time         `json:"lastPing,omitempty"`
-	NextPing    *time.Time         `json:"nextPing,omitempty"`
-	LastStarted *time.Time         `json:"lastStarted,omitempty"`
-	Status      entity.CheckStatus `json:"status" validate:"required"`
-	Channels    []Channel          `json:"channels" validate:"required"`
+	Id                 string                   `json:"id" validate:"required"`
+	Name               string                   `json:"name" validate:"required"`
+	Slug               *string                  `json:"slug,omitempty"`
+	Description        string                   `json:"description" validate:"required"`
+	Tags               []string                 `json:"tags" validate:"required"`
+	Schedule           entity.ScheduleKind      `json:"schedule" validate:"required"`
+	Interval           int                      `json:"interval,omitempty"`
+	Cron               string                   `json:"cron,omitempty"`
+	Timezone           string                   `json:"timezone" validate:"required"`
+	Grace              int                      `json:"grace" validate:"required"`
+	MaxDuration        *int                     `json:"maxDuration,omitempty"`
+	FirstPingDeadline  *int                     `json:"firstPingDeadline,omitempty"`
+	FailureThreshold   int                      `json:"failureThreshold" validate:"required"`
+	Failures           int                      `json:"failures"` // consecutive failures
+	FlapThreshold      *int                     `json:"flapThreshold,omitempty"`
+	FlapWindow         int                      `json:"flapWindow" validate:"required"`
+	FlapStablePeriod   int                      `json:"flapStablePeriod" validate:"required"`
+	FlappingSince      *time.Time               `json:"flappingSince,omitempty"`
+	EscalationPolicyId *int                     `json:"escalationPolicyId,omitempty"`
+	ReminderInterval   *int                     `json:"reminderInterval,omitempty"`
+	ReminderLimit      int                      `json:"reminderLimit" validate:"required"`
+	RemindersStoppedAt *time.Time               `json:"remindersStoppedAt,omitempty"`
+	SlowDuration       *int                     `json:"slowDuration,omitempty"`
+	SlowFactor         *float64                 `json:"slowFactor,omitempty"`
+	MinDuration        *int                     `json:"minDuration,omitempty"`
+	MinDurationAction  entity.MinDurationAction `json:"minDurationAction" validate:"required"`
+	Fleet              bool                     `json:"fleet"`
+	FleetTolerance     int                      `json:"fleetTolerance"`
+	InstanceTTL        int                      `json:"instanceTTL" validate:"required"`
+	Severity           entity.CheckSeverity     `json:"severity" validate:"required"`
+	CreatedAt          time.Time                `json:"createdAt" validate:"required"`
+	PausePolicy        entity.PausePolicy       `json:"pausePolicy" validate:"required"`
+	ResumeAt           *time.Time               `json:"resumeAt,omitempty"`
+	LastPing           *time.Time               `json:"lastPing,omitempty"`
+	NextPing           *time.Time               `json:"nextPing,omitempty"`
+	LastStarted        *time.Time               `json:"lastStarted,omitempty"`
+	Status             entity.CheckStatus       `json:"status" validate:"required"`
+	Channels           []Channel                `json:"channels" validate:"required"`
+	BodyRules          []BodyRule               `json:"bodyRules" validate:"required"`
+	Metrics            []Metric                 `json:"metrics" validate:"required"`
+	MetricRules        []MetricRule             `json:"metricRules" validate:"required"`
+	Instances          []Instance               `json:"instances" validate:"required"` // instances of fleet check
+}
+
+type Instance struct {
+	Name     string                `json:"name" validate:"required"`
+	Status   entity.InstanceStatus `json:"status" validate:"required"`
+	LastPing time.Time             `json:"lastPing" validate:"required"`
+	NextPing *time.Time            `json:"nextPing,omitempty"`
 }
 
 type Channel struct {
@@ -31,17 +70,65 @@ type CheckIdParam struct {
 }
 
 type CreateCheckBody struct {
-	Name        string `json:"name" validate:"required,max=128"`
-	Description string `json:"description" validate:"required,max=528"`
-	Interval    int    `json:"interval" validate:"required,min=60,max=31536000"` // min 1 minute, max 1 year
-	Grace       int    `json:"grace" validate:"required,min=60,max=31536000"`    // min 1 minute, max 1 year
-	Channels    []int  `json:"channels" validate:"required,min=1"`
+	Name               string                   `json:"name" validate:"required,max=128"`
+	Slug               *string                  `json:"slug" validate:"omitempty,max=100,slug"` // unique among user's checks
+	Description        string                   `json:"description" validate:"required,max=528"`
+	Tags               []string                 `json:"tags" validate:"omitempty,max=10,dive,max=50,slug"`
+	Schedule           entity.ScheduleKind      `json:"schedule" validate:"omitempty,oneof=simple cron"`                                 // simple by default
+	Interval           int                      `json:"interval" validate:"required_unless=Schedule cron,omitempty,min=60,max=31536000"` // min 1 minute, max 1 year
+	Cron               string                   `json:"cron" validate:"required_if=Schedule cron,omitempty,max=100,cron"`
+	Timezone           string                   `json:"timezone" validate:"omitempty,timezone"`                                        // UTC by default
+	Grace              int                      `json:"grace" validate:"required,min=60,max=31536000"`                                 // min 1 minute, max 1 year
+	MaxDuration        *int                     `json:"maxDuration" validate:"omitempty,min=60,max=31536000"`                          // max duration of a run, no limit by default
+	FirstPingDeadline  *int                     `json:"firstPingDeadline" validate:"omitempty,min=60,max=31536000"`                    // seconds after check creation, no deadline by default
+	FailureThreshold   int                      `json:"failureThreshold" validate:"omitempty,min=1,max=100"`                           // consecutive failures to go down, 1 by default
+	FlapThreshold      *int                     `json:"flapThreshold" validate:"omitempty,min=1,max=100"`                              // check is flapping with more flips within window, no detection by default
+	FlapWindow         int                      `json:"flapWindow" validate:"omitempty,min=60,max=604800"`                             // 1 hour by default
+	FlapStablePeriod   int                      `json:"flapStablePeriod" validate:"omitempty,min=60,max=604800"`                       // period without flips to stop flapping, 1 hour by default
+	EscalationPolicyId *int                     `json:"escalationPolicyId"`                                                            // channels of the policy's steps are notified about down and up flips instead of check's channels
+	ReminderInterval   *int                     `json:"reminderInterval" validate:"omitempty,min=300,max=604800"`                      // seconds between "still down" reminders, channel's interval is used by default
+	ReminderLimit      int                      `json:"reminderLimit" validate:"omitempty,min=1,max=100"`                              // max reminders per outage, 10 by default
+	SlowDuration       *int                     `json:"slowDuration" validate:"omitempty,min=1,max=31536000,excluded_with=SlowFactor"` // run lasting longer is slow, user is warned about it
+	SlowFactor         *float64                 `json:"slowFactor" validate:"omitempty,min=1,max=100"`                                 // run lasting longer than this times p95 duration of the last 30 days runs is slow
+	MinDuration        *int                     `json:"minDuration" validate:"omitempty,min=1,max=31536000"`                           // successful run lasting less has likely done nothing, no min by default
+	MinDurationAction  entity.MinDurationAction `json:"minDurationAction" validate:"omitempty,oneof=fail warn"`                        // too fast run fails the check or warns about it, fail by default
+	Fleet              bool                     `json:"fleet"`                                                                         // pings come from multiple instances tracked separately
+	FleetTolerance     int                      `json:"fleetTolerance" validate:"omitempty,min=0,max=1000"`                            // down instances which fleet check tolerates, 0 by default
+	InstanceTTL        int                      `json:"instanceTTL" validate:"omitempty,min=60,max=31536000"`                          // seconds after which instance which has stopped pinging is forgotten, 1 day by default
+	Severity           entity.CheckSeverity     `json:"severity" validate:"omitempty,oneof=critical error warning info"`               // severity of incidents reported to PagerDuty, critical by default
+	PausePolicy        entity.PausePolicy       `json:"pausePolicy" validate:"omitempty,oneof=resume ignore"`                          // what a ping does to a paused check, resume by default
+	Channels           []int                    `json:"channels" validate:"required,min=1"`
+	BodyRules          []BodyRule               `json:"bodyRules" validate:"omitempty,max=20,dive"`           // the first rule matching body of success or fail ping decides its outcome
+	Metrics            []Metric                 `json:"metrics" validate:"omitempty,max=20,unique=Name,dive"` // numbers extracted from JSON bodies of pings
+	MetricRules        []MetricRule             `json:"metricRules" validate:"omitempty,max=20,dive"`         // the first rule matching metrics decides outcome of ping if no body rule matches
+}
+
+type Metric struct {
+	Name string `json:"name" validate:"required,max=64"`
+	Path string `json:"path" validate:"required,max=255"` // gjson syntax, e.g. stats.rows
+}
+
+type MetricRule struct {
+	Metric    string          `json:"metric" validate:"required,max=64"`
+	Op        entity.MetricOp `json:"op" validate:"required,oneof=lt lte gt gte eq ne"`
+	Threshold float64         `json:"threshold"`
+	Outcome   entity.PingKind `json:"outcome" validate:"required,oneof=success fail"`
+}
+
+type BodyRule struct {
+	Match   entity.BodyRuleMatch `json:"match" validate:"required,oneof=substring regex"`
+	Pattern string               `json:"pattern" validate:"required,max=500"` // RE2 syntax for regex rules
+	Outcome entity.PingKind      `json:"outcome" validate:"required,oneof=success fail"`
 }
 
 type UpdateCheckBody struct {
 	CreateCheckBody
 }
 
+type PauseCheckQuery struct {
+	ResumeAt int `json:"resumeAt"` // unix time in milliseconds when check resumes automatically
+}
+
 type CreateCheckResponse struct {
 	Id string `json:"id" validate:"required"`
 }
@@ -61,6 +148,14 @@ type Ping struct {
 	Body      string          `json:"body,omitempty"`
 	Date      time.Time       `json:"date" validate:"required"`
 	Duration  *int            `json:"duration,omitempty"`
+	ExitCode  *int            `json:"exitCode,omitempty"`
+	RunId     *string         `json:"runId,omitempty"`
+	// SlowThreshold is set if the run finished by the ping was slow, FastThreshold if it was too fast
+	SlowThreshold *int        `json:"slowThreshold,omitempty"`
+	FastThreshold *int        `json:"fastThreshold,omitempty"`
+	BodyRule      *BodyRule   `json:"bodyRule,omitempty"`   // rule which has decided outcome of the ping
+	MetricRule    *MetricRule `json:"metricRule,omitempty"` // rule which has decided outcome of the ping
+	Instance      *string     `json:"instance,omitempty"`   // instance of fleet check which has sent the ping
 }
 
 type GetPingsResponse struct {
@@ -68,6 +163,34 @@ type GetPingsResponse struct {
 	Items []Ping `json:"items" validate:"required"`
 }
 
+type GetRunsQuery struct {
+	From   int  `json:"from" validate:"required"`
+	To     int  `json:"to" validate:"required"`
+	Limit  int  `json:"limit" validate:"required,min=1,max=50"`
+	Offset *int `json:"offset" validate:"required"`
+}
+
+type Run struct {
+	RunId    *string    `json:"runId,omitempty"`
+	Start    time.Time  `json:"start" validate:"required"`
+	End      *time.Time `json:"end,omitempty"`
+	Outcome  RunOutcome `json:"outcome" validate:"required"`
+	Duration *int       `json:"duration,omitempty"`
+}
+
+type RunOutcome string
+
+const (
+	RunSuccess    RunOutcome = "success"
+	RunFail       RunOutcome = "fail"
+	RunUnfinished RunOutcome = "unfinished"
+)
+
+type GetRunsResponse struct {
+	Total int   `json:"total" validate:"required"`
+	Items []Run `json:"items" validate:"required"`
+}
+
 type GetFlipsQuery struct {
 	From   int  `json:"from" validate:"required"`
 	To     int  `json:"to" validate:"required"`
@@ -76,11 +199,98 @@ type GetFlipsQuery struct {
 }
 
 type Flip struct {
-	To   entity.FlipState `json:"to" validate:"required"`
-	Date time.Time        `json:"date" validate:"required"`
+	To         entity.FlipState  `json:"to" validate:"required"`
+	Reason     entity.FlipReason `json:"reason,omitempty"`
+	ExitCode   *int              `json:"exitCode,omitempty"`
+	Suppressed bool              `json:"suppressed,omitempty"` // users are not notified about suppressed flips
+	Date       time.Time         `json:"date" validate:"required"`
 }
 
 type GetFlipsResponse struct {
 	Total int    `json:"total" validate:"required"`
 	Items []Flip `json:"items" validate:"required"`
 }
+
+type GetIncidentsQuery struct {
+	From   int  `json:"from" validate:"required"`
+	To     int  `json:"to" validate:"required"`
+	Limit  int  `json:"limit" validate:"required,min=1,max=50"`
+	Offset *int `json:"offset" validate:"required"`
+}
+
+type Incident struct {
+	Id             int               `json:"id" validate:"required"`
+	Cause          entity.FlipReason `json:"cause,omitempty"`
+	StartedAt      time.Time         `json:"startedAt" validate:"required"`
+	EndedAt        *time.Time        `json:"endedAt,omitempty"`
+	Duration       *int              `json:"duration,omitempty"` // seconds, set when incident has ended
+	AcknowledgedAt *time.Time        `json:"acknowledgedAt,omitempty"`
+	AcknowledgedBy *string           `json:"acknowledgedBy,omitempty"`
+	Notes          []IncidentNote    `json:"notes" validate:"required"`
+}
+
+type IncidentNote struct {
+	Text      string    `json:"text" validate:"required"`
+	Author    string    `json:"author" validate:"required"`
+	CreatedAt time.Time `json:"createdAt" validate:"required"`
+}
+
+type GetIncidentsResponse struct {
+	Total int        `json:"total" validate:"required"`
+	Items []Incident `json:"items" validate:"required"`
+}
+
+type GetUptimeQuery struct {
+	From   int                 `json:"from" validate:"required"`
+	To     int                 `json:"to" validate:"required"`
+	Bucket entity.UptimeBucket `json:"bucket" validate:"required,oneof=day week month"`
+}
+
+type Uptime struct {
+	Start        time.Time `json:"start" validate:"required"`
+	End          time.Time `json:"end" validate:"required"`
+	Up           int       `json:"up" validate:"required"`     // seconds
+	Down         int       `json:"down" validate:"required"`   // seconds
+	Paused       int       `json:"paused" validate:"required"` // seconds
+	Availability *float64  `json:"availability,omitempty"`     // percentage of up time in up and down time, absent if check was neither up nor down
+}
+
+type TagUptime struct {
+	Tag     string   `json:"tag" validate:"required"`
+	Buckets []Uptime `json:"buckets" validate:"required"`
+}
+
+type GetDurationsQuery struct {
+	From   int                   `json:"from" validate:"required"`
+	To     int                   `json:"to" validate:"required"`
+	Bucket entity.DurationBucket `json:"bucket" validate:"required,oneof=hour day week month"`
+}
+
+type DurationStats struct {
+	Runs int     `json:"runs"` // number of finished runs
+	Min  int     `json:"min"`  // seconds
+	Avg  float64 `json:"avg"`  // seconds
+	P50  float64 `json:"p50"`  // seconds
+	P95  float64 `json:"p95"`  // seconds
+	Max  int     `json:"max"`  // seconds
+}
+
+type DurationPoint struct {
+	Start time.Time `json:"start" validate:"required"`
+	DurationStats
+}
+
+type GetDurationsResponse struct {
+	DurationStats
+	Series []DurationPoint `json:"series" validate:"required"` // statistics of buckets which have runs
+}
+
+type GetMetricHistoryQuery struct {
+	From int `json:"from" validate:"required"`
+	To   int `json:"to" validate:"required"`
+}
+
+type MetricPoint struct {
+	Date  time.Time `json:"date" validate:"required"`
+	Value float64   `json:"value"`
+}
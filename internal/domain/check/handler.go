@@ -4,9 +4,11 @@ import (
 	"github.com/go-chi/chi/v5"
 	"gitlab.com/grygoryz/uptime-checker/internal/entity"
 	"gitlab.com/grygoryz/uptime-checker/internal/session"
+	"gitlab.com/grygoryz/uptime-checker/internal/utility/errors"
 	"gitlab.com/grygoryz/uptime-checker/internal/utility/request"
 	"gitlab.com/grygoryz/uptime-checker/internal/utility/respond"
 	"gitlab.com/grygoryz/uptime-checker/internal/validate"
+	"math"
 	"net/http"
 	"time"
 )
@@ -25,12 +27,20 @@ func RegisterHandler(router *chi.Mux, service *service, validator *validate.Vali
 		router.Use(authMiddleware)
 		router.Get("/", h.GetChecks)
 		router.Post("/", h.CreateCheck)
+		router.Get("/uptime", h.GetTagsUptime)
 		router.Get("/{id}", h.GetCheck)
 		router.Put("/{id}", h.UpdateCheck)
 		router.Delete("/{id}", h.DeleteCheck)
 		router.Put("/{id}/pause", h.PauseCheck)
+		router.Put("/{id}/resume", h.ResumeCheck)
+		router.Put("/{id}/stop-reminders", h.StopReminders)
 		router.Get("/{id}/pings", h.GetPings)
 		router.Get("/{id}/flips", h.GetFlips)
+		router.Get("/{id}/runs", h.GetRuns)
+		router.Get("/{id}/incidents", h.GetIncidents)
+		router.Get("/{id}/uptime", h.GetUptime)
+		router.Get("/{id}/durations", h.GetDurations)
+		router.Get("/{id}/metrics/{name}", h.GetMetricHistory)
 	})
 }
 
@@ -101,13 +111,37 @@ func (h handler) CreateCheck(w http.ResponseWriter, r *http.Request) {
 	}
 
 	user := session.User(r.Context())
+	sched := scheduleFromBody(body)
 	id, err := h.service.CreateCheck(r.Context(), entity.CreateCheck{
-		UserId:      user.Id,
-		Name:        body.Name,
-		Description: body.Description,
-		Interval:    body.Interval,
-		Grace:       body.Grace,
-	}, body.Channels)
+		UserId:             user.Id,
+		Name:               body.Name,
+		Slug:               body.Slug,
+		Description:        body.Description,
+		Tags:               body.Tags,
+		Schedule:           sched.Kind,
+		Interval:           sched.Interval,
+		Cron:               sched.Cron,
+		Timezone:           sched.Timezone,
+		Grace:              body.Grace,
+		MaxDuration:        body.MaxDuration,
+		FirstPingDeadline:  body.FirstPingDeadline,
+		FailureThreshold:   failureThreshold(body.FailureThreshold),
+		FlapThreshold:      body.FlapThreshold,
+		FlapWindow:         defaultPeriod(body.FlapWindow),
+		FlapStable:         defaultPeriod(body.FlapStablePeriod),
+		EscalationPolicyId: body.EscalationPolicyId,
+		ReminderInterval:   body.ReminderInterval,
+		ReminderLimit:      reminderLimit(body.ReminderLimit),
+		SlowDuration:       body.SlowDuration,
+		SlowFactor:         body.SlowFactor,
+		MinDuration:        body.MinDuration,
+		MinDurationAction:  minDurationAction(body.MinDurationAction),
+		Fleet:              body.Fleet,
+		FleetTolerance:     body.FleetTolerance,
+		InstanceTTL:        instanceTTL(body.InstanceTTL),
+		Severity:           severity(body.Severity),
+		PausePolicy:        pausePolicy(body.PausePolicy),
+	}, body.Channels, pingRules(body))
 	if err != nil {
 		respond.Error(r.Context(), w, err)
 		return
@@ -141,14 +175,38 @@ func (h handler) UpdateCheck(w http.ResponseWriter, r *http.Request) {
 	}
 
 	user := session.User(r.Context())
+	sched := scheduleFromBody(body.CreateCheckBody)
 	err = h.service.UpdateCheck(r.Context(), entity.UpdateCheck{
-		Id:          checkId,
-		UserId:      user.Id,
-		Name:        body.Name,
-		Description: body.Description,
-		Interval:    body.Interval,
-		Grace:       body.Grace,
-	}, body.Channels)
+		Id:                 checkId,
+		UserId:             user.Id,
+		Name:               body.Name,
+		Slug:               body.Slug,
+		Description:        body.Description,
+		Tags:               body.Tags,
+		Schedule:           sched.Kind,
+		Interval:           sched.Interval,
+		Cron:               sched.Cron,
+		Timezone:           sched.Timezone,
+		Grace:              body.Grace,
+		MaxDuration:        body.MaxDuration,
+		FirstPingDeadline:  body.FirstPingDeadline,
+		FailureThreshold:   failureThreshold(body.FailureThreshold),
+		FlapThreshold:      body.FlapThreshold,
+		FlapWindow:         defaultPeriod(body.FlapWindow),
+		FlapStable:         defaultPeriod(body.FlapStablePeriod),
+		EscalationPolicyId: body.EscalationPolicyId,
+		ReminderInterval:   body.ReminderInterval,
+		ReminderLimit:      reminderLimit(body.ReminderLimit),
+		SlowDuration:       body.SlowDuration,
+		SlowFactor:         body.SlowFactor,
+		MinDuration:        body.MinDuration,
+		MinDurationAction:  minDurationAction(body.MinDurationAction),
+		Fleet:              body.Fleet,
+		FleetTolerance:     body.FleetTolerance,
+		InstanceTTL:        instanceTTL(body.InstanceTTL),
+		Severity:           severity(body.Severity),
+		PausePolicy:        pausePolicy(body.PausePolicy),
+	}, body.Channels, pingRules(body.CreateCheckBody))
 	if err != nil {
 		respond.Error(r.Context(), w, err)
 		return
@@ -194,6 +252,7 @@ func (h handler) DeleteCheck(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param id path string true "check id"
+// @Param params query PauseCheckQuery false "params"
 // @Success 200
 // @router /v1/checks/{id}/pause [put]
 func (h handler) PauseCheck(w http.ResponseWriter, r *http.Request) {
@@ -204,8 +263,83 @@ func (h handler) PauseCheck(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var resumeAt *time.Time
+	if r.URL.Query().Has("resumeAt") {
+		ms, err := request.IntQueryParam(r, "resumeAt")
+		if err != nil {
+			respond.Error(r.Context(), w, err)
+			return
+		}
+
+		t := time.UnixMilli(int64(ms))
+		if !t.After(time.Now()) {
+			respond.Error(r.Context(), w, errors.E(errors.Validation, "resumeAt must be in the future"))
+			return
+		}
+		resumeAt = &t
+	}
+
+	user := session.User(r.Context())
+	err = h.service.PauseCheck(r.Context(), entity.PauseCheck{
+		Id:       checkId,
+		UserId:   user.Id,
+		ResumeAt: resumeAt,
+	})
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	respond.Status(w, http.StatusOK)
+}
+
+// ResumeCheck resumes paused check
+// @Tags Checks
+// @Summary Resume check
+// @Security cookieAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "check id"
+// @Success 200
+// @router /v1/checks/{id}/resume [put]
+func (h handler) ResumeCheck(w http.ResponseWriter, r *http.Request) {
+	checkId := chi.URLParam(r, "id")
+	err := h.validator.Struct(CheckIdParam{Id: checkId})
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	user := session.User(r.Context())
+	err = h.service.ResumeCheck(r.Context(), checkId, user.Id)
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	respond.Status(w, http.StatusOK)
+}
+
+// StopReminders stops "still down" reminders of down check
+// @Tags Checks
+// @Summary Stop reminders
+// @Description Reminders are sent again when the check goes down next time
+// @Security cookieAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "check id"
+// @Success 200
+// @router /v1/checks/{id}/stop-reminders [put]
+func (h handler) StopReminders(w http.ResponseWriter, r *http.Request) {
+	checkId := chi.URLParam(r, "id")
+	err := h.validator.Struct(CheckIdParam{Id: checkId})
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
 	user := session.User(r.Context())
-	err = h.service.PauseCheck(r.Context(), checkId, user.Id)
+	err = h.service.StopReminders(r.Context(), entity.StopReminders{Id: checkId, UserId: user.Id})
 	if err != nil {
 		respond.Error(r.Context(), w, err)
 		return
@@ -279,13 +413,26 @@ func (h handler) GetPings(w http.ResponseWriter, r *http.Request) {
 	items := make([]Ping, len(pings))
 	for i, ping := range pings {
 		items[i] = Ping{
-			Id:        ping.Id,
-			Type:      ping.Type,
-			Source:    ping.Source,
-			UserAgent: ping.UserAgent,
-			Body:      ping.Body,
-			Date:      ping.Date.UTC(),
-			Duration:  ping.Duration,
+			Id:            ping.Id,
+			Type:          ping.Type,
+			Source:        ping.Source,
+			UserAgent:     ping.UserAgent,
+			Body:          ping.Body,
+			Date:          ping.Date.UTC(),
+			Duration:      ping.Duration,
+			ExitCode:      ping.ExitCode,
+			RunId:         ping.RunId,
+			SlowThreshold: ping.SlowThreshold,
+			FastThreshold: ping.FastThreshold,
+			Instance:      ping.Instance,
+		}
+		if ping.BodyRule != nil {
+			rule := bodyRuleDTO(*ping.BodyRule)
+			items[i].BodyRule = &rule
+		}
+		if ping.MetricRule != nil {
+			rule := metricRuleDTO(*ping.MetricRule)
+			items[i].MetricRule = &rule
 		}
 	}
 
@@ -360,8 +507,11 @@ func (h handler) GetFlips(w http.ResponseWriter, r *http.Request) {
 	items := make([]Flip, len(flips))
 	for i, flip := range flips {
 		items[i] = Flip{
-			To:   flip.To,
-			Date: flip.Date.UTC(),
+			To:         flip.To,
+			Reason:     flip.Reason,
+			ExitCode:   flip.ExitCode,
+			Suppressed: flip.Suppressed,
+			Date:       flip.Date.UTC(),
 		}
 	}
 
@@ -371,19 +521,454 @@ func (h handler) GetFlips(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// GetRuns returns check's runs
+// @Tags Checks
+// @Summary Get runs
+// @Description Runs are paired start and finish pings. Pings with run id are paired by it
+// @Security cookieAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "check id"
+// @Param params query GetRunsQuery true "params"
+// @Success 200 {object} GetRunsResponse
+// @router /v1/checks/{id}/runs [get]
+func (h handler) GetRuns(w http.ResponseWriter, r *http.Request) {
+	checkId := chi.URLParam(r, "id")
+	err := h.validator.Struct(CheckIdParam{Id: checkId})
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	limit, err := request.IntQueryParam(r, "limit")
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+	offset, err := request.IntQueryParam(r, "offset")
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+	from, err := request.IntQueryParam(r, "from")
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+	to, err := request.IntQueryParam(r, "to")
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	err = h.validator.Struct(GetRunsQuery{
+		From:   from,
+		To:     to,
+		Limit:  limit,
+		Offset: &offset,
+	})
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	runs, total, err := h.service.GetRuns(r.Context(), entity.GetRuns{
+		CheckId: checkId,
+		From:    time.UnixMilli(int64(from)),
+		To:      time.UnixMilli(int64(to)),
+		Limit:   limit,
+		Offset:  offset,
+	})
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	items := make([]Run, len(runs))
+	for i, run := range runs {
+		outcome := RunUnfinished
+		if run.Outcome != nil {
+			outcome = RunOutcome(*run.Outcome)
+		}
+		items[i] = Run{
+			RunId:    run.RunId,
+			Start:    run.Start.UTC(),
+			End:      utc(run.End),
+			Outcome:  outcome,
+			Duration: run.Duration,
+		}
+	}
+
+	respond.JSON(r.Context(), w, http.StatusOK, GetRunsResponse{
+		Total: total,
+		Items: items,
+	})
+}
+
+// GetIncidents returns check's incidents
+// @Tags Checks
+// @Summary Get incidents
+// @Description Incident starts when check goes down and ends when it goes up
+// @Security cookieAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "check id"
+// @Param params query GetIncidentsQuery true "params"
+// @Success 200 {object} GetIncidentsResponse
+// @router /v1/checks/{id}/incidents [get]
+func (h handler) GetIncidents(w http.ResponseWriter, r *http.Request) {
+	checkId := chi.URLParam(r, "id")
+	err := h.validator.Struct(CheckIdParam{Id: checkId})
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	limit, err := request.IntQueryParam(r, "limit")
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+	offset, err := request.IntQueryParam(r, "offset")
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+	from, err := request.IntQueryParam(r, "from")
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+	to, err := request.IntQueryParam(r, "to")
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	err = h.validator.Struct(GetIncidentsQuery{
+		From:   from,
+		To:     to,
+		Limit:  limit,
+		Offset: &offset,
+	})
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	user := session.User(r.Context())
+	incidents, total, err := h.service.GetIncidents(r.Context(), entity.GetIncidents{
+		CheckId: checkId,
+		UserId:  user.Id,
+		From:    time.UnixMilli(int64(from)),
+		To:      time.UnixMilli(int64(to)),
+		Limit:   limit,
+		Offset:  offset,
+	})
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	items := make([]Incident, len(incidents))
+	for i, incident := range incidents {
+		items[i] = Incident{
+			Id:             incident.Id,
+			Cause:          incident.Cause,
+			StartedAt:      incident.StartedAt.UTC(),
+			EndedAt:        utc(incident.EndedAt),
+			Duration:       incident.Duration,
+			AcknowledgedAt: utc(incident.AcknowledgedAt),
+			AcknowledgedBy: incident.AcknowledgedBy,
+			Notes:          make([]IncidentNote, len(incident.Notes)),
+		}
+		for j, note := range incident.Notes {
+			items[i].Notes[j] = IncidentNote{
+				Text:      note.Text,
+				Author:    note.Author,
+				CreatedAt: note.CreatedAt.UTC(),
+			}
+		}
+	}
+
+	respond.JSON(r.Context(), w, http.StatusOK, GetIncidentsResponse{
+		Total: total,
+		Items: items,
+	})
+}
+
 // checkDTO transforms entity.Check to Check
+// GetUptime returns check's uptime report
+// @Tags Checks
+// @Summary Get uptime
+// @Description Period is split into UTC days, weeks or months. Time before the first flip and after resume until check gets up or down is not counted
+// @Security cookieAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "check id"
+// @Param params query GetUptimeQuery true "params"
+// @Success 200 {array} Uptime
+// @router /v1/checks/{id}/uptime [get]
+func (h handler) GetUptime(w http.ResponseWriter, r *http.Request) {
+	checkId := chi.URLParam(r, "id")
+	err := h.validator.Struct(CheckIdParam{Id: checkId})
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	query, err := h.uptimeQuery(r)
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	user := session.User(r.Context())
+	buckets, err := h.service.GetUptime(r.Context(), entity.GetUptime{
+		CheckId: checkId,
+		UserId:  user.Id,
+		From:    time.UnixMilli(int64(query.From)),
+		To:      time.UnixMilli(int64(query.To)),
+		Bucket:  query.Bucket,
+	})
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	respond.JSON(r.Context(), w, http.StatusOK, uptimeDTO(buckets))
+}
+
+// GetTagsUptime returns uptime report of user's checks aggregated by tag
+// @Tags Checks
+// @Summary Get uptime by tag
+// @Description Durations of checks with the tag are summed up. Checks without tags are not included
+// @Security cookieAuth
+// @Accept json
+// @Produce json
+// @Param params query GetUptimeQuery true "params"
+// @Success 200 {array} TagUptime
+// @router /v1/checks/uptime [get]
+func (h handler) GetTagsUptime(w http.ResponseWriter, r *http.Request) {
+	query, err := h.uptimeQuery(r)
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	user := session.User(r.Context())
+	tags, err := h.service.GetTagsUptime(r.Context(), entity.GetTagsUptime{
+		UserId: user.Id,
+		From:   time.UnixMilli(int64(query.From)),
+		To:     time.UnixMilli(int64(query.To)),
+		Bucket: query.Bucket,
+	})
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	response := make([]TagUptime, len(tags))
+	for i, tag := range tags {
+		response[i] = TagUptime{Tag: tag.Tag, Buckets: uptimeDTO(tag.Buckets)}
+	}
+
+	respond.JSON(r.Context(), w, http.StatusOK, response)
+}
+
+func (h handler) uptimeQuery(r *http.Request) (GetUptimeQuery, error) {
+	from, err := request.IntQueryParam(r, "from")
+	if err != nil {
+		return GetUptimeQuery{}, err
+	}
+	to, err := request.IntQueryParam(r, "to")
+	if err != nil {
+		return GetUptimeQuery{}, err
+	}
+
+	query := GetUptimeQuery{
+		From:   from,
+		To:     to,
+		Bucket: entity.UptimeBucket(r.URL.Query().Get("bucket")),
+	}
+	err = h.validator.Struct(query)
+	if err != nil {
+		return GetUptimeQuery{}, err
+	}
+
+	return query, nil
+}
+
+// GetDurations returns statistics of check's run durations
+// @Tags Checks
+// @Summary Get run durations
+// @Description Statistics of runs finished within the period, and time series of them split into UTC hours, days, weeks or months
+// @Security cookieAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "check id"
+// @Param params query GetDurationsQuery true "params"
+// @Success 200 {object} GetDurationsResponse
+// @router /v1/checks/{id}/durations [get]
+func (h handler) GetDurations(w http.ResponseWriter, r *http.Request) {
+	checkId := chi.URLParam(r, "id")
+	err := h.validator.Struct(CheckIdParam{Id: checkId})
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	from, err := request.IntQueryParam(r, "from")
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+	to, err := request.IntQueryParam(r, "to")
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	query := GetDurationsQuery{
+		From:   from,
+		To:     to,
+		Bucket: entity.DurationBucket(r.URL.Query().Get("bucket")),
+	}
+	err = h.validator.Struct(query)
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	user := session.User(r.Context())
+	stats, series, err := h.service.GetDurations(r.Context(), entity.GetDurations{
+		CheckId: checkId,
+		UserId:  user.Id,
+		From:    time.UnixMilli(int64(from)),
+		To:      time.UnixMilli(int64(to)),
+		Bucket:  query.Bucket,
+	})
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	response := GetDurationsResponse{
+		DurationStats: durationStatsDTO(stats),
+		Series:        make([]DurationPoint, len(series)),
+	}
+	for i, point := range series {
+		response.Series[i] = DurationPoint{Start: point.Start.UTC(), DurationStats: durationStatsDTO(point)}
+	}
+
+	respond.JSON(r.Context(), w, http.StatusOK, response)
+}
+
+// GetMetricHistory returns values of check's metric
+// @Tags Checks
+// @Summary Get metric history
+// @Description Values extracted from JSON bodies of pings received within the period, the earliest first
+// @Security cookieAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "check id"
+// @Param name path string true "metric name"
+// @Param params query GetMetricHistoryQuery true "params"
+// @Success 200 {array} MetricPoint
+// @router /v1/checks/{id}/metrics/{name} [get]
+func (h handler) GetMetricHistory(w http.ResponseWriter, r *http.Request) {
+	checkId := chi.URLParam(r, "id")
+	err := h.validator.Struct(CheckIdParam{Id: checkId})
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	from, err := request.IntQueryParam(r, "from")
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+	to, err := request.IntQueryParam(r, "to")
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	err = h.validator.Struct(GetMetricHistoryQuery{
+		From: from,
+		To:   to,
+	})
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	user := session.User(r.Context())
+	points, err := h.service.GetMetricHistory(r.Context(), entity.GetMetricHistory{
+		CheckId: checkId,
+		UserId:  user.Id,
+		Name:    chi.URLParam(r, "name"),
+		From:    time.UnixMilli(int64(from)),
+		To:      time.UnixMilli(int64(to)),
+	})
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	response := make([]MetricPoint, len(points))
+	for i, point := range points {
+		response[i] = MetricPoint{Date: point.Date.UTC(), Value: point.Value}
+	}
+
+	respond.JSON(r.Context(), w, http.StatusOK, response)
+}
+
 func checkDTO(check entity.Check) Check {
 	response := Check{
-		Id:          check.Id,
-		Name:        check.Name,
-		Description: check.Description,
-		Interval:    check.Interval,
-		Grace:       check.Grace,
-		Status:      check.Status,
-		LastPing:    utc(check.LastPing),
-		NextPing:    utc(check.NextPing),
-		LastStarted: utc(check.LastStarted),
-		Channels:    make([]Channel, len(check.Channels)),
+		Id:                 check.Id,
+		Name:               check.Name,
+		Slug:               check.Slug,
+		Description:        check.Description,
+		Tags:               tags(check.Tags),
+		Schedule:           check.Schedule,
+		Interval:           check.Interval,
+		Cron:               check.Cron,
+		Timezone:           check.Timezone,
+		Grace:              check.Grace,
+		MaxDuration:        check.MaxDuration,
+		FirstPingDeadline:  check.FirstPingDeadline,
+		FailureThreshold:   check.FailureThreshold,
+		Failures:           check.Failures,
+		FlapThreshold:      check.FlapThreshold,
+		FlapWindow:         check.FlapWindow,
+		FlapStablePeriod:   check.FlapStable,
+		EscalationPolicyId: check.EscalationPolicyId,
+		ReminderInterval:   check.ReminderInterval,
+		ReminderLimit:      check.ReminderLimit,
+		SlowDuration:       check.SlowDuration,
+		SlowFactor:         check.SlowFactor,
+		MinDuration:        check.MinDuration,
+		MinDurationAction:  check.MinDurationAction,
+		Fleet:              check.Fleet,
+		FleetTolerance:     check.FleetTolerance,
+		InstanceTTL:        check.InstanceTTL,
+		Severity:           check.Severity,
+		RemindersStoppedAt: utc(check.RemindersStoppedAt),
+		FlappingSince:      utc(check.FlappingSince),
+		CreatedAt:          check.CreatedAt.UTC(),
+		PausePolicy:        check.PausePolicy,
+		ResumeAt:           utc(check.ResumeAt),
+		Status:             check.Status,
+		LastPing:           utc(check.LastPing),
+		NextPing:           utc(check.NextPing),
+		LastStarted:        utc(check.LastStarted),
+		Channels:           make([]Channel, len(check.Channels)),
+		BodyRules:          make([]BodyRule, len(check.BodyRules)),
+		Metrics:            make([]Metric, len(check.Metrics)),
+		MetricRules:        make([]MetricRule, len(check.MetricRules)),
+		Instances:          make([]Instance, len(check.Instances)),
 	}
 	for i, channel := range check.Channels {
 		response.Channels[i] = Channel{
@@ -394,10 +979,106 @@ func checkDTO(check entity.Check) Check {
 			WebhookURLDown: channel.WebhookURLDown,
 		}
 	}
+	for i, rule := range check.BodyRules {
+		response.BodyRules[i] = bodyRuleDTO(rule)
+	}
+	for i, metric := range check.Metrics {
+		response.Metrics[i] = Metric{Name: metric.Name, Path: metric.Path}
+	}
+	for i, rule := range check.MetricRules {
+		response.MetricRules[i] = metricRuleDTO(rule)
+	}
+	for i, instance := range check.Instances {
+		response.Instances[i] = Instance{
+			Name:     instance.Name,
+			Status:   instance.Status,
+			LastPing: instance.LastPing.UTC(),
+			NextPing: utc(instance.NextPing),
+		}
+	}
 
 	return response
 }
 
+// scheduleFromBody returns check's schedule from the request body filled with default values
+func scheduleFromBody(body CreateCheckBody) entity.Schedule {
+	s := entity.Schedule{
+		Kind:     body.Schedule,
+		Interval: body.Interval,
+		Cron:     body.Cron,
+		Timezone: body.Timezone,
+	}
+	if s.Kind == "" {
+		s.Kind = entity.ScheduleSimple
+	}
+	if s.Kind == entity.ScheduleCron {
+		s.Interval = 0
+	} else {
+		s.Cron = ""
+	}
+	if s.Timezone == "" {
+		s.Timezone = entity.DefaultTimezone
+	}
+
+	return s
+}
+
+// pausePolicy returns pause policy from the request body or the default one
+func pausePolicy(policy entity.PausePolicy) entity.PausePolicy {
+	if policy == "" {
+		return entity.DefaultPausePolicy
+	}
+	return policy
+}
+
+// failureThreshold returns failure threshold from the request body or the default one
+func failureThreshold(threshold int) int {
+	if threshold == 0 {
+		return entity.DefaultFailureThreshold
+	}
+	return threshold
+}
+
+// reminderLimit returns max number of reminders from the request body or the default one
+func reminderLimit(limit int) int {
+	if limit == 0 {
+		return entity.DefaultReminderLimit
+	}
+	return limit
+}
+
+// defaultPeriod returns period in seconds from the request body or the default one, which is 1 hour
+func defaultPeriod(period int) int {
+	if period == 0 {
+		return entity.DefaultFlapPeriod
+	}
+	return period
+}
+
+// instanceTTL returns instance TTL in seconds from the request body or the default one, which is 1 day
+func instanceTTL(ttl int) int {
+	if ttl == 0 {
+		return entity.DefaultInstanceTTL
+	}
+	return ttl
+}
+
+// severity returns severity from the request body or the default one
+func severity(severity entity.CheckSeverity) entity.CheckSeverity {
+	if severity == "" {
+		return entity.DefaultSeverity
+	}
+	return severity
+}
+
+// tags returns check's tags, empty slice if there are no tags
+func tags(t entity.Tags) []string {
+	if t == nil {
+		return []string{}
+	}
+	return t
+}
+
 func utc(time *time.Time) *time.Time {
 	if time == nil {
 		return nil
@@ -405,3 +1086,71 @@ func utc(time *time.Time) *time.Time {
 	val := time.UTC()
 	return &val
 }
+
+func uptimeDTO(buckets []entity.Uptime) []Uptime {
+	response := make([]Uptime, len(buckets))
+	for i, bucket := range buckets {
+		response[i] = Uptime{
+			Start:  bucket.Start,
+			End:    bucket.End,
+			Up:     int(bucket.Up.Seconds()),
+			Down:   int(bucket.Down.Seconds()),
+			Paused: int(bucket.Paused.Seconds()),
+		}
+		if monitored := bucket.Up + bucket.Down; monitored > 0 {
+			availability := math.Round(float64(bucket.Up)/float64(monitored)*10000) / 100
+			response[i].Availability = &availability
+		}
+	}
+
+	return response
+}
+
+func durationStatsDTO(stats entity.DurationStats) DurationStats {
+	return DurationStats{
+		Runs: stats.Runs,
+		Min:  stats.Min,
+		Avg:  stats.Avg,
+		P50:  stats.P50,
+		P95:  stats.P95,
+		Max:  stats.Max,
+	}
+}
+
+func minDurationAction(action entity.MinDurationAction) entity.MinDurationAction {
+	if action == "" {
+		return entity.DefaultMinDurationAction
+	}
+	return action
+}
+
+func pingRules(body CreateCheckBody) entity.PingRules {
+	rules := entity.PingRules{
+		Body:        make(entity.BodyRules, len(body.BodyRules)),
+		Metrics:     make(entity.Metrics, len(body.Metrics)),
+		MetricRules: make(entity.MetricRules, len(body.MetricRules)),
+	}
+	for i, rule := range body.BodyRules {
+		rules.Body[i] = entity.BodyRule{Match: rule.Match, Pattern: rule.Pattern, Outcome: rule.Outcome}
+	}
+	for i, metric := range body.Metrics {
+		rules.Metrics[i] = entity.Metric{Name: metric.Name, Path: metric.Path}
+	}
+	for i, rule := range body.MetricRules {
+		rules.MetricRules[i] = entity.MetricRule{
+			Metric:    rule.Metric,
+			Op:        rule.Op,
+			Threshold: rule.Threshold,
+			Outcome:   rule.Outcome,
+		}
+	}
+	return rules
+}
+
+func bodyRuleDTO(rule entity.BodyRule) BodyRule {
+	return BodyRule{Match: rule.Match, Pattern: rule.Pattern, Outcome: rule.Outcome}
+}
+
+func metricRuleDTO(rule entity.MetricRule) MetricRule {
+	return MetricRule{Metric: rule.Metric, Op: rule.Op, Threshold: rule.Threshold, Outcome: rule.Outcome}
+}
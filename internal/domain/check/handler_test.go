@@ -149,6 +149,232 @@ func TestHandler_CreateCheck_InvalidInput(t *testing.T) {
 	test.CheckCode(t, http.StatusBadRequest, response.Code)
 }
 
+func TestHandler_CreateCheck_CronSchedule(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	channels := getChannels(t, cookie)
+
+	dto := check.CreateCheckBody{
+		Name:        "testcheck",
+		Description: "some description",
+		Schedule:    entity.ScheduleCron,
+		Cron:        "0 3 * * 1-5",
+		Timezone:    "Europe/Berlin",
+		Grace:       3600,
+		Channels:    []int{channels[0].Id},
+	}
+	ch := createCheck(t, cookie, dto)
+
+	req, _ := http.NewRequest("PUT", "/v1/pings/"+ch.Id, nil)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	req, _ = http.NewRequest("GET", "/v1/checks/"+ch.Id, nil)
+	req.Header.Set("Cookie", cookie)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var found check.Check
+	err := json.Unmarshal(response.Body.Bytes(), &found)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if found.Schedule != dto.Schedule || found.Cron != dto.Cron || found.Timezone != dto.Timezone {
+		t.Errorf("want check schedule to be %v %q %v, got %v %q %v",
+			dto.Schedule, dto.Cron, dto.Timezone, found.Schedule, found.Cron, found.Timezone)
+	}
+	if found.NextPing == nil || !found.NextPing.After(*found.LastPing) {
+		t.Fatalf("want check NextPing to be after LastPing, got %v", found.NextPing)
+	}
+
+	loc, err := time.LoadLocation(dto.Timezone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := found.NextPing.In(loc)
+	if next.Hour() != 3 || next.Minute() != 0 || next.Weekday() == time.Saturday || next.Weekday() == time.Sunday {
+		t.Errorf("want check NextPing to be at 03:00 on a weekday in %v, got %v", dto.Timezone, next)
+	}
+}
+
+func TestHandler_CreateCheck_InvalidCron(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	channels := getChannels(t, cookie)
+
+	dto := check.CreateCheckBody{
+		Name:        "testcheck",
+		Description: "some description",
+		Schedule:    entity.ScheduleCron,
+		Cron:        "0 3 * *",
+		Timezone:    "Mars/Olympus",
+		Grace:       3600,
+		Channels:    []int{channels[0].Id},
+	}
+	body, err := json.Marshal(dto)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest("POST", "/v1/checks", bytes.NewReader(body))
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+
+	test.CheckCode(t, http.StatusBadRequest, response.Code)
+}
+
+func TestHandler_CreateCheck_BodyRules(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	channels := getChannels(t, cookie)
+
+	dto := check.CreateCheckBody{
+		Name:        "testcheck",
+		Description: "some description",
+		Interval:    60,
+		Grace:       3600,
+		Channels:    []int{channels[0].Id},
+		BodyRules: []check.BodyRule{
+			{Match: entity.BodyRuleRegex, Pattern: "(error", Outcome: entity.PingFail},
+		},
+	}
+	body, err := json.Marshal(dto)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest("POST", "/v1/checks", bytes.NewReader(body))
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusBadRequest, response.Code)
+
+	dto.BodyRules = []check.BodyRule{
+		{Match: entity.BodyRuleRegex, Pattern: "(?i)error", Outcome: entity.PingFail},
+		{Match: entity.BodyRuleSubstring, Pattern: "OK", Outcome: entity.PingSuccess},
+	}
+	ch := createCheck(t, cookie, dto)
+
+	req, _ = http.NewRequest("GET", "/v1/checks/"+ch.Id, nil)
+	req.Header.Set("Cookie", cookie)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var found check.Check
+	err = json.Unmarshal(response.Body.Bytes(), &found)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(found.BodyRules, dto.BodyRules) {
+		t.Errorf("want body rules %+v, got %+v", dto.BodyRules, found.BodyRules)
+	}
+}
+
+func TestHandler_CreateCheck_MaxDuration(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	channels := getChannels(t, cookie)
+
+	maxDuration := 600
+	ch := createCheck(t, cookie, check.CreateCheckBody{
+		Name:        "testcheck",
+		Description: "some description",
+		Interval:    60,
+		Grace:       3600,
+		MaxDuration: &maxDuration,
+		Channels:    []int{channels[0].Id},
+	})
+
+	req, _ := http.NewRequest("GET", "/v1/checks/"+ch.Id, nil)
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var found check.Check
+	err := json.Unmarshal(response.Body.Bytes(), &found)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if found.MaxDuration == nil || *found.MaxDuration != maxDuration {
+		t.Errorf("want check MaxDuration to be %v, got %v", maxDuration, found.MaxDuration)
+	}
+}
+
+func TestHandler_CreateCheck_FirstPingDeadline(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	channels := getChannels(t, cookie)
+
+	deadline := 3600
+	ch := createCheck(t, cookie, check.CreateCheckBody{
+		Name:              "testcheck",
+		Description:       "some description",
+		Interval:          60,
+		Grace:             3600,
+		FirstPingDeadline: &deadline,
+		Channels:          []int{channels[0].Id},
+	})
+
+	req, _ := http.NewRequest("GET", "/v1/checks/"+ch.Id, nil)
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var found check.Check
+	err := json.Unmarshal(response.Body.Bytes(), &found)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if found.FirstPingDeadline == nil || *found.FirstPingDeadline != deadline {
+		t.Errorf("want check FirstPingDeadline to be %v, got %v", deadline, found.FirstPingDeadline)
+	}
+	if found.CreatedAt.IsZero() {
+		t.Error("want check CreatedAt to be set")
+	}
+}
+
+func TestHandler_CreateCheck_Severity(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	channels := getChannels(t, cookie)
+
+	dto := check.CreateCheckBody{
+		Name:        "testcheck",
+		Description: "some description",
+		Interval:    60,
+		Grace:       3600,
+		Channels:    []int{channels[0].Id},
+	}
+	chDefault := createCheck(t, cookie, dto)
+	dto.Severity = entity.SeverityWarning
+	ch := createCheck(t, cookie, dto)
+
+	for id, want := range map[string]entity.CheckSeverity{
+		chDefault.Id: entity.SeverityCritical,
+		ch.Id:        entity.SeverityWarning,
+	} {
+		req, _ := http.NewRequest("GET", "/v1/checks/"+id, nil)
+		req.Header.Set("Cookie", cookie)
+		response := test.ExecuteRequest(s, req)
+		test.CheckCode(t, http.StatusOK, response.Code)
+
+		var found check.Check
+		err := json.Unmarshal(response.Body.Bytes(), &found)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found.Severity != want {
+			t.Errorf("want check Severity to be %v, got %v", want, found.Severity)
+		}
+	}
+
+	dto.Severity = "fatal"
+	body, err := json.Marshal(dto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest("POST", "/v1/checks", bytes.NewReader(body))
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusBadRequest, response.Code)
+}
+
 func TestHandler_UpdateCheck_ValidInput(t *testing.T) {
 	cookie, _ := test.Authorize(t, s)
 	channels := getChannels(t, cookie)
@@ -232,6 +458,48 @@ func TestHandler_UpdateCheck_ValidInput(t *testing.T) {
 	}
 }
 
+func TestHandler_UpdateCheck_Reschedule(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	channels := getChannels(t, cookie)
+
+	dto := check.CreateCheckBody{
+		Name:        "testcheck",
+		Description: "some description",
+		Interval:    86400,
+		Grace:       3600,
+		Channels:    []int{channels[0].Id},
+	}
+	ch := createCheck(t, cookie, dto)
+
+	req, _ := http.NewRequest("PUT", "/v1/pings/"+ch.Id, nil)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	dto.Interval = 60
+	body, err := json.Marshal(check.UpdateCheckBody{CreateCheckBody: dto})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ = http.NewRequest("PUT", "/v1/checks/"+ch.Id, bytes.NewReader(body))
+	req.Header.Set("Cookie", cookie)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	req, _ = http.NewRequest("GET", "/v1/checks/"+ch.Id, nil)
+	req.Header.Set("Cookie", cookie)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var found check.Check
+	err = json.Unmarshal(response.Body.Bytes(), &found)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.NextPing == nil || found.NextPing.After(time.Now().Add(2*time.Minute)) {
+		t.Errorf("want check NextPing to follow the new interval, got %v", found.NextPing)
+	}
+}
+
 func TestHandler_UpdateCheck_InvalidInput(t *testing.T) {
 	cookie, _ := test.Authorize(t, s)
 
@@ -358,6 +626,102 @@ func TestHandler_PauseCheck(t *testing.T) {
 	}
 }
 
+func TestHandler_ResumeCheck(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	channels := getChannels(t, cookie)
+
+	ch := createCheck(t, cookie, check.CreateCheckBody{
+		Name:        "testcheck",
+		Description: "some description",
+		Interval:    60,
+		Grace:       3600,
+		Channels:    []int{channels[0].Id},
+	})
+
+	req, _ := http.NewRequest("PUT", "/v1/checks/"+ch.Id+"/resume", nil)
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusBadRequest, response.Code)
+
+	req, _ = http.NewRequest("PUT", "/v1/checks/"+ch.Id+"/pause", nil)
+	req.Header.Set("Cookie", cookie)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	req, _ = http.NewRequest("PUT", "/v1/checks/"+ch.Id+"/resume", nil)
+	req.Header.Set("Cookie", cookie)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	req, _ = http.NewRequest("GET", "/v1/checks/"+ch.Id, nil)
+	req.Header.Set("Cookie", cookie)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var found check.Check
+	err := json.Unmarshal(response.Body.Bytes(), &found)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if found.Status != entity.CheckUp {
+		t.Errorf("want check Status to be %v, got %v", entity.CheckUp, found.Status)
+	}
+	if found.NextPing == nil {
+		t.Error("want check NextPing not to be nil")
+	}
+
+	var flip int
+	err = s.DB().Get(&flip, `SELECT count(*) FROM flips WHERE check_id = $1 AND "to" = $2`, ch.Id, entity.FlipResumed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flip != 1 {
+		t.Errorf("want flip to %v to be created", entity.FlipResumed)
+	}
+}
+
+func TestHandler_PauseCheck_ResumeAt(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	channels := getChannels(t, cookie)
+
+	ch := createCheck(t, cookie, check.CreateCheckBody{
+		Name:        "testcheck",
+		Description: "some description",
+		Interval:    60,
+		Grace:       3600,
+		Channels:    []int{channels[0].Id},
+	})
+
+	url := fmt.Sprintf("/v1/checks/%v/pause?resumeAt=%v", ch.Id, time.Now().Add(-time.Hour).UnixMilli())
+	req, _ := http.NewRequest("PUT", url, nil)
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusBadRequest, response.Code)
+
+	resumeAt := time.Now().Add(time.Hour).Truncate(time.Millisecond)
+	url = fmt.Sprintf("/v1/checks/%v/pause?resumeAt=%v", ch.Id, resumeAt.UnixMilli())
+	req, _ = http.NewRequest("PUT", url, nil)
+	req.Header.Set("Cookie", cookie)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	req, _ = http.NewRequest("GET", "/v1/checks/"+ch.Id, nil)
+	req.Header.Set("Cookie", cookie)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var found check.Check
+	err := json.Unmarshal(response.Body.Bytes(), &found)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if found.ResumeAt == nil || !found.ResumeAt.Equal(resumeAt) {
+		t.Errorf("want check ResumeAt to be %v, got %v", resumeAt, found.ResumeAt)
+	}
+}
+
 func TestHandler_GetPings(t *testing.T) {
 	cookie, _ := test.Authorize(t, s)
 	channels := getChannels(t, cookie)
@@ -435,6 +799,372 @@ func TestHandler_GetFlips(t *testing.T) {
 	}
 
 	if len(flips.Items) != 2 {
-		t.Errorf("want 2 flips, got %v", len(flips.Items))
+		t.Fatalf("want 2 flips, got %v", len(flips.Items))
+	}
+	if flips.Items[0].Reason != entity.FlipFail {
+		t.Errorf("want down flip reason to be %v, got %v", entity.FlipFail, flips.Items[0].Reason)
+	}
+}
+
+func TestHandler_GetRuns(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	channels := getChannels(t, cookie)
+
+	dto := check.CreateCheckBody{
+		Name:        "testcheck",
+		Description: "some description",
+		Interval:    60,
+		Grace:       3600,
+		Channels:    []int{channels[0].Id},
+	}
+	ch := createCheck(t, cookie, dto)
+
+	now := time.Now().UnixMilli()
+
+	// two overlapping runs and one unfinished run
+	ridA := "6e5a3a8e-0c55-4d7e-8a5e-9b1c8c2c0b01"
+	ridB := "6e5a3a8e-0c55-4d7e-8a5e-9b1c8c2c0b02"
+	ridC := "6e5a3a8e-0c55-4d7e-8a5e-9b1c8c2c0b03"
+	for _, path := range []string{
+		"/start?rid=" + ridA,
+		"/start?rid=" + ridB,
+		"/fail?rid=" + ridA,
+		"?rid=" + ridB,
+		"/start?rid=" + ridC,
+	} {
+		req, _ := http.NewRequest("PUT", "/v1/pings/"+ch.Id+path, nil)
+		response := test.ExecuteRequest(s, req)
+		test.CheckCode(t, http.StatusOK, response.Code)
+	}
+
+	url := fmt.Sprintf("/v1/checks/%v/runs?limit=10&offset=0&from=%v&to=%v", ch.Id, now, time.Now().UnixMilli())
+	req, _ := http.NewRequest("GET", url, nil)
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var runs check.GetRunsResponse
+	err := json.Unmarshal(response.Body.Bytes(), &runs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if runs.Total != 3 || len(runs.Items) != 3 {
+		t.Fatalf("want 3 runs, got %v (total %v)", len(runs.Items), runs.Total)
+	}
+	outcomes := make(map[string]check.RunOutcome)
+	for _, run := range runs.Items {
+		outcomes[*run.RunId] = run.Outcome
+		if run.Outcome != check.RunUnfinished && (run.End == nil || run.Duration == nil) {
+			t.Errorf("want finished run %v to have End and Duration", *run.RunId)
+		}
+	}
+	want := map[string]check.RunOutcome{ridA: check.RunFail, ridB: check.RunSuccess, ridC: check.RunUnfinished}
+	if diff := cmp.Diff(want, outcomes); diff != "" {
+		t.Errorf("want runs outcomes to be the same, diff (-want, +got)\n: %s", diff)
+	}
+}
+
+func TestHandler_GetFlips_Flapping(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	channels := getChannels(t, cookie)
+
+	flapThreshold := 2
+	dto := check.CreateCheckBody{
+		Name:          "testcheck",
+		Description:   "some description",
+		Interval:      60,
+		Grace:         3600,
+		FlapThreshold: &flapThreshold,
+		Channels:      []int{channels[0].Id},
+	}
+	ch := createCheck(t, cookie, dto)
+
+	now := time.Now().UnixMilli()
+
+	for _, path := range []string{"", "/fail", ""} {
+		req, _ := http.NewRequest("PUT", "/v1/pings/"+ch.Id+path, nil)
+		response := test.ExecuteRequest(s, req)
+		test.CheckCode(t, http.StatusOK, response.Code)
+	}
+
+	req, _ := http.NewRequest("GET", "/v1/checks/"+ch.Id, nil)
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var c check.Check
+	err := json.Unmarshal(response.Body.Bytes(), &c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.FlappingSince == nil {
+		t.Error("want check FlappingSince not to be nil")
+	}
+
+	url := fmt.Sprintf("/v1/checks/%v/flips?limit=10&offset=0&from=%v&to=%v", ch.Id, now, time.Now().UnixMilli())
+	req, _ = http.NewRequest("GET", url, nil)
+	req.Header.Set("Cookie", cookie)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var flips check.GetFlipsResponse
+	err = json.Unmarshal(response.Body.Bytes(), &flips)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var flappingFlips, suppressedFlips int
+	for _, flip := range flips.Items {
+		if flip.To == entity.FlipFlapping {
+			flappingFlips++
+		}
+		if flip.Suppressed {
+			suppressedFlips++
+		}
+	}
+	if flappingFlips != 1 || suppressedFlips != 1 {
+		t.Errorf("want 1 flapping and 1 suppressed flip, got %v and %v", flappingFlips, suppressedFlips)
+	}
+}
+
+func TestHandler_StopReminders(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	channels := getChannels(t, cookie)
+
+	interval := 600
+	ch := createCheck(t, cookie, check.CreateCheckBody{
+		Name:             "testcheck",
+		Description:      "some description",
+		Interval:         60,
+		Grace:            3600,
+		ReminderInterval: &interval,
+		ReminderLimit:    3,
+		Channels:         []int{channels[0].Id},
+	})
+
+	// reminders of up check can't be stopped
+	req, _ := http.NewRequest("PUT", "/v1/checks/"+ch.Id+"/stop-reminders", nil)
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusBadRequest, response.Code)
+
+	req, _ = http.NewRequest("PUT", "/v1/pings/"+ch.Id+"/fail", nil)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	req, _ = http.NewRequest("PUT", "/v1/checks/"+ch.Id+"/stop-reminders", nil)
+	req.Header.Set("Cookie", cookie)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	req, _ = http.NewRequest("GET", "/v1/checks/"+ch.Id, nil)
+	req.Header.Set("Cookie", cookie)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var found check.Check
+	err := json.Unmarshal(response.Body.Bytes(), &found)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if found.ReminderInterval == nil || *found.ReminderInterval != interval || found.ReminderLimit != 3 {
+		t.Errorf("want reminder interval %v and limit 3, got %v and %v", interval, found.ReminderInterval, found.ReminderLimit)
+	}
+	if found.RemindersStoppedAt == nil {
+		t.Error("want check RemindersStoppedAt to be set")
+	}
+}
+
+func TestHandler_GetUptime(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	channels := getChannels(t, cookie)
+
+	dto := check.CreateCheckBody{
+		Name:        "testcheck",
+		Description: "some description",
+		Tags:        []string{"nightly", "db"},
+		Interval:    60,
+		Grace:       3600,
+		Channels:    []int{channels[0].Id},
+	}
+	ch := createCheck(t, cookie, dto)
+
+	// the api takes milliseconds
+	from := time.UnixMilli(time.Now().Add(-time.Hour * 48).UnixMilli())
+	to := time.UnixMilli(time.Now().Add(time.Hour).UnixMilli())
+
+	// up for a second, then down for a second
+	for _, path := range []string{"", "/fail"} {
+		req, _ := http.NewRequest("PUT", "/v1/pings/"+ch.Id+path, nil)
+		response := test.ExecuteRequest(s, req)
+		test.CheckCode(t, http.StatusOK, response.Code)
+		time.Sleep(time.Millisecond * 1100)
+	}
+
+	url := fmt.Sprintf("/v1/checks/%v/uptime?from=%v&to=%v&bucket=day", ch.Id, from.UnixMilli(), to.UnixMilli())
+	req, _ := http.NewRequest("GET", url, nil)
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var buckets []check.Uptime
+	err := json.Unmarshal(response.Body.Bytes(), &buckets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buckets) < 3 || !buckets[0].Start.Equal(from.UTC()) || !buckets[len(buckets)-1].End.Equal(to.UTC()) {
+		t.Fatalf("want at least 3 buckets covering the period, got %+v", buckets)
+	}
+	if buckets[0].Availability != nil {
+		t.Errorf("want no availability before the first flip, got %v", *buckets[0].Availability)
+	}
+
+	var up, down int
+	for _, bucket := range buckets {
+		up += bucket.Up
+		down += bucket.Down
+	}
+	if up < 1 || down < 1 {
+		t.Errorf("want check to be up and down for at least a second, got %v and %v", up, down)
+	}
+
+	url = fmt.Sprintf("/v1/checks/uptime?from=%v&to=%v&bucket=month", from.UnixMilli(), to.UnixMilli())
+	req, _ = http.NewRequest("GET", url, nil)
+	req.Header.Set("Cookie", cookie)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var tags []check.TagUptime
+	err = json.Unmarshal(response.Body.Bytes(), &tags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 2 || tags[0].Tag != "db" || tags[1].Tag != "nightly" {
+		t.Fatalf("want uptime of db and nightly tags, got %+v", tags)
+	}
+	var tagUp int
+	for _, bucket := range tags[0].Buckets {
+		tagUp += bucket.Up
+	}
+	if tagUp != up {
+		t.Errorf("want tag up time to be %v, got %v", up, tagUp)
+	}
+}
+
+func TestHandler_GetUptime_InvalidInput(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	channels := getChannels(t, cookie)
+
+	ch := createCheck(t, cookie, check.CreateCheckBody{
+		Name:        "testcheck",
+		Description: "some description",
+		Interval:    60,
+		Grace:       3600,
+		Channels:    []int{channels[0].Id},
+	})
+
+	now := time.Now()
+	queries := []string{
+		fmt.Sprintf("from=%v&to=%v&bucket=year", now.Add(-time.Hour).UnixMilli(), now.UnixMilli()),
+		fmt.Sprintf("from=%v&to=%v&bucket=day", now.UnixMilli(), now.Add(-time.Hour).UnixMilli()),
+		fmt.Sprintf("from=%v&to=%v&bucket=day", now.AddDate(-2, 0, 0).UnixMilli(), now.UnixMilli()),
+	}
+	for _, query := range queries {
+		req, _ := http.NewRequest("GET", "/v1/checks/"+ch.Id+"/uptime?"+query, nil)
+		req.Header.Set("Cookie", cookie)
+		response := test.ExecuteRequest(s, req)
+		test.CheckCode(t, http.StatusBadRequest, response.Code)
+	}
+}
+
+func TestHandler_GetDurations(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	channels := getChannels(t, cookie)
+
+	slowDuration := 1
+	ch := createCheck(t, cookie, check.CreateCheckBody{
+		Name:         "testcheck",
+		Description:  "some description",
+		Interval:     60,
+		Grace:        3600,
+		SlowDuration: &slowDuration,
+		Channels:     []int{channels[0].Id},
+	})
+
+	from := time.Now().Add(-time.Hour).UnixMilli()
+
+	// a fast run and a slow one
+	for _, wait := range []time.Duration{0, time.Millisecond * 1600} {
+		req, _ := http.NewRequest("PUT", "/v1/pings/"+ch.Id+"/start", nil)
+		response := test.ExecuteRequest(s, req)
+		test.CheckCode(t, http.StatusOK, response.Code)
+
+		time.Sleep(wait)
+
+		req, _ = http.NewRequest("PUT", "/v1/pings/"+ch.Id, nil)
+		response = test.ExecuteRequest(s, req)
+		test.CheckCode(t, http.StatusOK, response.Code)
+	}
+
+	to := time.Now().Add(time.Hour).UnixMilli()
+	url := fmt.Sprintf("/v1/checks/%v/durations?from=%v&to=%v&bucket=hour", ch.Id, from, to)
+	req, _ := http.NewRequest("GET", url, nil)
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var durations check.GetDurationsResponse
+	err := json.Unmarshal(response.Body.Bytes(), &durations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if durations.Runs != 2 || durations.Min != 0 || durations.Max != 2 || durations.Avg != 1 {
+		t.Errorf("want 2 runs of 0 and 2 seconds, got %+v", durations.DurationStats)
+	}
+	var seriesRuns int
+	for _, point := range durations.Series {
+		seriesRuns += point.Runs
+	}
+	if seriesRuns != 2 {
+		t.Errorf("want 2 runs in series, got %v", seriesRuns)
+	}
+
+	url = fmt.Sprintf("/v1/checks/%v/pings?limit=10&offset=0&from=%v&to=%v", ch.Id, from, to)
+	req, _ = http.NewRequest("GET", url, nil)
+	req.Header.Set("Cookie", cookie)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var pings check.GetPingsResponse
+	err = json.Unmarshal(response.Body.Bytes(), &pings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var slow int
+	for _, ping := range pings.Items {
+		if ping.SlowThreshold != nil {
+			slow++
+		}
+	}
+	if slow != 1 || pings.Items[0].SlowThreshold == nil || *pings.Items[0].SlowThreshold != slowDuration {
+		t.Errorf("want the last ping to finish slow run, got %+v", pings.Items)
+	}
+
+	// the check stays up
+	req, _ = http.NewRequest("GET", "/v1/checks/"+ch.Id, nil)
+	req.Header.Set("Cookie", cookie)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var c check.Check
+	err = json.Unmarshal(response.Body.Bytes(), &c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Status != entity.CheckUp {
+		t.Errorf("want check status %v, got %v", entity.CheckUp, c.Status)
 	}
 }
@@ -2,12 +2,20 @@ package check
 
 import (
 	"context"
+	"fmt"
 	"gitlab.com/grygoryz/uptime-checker/internal/entity"
 	"gitlab.com/grygoryz/uptime-checker/internal/repository"
+	"gitlab.com/grygoryz/uptime-checker/internal/schedule"
+	"gitlab.com/grygoryz/uptime-checker/internal/uptime"
 	"gitlab.com/grygoryz/uptime-checker/internal/utility/errors"
+	"regexp"
+	"sort"
 	"time"
 )
 
+// maxUptimeBuckets limits size of uptime reports, e.g. a year split into days
+const maxUptimeBuckets = 366
+
 type service struct {
 	r *repository.Registry
 }
@@ -24,10 +32,24 @@ func (s *service) GetCheck(ctx context.Context, userId int, checkId string) (ent
 	return s.r.Check.Get(ctx, entity.GetCheck{Id: checkId, UserId: userId})
 }
 
-func (s *service) CreateCheck(ctx context.Context, check entity.CreateCheck, channels []int) (string, error) {
+func (s *service) CreateCheck(
+	ctx context.Context,
+	check entity.CreateCheck,
+	channels []int,
+	rules entity.PingRules,
+) (string, error) {
+	err := checkPingRules(rules)
+	if err != nil {
+		return "", err
+	}
+
 	var id string
-	err := s.r.WithTx(ctx, func(ctx context.Context) error {
-		var err error
+	err = s.r.WithTx(ctx, func(ctx context.Context) error {
+		err := s.checkPolicy(ctx, check.EscalationPolicyId, check.UserId)
+		if err != nil {
+			return err
+		}
+
 		id, err = s.r.Check.Create(ctx, check)
 		if err != nil {
 			return err
@@ -38,15 +60,54 @@ func (s *service) CreateCheck(ctx context.Context, check entity.CreateCheck, cha
 			return err
 		}
 
+		err = s.r.Check.SetBodyRules(ctx, entity.SetBodyRules{CheckId: id, Rules: rules.Body})
+		if err != nil {
+			return err
+		}
+
+		err = s.r.Check.SetMetrics(ctx, entity.SetMetrics{
+			CheckId: id,
+			Metrics: rules.Metrics,
+			Rules:   rules.MetricRules,
+		})
+		if err != nil {
+			return err
+		}
+
 		return nil
 	})
 
 	return id, err
 }
 
-func (s *service) UpdateCheck(ctx context.Context, check entity.UpdateCheck, channels []int) error {
+func (s *service) UpdateCheck(
+	ctx context.Context,
+	check entity.UpdateCheck,
+	channels []int,
+	rules entity.PingRules,
+) error {
+	err := checkPingRules(rules)
+	if err != nil {
+		return err
+	}
+
 	return s.r.WithTx(ctx, func(ctx context.Context) error {
-		err := s.r.Check.Update(ctx, check)
+		err := s.checkPolicy(ctx, check.EscalationPolicyId, check.UserId)
+		if err != nil {
+			return err
+		}
+
+		old, err := s.r.Check.Get(ctx, entity.GetCheck{Id: check.Id, UserId: check.UserId})
+		if err != nil {
+			return err
+		}
+
+		err = s.r.Check.Update(ctx, check)
+		if err != nil {
+			return err
+		}
+
+		err = s.reschedule(ctx, old, check)
 		if err != nil {
 			return err
 		}
@@ -61,6 +122,20 @@ func (s *service) UpdateCheck(ctx context.Context, check entity.UpdateCheck, cha
 			return err
 		}
 
+		err = s.r.Check.SetBodyRules(ctx, entity.SetBodyRules{CheckId: check.Id, Rules: rules.Body})
+		if err != nil {
+			return err
+		}
+
+		err = s.r.Check.SetMetrics(ctx, entity.SetMetrics{
+			CheckId: check.Id,
+			Metrics: rules.Metrics,
+			Rules:   rules.MetricRules,
+		})
+		if err != nil {
+			return err
+		}
+
 		return nil
 	})
 }
@@ -69,18 +144,14 @@ func (s *service) DeleteCheck(ctx context.Context, check entity.DeleteCheck) err
 	return s.r.Check.Delete(ctx, check)
 }
 
-func (s *service) PauseCheck(ctx context.Context, checkId string, userId int) error {
+func (s *service) PauseCheck(ctx context.Context, check entity.PauseCheck) error {
 	return s.r.WithTx(ctx, func(ctx context.Context) error {
-		status, err := s.r.Check.GetStatus(ctx, checkId)
+		status, err := s.r.Check.GetStatus(ctx, check.Id)
 		if status == entity.CheckPaused {
 			return errors.E(errors.Validation, "check is paused already")
 		}
 
-		err = s.r.Check.SetStatus(ctx, entity.SetCheckStatus{
-			Id:     checkId,
-			UserId: userId,
-			Status: entity.CheckPaused,
-		})
+		err = s.r.Check.Pause(ctx, check)
 		if err != nil {
 			return err
 		}
@@ -88,6 +159,62 @@ func (s *service) PauseCheck(ctx context.Context, checkId string, userId int) er
 		err = s.r.Flip.Create(ctx, entity.CreateFlip{
 			To:      entity.FlipPaused,
 			Date:    time.Now(),
+			CheckId: check.Id,
+		})
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// reschedule moves the next expected ping of the check to its new schedule, so the check doesn't go down on
+// the deadline of the old one. Checks which don't wait for a ping and paused ones, which are rescheduled on
+// resume, are left as they are
+func (s *service) reschedule(ctx context.Context, old entity.Check, check entity.UpdateCheck) error {
+	prev := entity.Schedule{Kind: old.Schedule, Interval: old.Interval, Cron: old.Cron, Timezone: old.Timezone}
+	sched := entity.Schedule{Kind: check.Schedule, Interval: check.Interval, Cron: check.Cron, Timezone: check.Timezone}
+	if sched == prev || old.NextPing == nil || old.Status == entity.CheckPaused {
+		return nil
+	}
+
+	next, err := schedule.Next(sched, time.Now())
+	if err != nil {
+		return err
+	}
+	return s.r.Check.SetNextPing(ctx, check.Id, next)
+}
+
+func (s *service) ResumeCheck(ctx context.Context, checkId string, userId int) error {
+	return s.r.WithTx(ctx, func(ctx context.Context) error {
+		check, err := s.r.Check.Get(ctx, entity.GetCheck{Id: checkId, UserId: userId})
+		if err != nil {
+			return err
+		}
+		if check.Status != entity.CheckPaused {
+			return errors.E(errors.Validation, "check is not paused")
+		}
+
+		now := time.Now()
+		next, err := schedule.Next(entity.Schedule{
+			Kind:     check.Schedule,
+			Interval: check.Interval,
+			Cron:     check.Cron,
+			Timezone: check.Timezone,
+		}, now)
+		if err != nil {
+			return err
+		}
+
+		err = s.r.Check.Resume(ctx, checkId, next)
+		if err != nil {
+			return err
+		}
+
+		err = s.r.Flip.Create(ctx, entity.CreateFlip{
+			To:      entity.FlipResumed,
+			Date:    now,
 			CheckId: checkId,
 		})
 		if err != nil {
@@ -98,6 +225,20 @@ func (s *service) PauseCheck(ctx context.Context, checkId string, userId int) er
 	})
 }
 
+func (s *service) StopReminders(ctx context.Context, params entity.StopReminders) error {
+	return s.r.WithTx(ctx, func(ctx context.Context) error {
+		check, err := s.r.Check.Get(ctx, entity.GetCheck{Id: params.Id, UserId: params.UserId})
+		if err != nil {
+			return err
+		}
+		if check.Status != entity.CheckDown {
+			return errors.E(errors.Validation, "check is not down")
+		}
+
+		return s.r.Check.StopReminders(ctx, params)
+	})
+}
+
 func (s *service) GetPings(ctx context.Context, params entity.GetPings) ([]entity.Ping, int, error) {
 	var pings []entity.Ping
 	var total int
@@ -153,3 +294,231 @@ func (s *service) GetFlips(ctx context.Context, params entity.GetFlips) ([]entit
 
 	return flips, total, nil
 }
+
+func (s *service) GetRuns(ctx context.Context, params entity.GetRuns) ([]entity.Run, int, error) {
+	var runs []entity.Run
+	var total int
+	err := s.r.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		total, err = s.r.Ping.GetRunsTotal(ctx, entity.GetRunsTotal{
+			CheckId: params.CheckId,
+			From:    params.From,
+			To:      params.To,
+		})
+		if err != nil {
+			return err
+		}
+
+		runs, err = s.r.Ping.GetRuns(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return runs, total, nil
+}
+
+func (s *service) GetIncidents(ctx context.Context, params entity.GetIncidents) ([]entity.Incident, int, error) {
+	var incidents []entity.Incident
+	var total int
+	err := s.r.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		total, err = s.r.Incident.GetTotal(ctx, entity.GetIncidentsTotal{
+			CheckId: params.CheckId,
+			UserId:  params.UserId,
+			From:    params.From,
+			To:      params.To,
+		})
+		if err != nil {
+			return err
+		}
+
+		incidents, err = s.r.Incident.GetMany(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return incidents, total, nil
+}
+
+func (s *service) GetUptime(ctx context.Context, params entity.GetUptime) ([]entity.Uptime, error) {
+	buckets, err := uptimeBuckets(params.Bucket, params.From, params.To)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.r.Check.Get(ctx, entity.GetCheck{Id: params.CheckId, UserId: params.UserId})
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := s.r.Flip.GetChanges(ctx, entity.GetFlipChanges{
+		CheckIds: []string{params.CheckId},
+		From:     params.From,
+		To:       params.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+	uptime.Add(buckets, changes, time.Now())
+
+	return buckets, nil
+}
+
+// GetTagsUptime returns uptime of user's checks summed up by tag, tags are sorted by name
+func (s *service) GetTagsUptime(ctx context.Context, params entity.GetTagsUptime) ([]entity.TagUptime, error) {
+	_, err := uptimeBuckets(params.Bucket, params.From, params.To)
+	if err != nil {
+		return nil, err
+	}
+
+	checks, err := s.r.Check.GetMany(ctx, params.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	tagged := make(map[string][]string)
+	var checkIds []string
+	for _, check := range checks {
+		if len(check.Tags) == 0 {
+			continue
+		}
+		for _, tag := range check.Tags {
+			tagged[tag] = append(tagged[tag], check.Id)
+		}
+		checkIds = append(checkIds, check.Id)
+	}
+	if len(checkIds) == 0 {
+		return []entity.TagUptime{}, nil
+	}
+
+	changes, err := s.r.Flip.GetChanges(ctx, entity.GetFlipChanges{
+		CheckIds: checkIds,
+		From:     params.From,
+		To:       params.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+	checkChanges := make(map[string][]entity.FlipChange)
+	for _, change := range changes {
+		checkChanges[change.CheckId] = append(checkChanges[change.CheckId], change)
+	}
+
+	now := time.Now()
+	tags := make([]entity.TagUptime, 0, len(tagged))
+	for tag, ids := range tagged {
+		buckets := uptime.Buckets(params.Bucket, params.From, params.To)
+		for _, id := range ids {
+			uptime.Add(buckets, checkChanges[id], now)
+		}
+		tags = append(tags, entity.TagUptime{Tag: tag, Buckets: buckets})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Tag < tags[j].Tag
+	})
+
+	return tags, nil
+}
+
+func (s *service) GetDurations(
+	ctx context.Context,
+	params entity.GetDurations,
+) (entity.DurationStats, []entity.DurationStats, error) {
+	var stats entity.DurationStats
+	if !params.From.Before(params.To) {
+		return stats, nil, errors.E(errors.Validation, "from must be before to")
+	}
+
+	_, err := s.r.Check.Get(ctx, entity.GetCheck{Id: params.CheckId, UserId: params.UserId})
+	if err != nil {
+		return stats, nil, err
+	}
+
+	stats, err = s.r.Ping.GetDurationStats(ctx, entity.GetDurationStats{
+		CheckId: params.CheckId,
+		From:    params.From,
+		To:      params.To,
+	})
+	if err != nil {
+		return stats, nil, err
+	}
+
+	series, err := s.r.Ping.GetDurationSeries(ctx, params)
+	if err != nil {
+		return stats, nil, err
+	}
+
+	return stats, series, nil
+}
+
+func (s *service) GetMetricHistory(ctx context.Context, params entity.GetMetricHistory) ([]entity.MetricPoint, error) {
+	_, err := s.r.Check.Get(ctx, entity.GetCheck{Id: params.CheckId, UserId: params.UserId})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.r.Metric.GetHistory(ctx, params)
+}
+
+// checkPolicy returns error if escalation policy is set and doesn't belong to the user
+func (s *service) checkPolicy(ctx context.Context, policyId *int, userId int) error {
+	if policyId == nil {
+		return nil
+	}
+
+	_, err := s.r.Escalation.GetPolicy(ctx, entity.GetEscalationPolicy{Id: *policyId, UserId: userId})
+	return err
+}
+
+// uptimeBuckets splits the period into buckets and returns error if the period is empty or has too many buckets
+func uptimeBuckets(kind entity.UptimeBucket, from, to time.Time) ([]entity.Uptime, error) {
+	if !from.Before(to) {
+		return nil, errors.E(errors.Validation, "from must be before to")
+	}
+
+	buckets := uptime.Buckets(kind, from, to)
+	if len(buckets) > maxUptimeBuckets {
+		return nil, errors.E(errors.Validation, fmt.Sprintf("period must have at most %v buckets", maxUptimeBuckets))
+	}
+
+	return buckets, nil
+}
+
+// checkPingRules returns error if pattern of any regex body rule is not valid, or if any metric rule refers to
+// metric which the check doesn't have
+func checkPingRules(rules entity.PingRules) error {
+	for _, rule := range rules.Body {
+		if rule.Match != entity.BodyRuleRegex {
+			continue
+		}
+
+		_, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return errors.E(errors.Validation, fmt.Sprintf("invalid regex %q: %v", rule.Pattern, err))
+		}
+	}
+
+	names := make(map[string]bool, len(rules.Metrics))
+	for _, metric := range rules.Metrics {
+		names[metric.Name] = true
+	}
+	for _, rule := range rules.MetricRules {
+		if !names[rule.Metric] {
+			return errors.E(errors.Validation, fmt.Sprintf("metric %v is not defined", rule.Metric))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package escalation
+
+type EscalationPolicy struct {
+	Id    int              `json:"id" validate:"required"`
+	Name  string           `json:"name" validate:"required"`
+	Steps []EscalationStep `json:"steps" validate:"required"`
+}
+
+type EscalationStep struct {
+	Delay     int `json:"delay" validate:"min=0,max=604800"` // seconds after the check went down, max 7 days
+	ChannelId int `json:"channelId" validate:"required"`
+}
+
+type CreateEscalationPolicyBody struct {
+	Name  string           `json:"name" validate:"required,max=255"`
+	Steps []EscalationStep `json:"steps" validate:"required,min=1,max=10,dive"` // steps are notified in order
+}
+
+type CreateEscalationPolicyResponse struct {
+	Id int `json:"id" validate:"required"`
+}
+
+type UpdateEscalationPolicyBody struct {
+	CreateEscalationPolicyBody
+}
@@ -0,0 +1,160 @@
+package escalation
+
+import (
+	"github.com/go-chi/chi/v5"
+	"gitlab.com/grygoryz/uptime-checker/internal/entity"
+	"gitlab.com/grygoryz/uptime-checker/internal/session"
+	"gitlab.com/grygoryz/uptime-checker/internal/utility/request"
+	"gitlab.com/grygoryz/uptime-checker/internal/utility/respond"
+	"gitlab.com/grygoryz/uptime-checker/internal/validate"
+	"net/http"
+)
+
+type handler struct {
+	service   *service
+	validator *validate.Validator
+}
+
+func RegisterHandler(router *chi.Mux, service *service, validator *validate.Validator, sessionRepo *session.Repository) {
+	h := handler{service: service, validator: validator}
+
+	authMiddleware := session.Auth(sessionRepo)
+
+	router.Route("/v1/escalation-policies", func(router chi.Router) {
+		router.Use(authMiddleware)
+		router.Get("/", h.GetPolicies)
+		router.Post("/", h.CreatePolicy)
+		router.Put("/{id}", h.UpdatePolicy)
+		router.Delete("/{id}", h.DeletePolicy)
+	})
+}
+
+// GetPolicies returns escalation policies
+// @Tags Escalation
+// @Summary Get escalation policies
+// @Security cookieAuth
+// @Accept json
+// @Produce json
+// @Success 200 {array} EscalationPolicy
+// @router /v1/escalation-policies [get]
+func (h handler) GetPolicies(w http.ResponseWriter, r *http.Request) {
+	user := session.User(r.Context())
+	policies, err := h.service.GetPolicies(r.Context(), user.Id)
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	response := make([]EscalationPolicy, len(policies))
+	for i, policy := range policies {
+		steps := make([]EscalationStep, len(policy.Steps))
+		for j, step := range policy.Steps {
+			steps[j] = EscalationStep{Delay: step.Delay, ChannelId: step.ChannelId}
+		}
+		response[i] = EscalationPolicy{Id: policy.Id, Name: policy.Name, Steps: steps}
+	}
+	respond.JSON(r.Context(), w, http.StatusOK, response)
+}
+
+// CreatePolicy creates escalation policy
+// @Tags Escalation
+// @Summary Create escalation policy
+// @Description While check with the policy is down, channel of each step is notified when the step's delay
+// @Description passes. Steps which are still pending when the check is up again are cancelled
+// @Security cookieAuth
+// @Accept json
+// @Produce json
+// @Param policy body CreateEscalationPolicyBody true "escalation policy data"
+// @Success 201 {object} CreateEscalationPolicyResponse
+// @router /v1/escalation-policies [post]
+func (h handler) CreatePolicy(w http.ResponseWriter, r *http.Request) {
+	body, err := request.Body[CreateEscalationPolicyBody](r, h.validator)
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	user := session.User(r.Context())
+	id, err := h.service.CreatePolicy(r.Context(), entity.CreateEscalationPolicy{
+		UserId: user.Id,
+		Name:   body.Name,
+	}, steps(body.Steps))
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	respond.JSON(r.Context(), w, http.StatusCreated, CreateEscalationPolicyResponse{Id: id})
+}
+
+// UpdatePolicy updates escalation policy
+// @Tags Escalation
+// @Summary Update escalation policy
+// @Security cookieAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "escalation policy id"
+// @Param policy body UpdateEscalationPolicyBody true "escalation policy data"
+// @Success 200
+// @router /v1/escalation-policies/{id} [put]
+func (h handler) UpdatePolicy(w http.ResponseWriter, r *http.Request) {
+	body, err := request.Body[UpdateEscalationPolicyBody](r, h.validator)
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	id, err := request.IntParam(r, "id")
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	user := session.User(r.Context())
+	err = h.service.UpdatePolicy(r.Context(), entity.UpdateEscalationPolicy{
+		Id:     id,
+		UserId: user.Id,
+		Name:   body.Name,
+	}, steps(body.Steps))
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	respond.Status(w, http.StatusOK)
+}
+
+// DeletePolicy deletes escalation policy by id
+// @Tags Escalation
+// @Summary Delete escalation policy
+// @Description Checks with the policy notify their channels only
+// @Security cookieAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "escalation policy id"
+// @Success 200
+// @router /v1/escalation-policies/{id} [delete]
+func (h handler) DeletePolicy(w http.ResponseWriter, r *http.Request) {
+	id, err := request.IntParam(r, "id")
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	user := session.User(r.Context())
+	err = h.service.DeletePolicy(r.Context(), entity.DeleteEscalationPolicy{Id: id, UserId: user.Id})
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	respond.Status(w, http.StatusOK)
+}
+
+func steps(body []EscalationStep) []entity.EscalationStep {
+	steps := make([]entity.EscalationStep, len(body))
+	for i, step := range body {
+		steps[i] = entity.EscalationStep{Delay: step.Delay, ChannelId: step.ChannelId}
+	}
+	return steps
+}
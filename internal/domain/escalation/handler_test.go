@@ -0,0 +1,243 @@
+package escalation_test
+
+import (
+	"bytes"
+	"encoding/json"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"gitlab.com/grygoryz/uptime-checker/config"
+	"gitlab.com/grygoryz/uptime-checker/internal/domain/auth"
+	"gitlab.com/grygoryz/uptime-checker/internal/domain/channel"
+	"gitlab.com/grygoryz/uptime-checker/internal/domain/check"
+	"gitlab.com/grygoryz/uptime-checker/internal/domain/escalation"
+	"gitlab.com/grygoryz/uptime-checker/internal/server"
+	"gitlab.com/grygoryz/uptime-checker/internal/utility/test"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+var s *server.Server
+
+func TestMain(m *testing.M) {
+	cfg := config.New(true)
+	s = server.New(cfg)
+	s.Init()
+	m.Run()
+}
+
+// authorizeOther signs in another user, test.Authorize can be used once per test
+func authorizeOther(t *testing.T) string {
+	body, err := json.Marshal(auth.SignUpBody{Email: "other" + t.Name() + "@test.com", Password: "123123123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest("PUT", "/v1/auth/signup", bytes.NewReader(body))
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusCreated, response.Code)
+
+	req, _ = http.NewRequest("PUT", "/v1/auth/signin", bytes.NewReader(body))
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	return response.Header().Get("Set-Cookie")
+}
+
+func getChannelId(t *testing.T, cookie string) int {
+	req, _ := http.NewRequest("GET", "/v1/channels", nil)
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var channels []channel.GetChannelsResponseItem
+	err := json.Unmarshal(response.Body.Bytes(), &channels)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return channels[0].Id
+}
+
+func createPolicy(t *testing.T, cookie string, dto escalation.CreateEscalationPolicyBody) int {
+	body, err := json.Marshal(dto)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest("POST", "/v1/escalation-policies", bytes.NewReader(body))
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusCreated, response.Code)
+
+	var policy escalation.CreateEscalationPolicyResponse
+	err = json.Unmarshal(response.Body.Bytes(), &policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return policy.Id
+}
+
+func getPolicies(t *testing.T, cookie string) []escalation.EscalationPolicy {
+	req, _ := http.NewRequest("GET", "/v1/escalation-policies", nil)
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var policies []escalation.EscalationPolicy
+	err := json.Unmarshal(response.Body.Bytes(), &policies)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return policies
+}
+
+func TestHandler_CreatePolicy(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	channelId := getChannelId(t, cookie)
+
+	id := createPolicy(t, cookie, escalation.CreateEscalationPolicyBody{
+		Name: "owner then team",
+		Steps: []escalation.EscalationStep{
+			{Delay: 0, ChannelId: channelId},
+			{Delay: 900, ChannelId: channelId},
+		},
+	})
+
+	policies := getPolicies(t, cookie)
+	if len(policies) != 1 || policies[0].Id != id {
+		t.Fatalf("want 1 policy with id %v, got %+v", id, policies)
+	}
+	if len(policies[0].Steps) != 2 || policies[0].Steps[1].Delay != 900 {
+		t.Errorf("want 2 steps with the second delayed by 900 seconds, got %+v", policies[0].Steps)
+	}
+}
+
+func TestHandler_CreatePolicy_ForeignChannel(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	otherCookie := authorizeOther(t)
+	channelId := getChannelId(t, otherCookie)
+
+	body, err := json.Marshal(escalation.CreateEscalationPolicyBody{
+		Name:  "policy",
+		Steps: []escalation.EscalationStep{{Delay: 0, ChannelId: channelId}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest("POST", "/v1/escalation-policies", bytes.NewReader(body))
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusNotFound, response.Code)
+}
+
+func TestHandler_CreatePolicy_InvalidInput(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+
+	body, err := json.Marshal(escalation.CreateEscalationPolicyBody{Name: "without steps"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest("POST", "/v1/escalation-policies", bytes.NewReader(body))
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusBadRequest, response.Code)
+}
+
+func TestHandler_UpdatePolicy(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	channelId := getChannelId(t, cookie)
+	id := createPolicy(t, cookie, escalation.CreateEscalationPolicyBody{
+		Name:  "policy",
+		Steps: []escalation.EscalationStep{{Delay: 0, ChannelId: channelId}},
+	})
+
+	body, err := json.Marshal(escalation.UpdateEscalationPolicyBody{
+		CreateEscalationPolicyBody: escalation.CreateEscalationPolicyBody{
+			Name:  "updated",
+			Steps: []escalation.EscalationStep{{Delay: 600, ChannelId: channelId}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest("PUT", "/v1/escalation-policies/"+strconv.Itoa(id), bytes.NewReader(body))
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	policies := getPolicies(t, cookie)
+	if len(policies) != 1 || policies[0].Name != "updated" || policies[0].Steps[0].Delay != 600 {
+		t.Errorf("want updated policy with 1 step delayed by 600 seconds, got %+v", policies)
+	}
+}
+
+func TestHandler_DeletePolicy(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	channelId := getChannelId(t, cookie)
+	id := createPolicy(t, cookie, escalation.CreateEscalationPolicyBody{
+		Name:  "policy",
+		Steps: []escalation.EscalationStep{{Delay: 0, ChannelId: channelId}},
+	})
+
+	req, _ := http.NewRequest("DELETE", "/v1/escalation-policies/"+strconv.Itoa(id), nil)
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	if policies := getPolicies(t, cookie); len(policies) != 0 {
+		t.Errorf("want no policies, got %+v", policies)
+	}
+}
+
+func TestHandler_CreateCheck_EscalationPolicy(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	channelId := getChannelId(t, cookie)
+	id := createPolicy(t, cookie, escalation.CreateEscalationPolicyBody{
+		Name:  "policy",
+		Steps: []escalation.EscalationStep{{Delay: 0, ChannelId: channelId}},
+	})
+
+	body, err := json.Marshal(check.CreateCheckBody{
+		Name:               "testcheck",
+		Description:        "some description",
+		Interval:           60,
+		Grace:              3600,
+		EscalationPolicyId: &id,
+		Channels:           []int{channelId},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest("POST", "/v1/checks", bytes.NewReader(body))
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusCreated, response.Code)
+
+	var ch check.CreateCheckResponse
+	err = json.Unmarshal(response.Body.Bytes(), &ch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var policyId *int
+	err = s.DB().Get(&policyId, "SELECT escalation_policy_id FROM checks WHERE id = $1", ch.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policyId == nil || *policyId != id {
+		t.Errorf("want check escalation policy %v, got %v", id, policyId)
+	}
+
+	// another user's policy can't be set
+	otherCookie := authorizeOther(t)
+	req, _ = http.NewRequest("POST", "/v1/checks", bytes.NewReader(body))
+	req.Header.Set("Cookie", otherCookie)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusNotFound, response.Code)
+}
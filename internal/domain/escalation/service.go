@@ -0,0 +1,65 @@
+package escalation
+
+import (
+	"context"
+	"gitlab.com/grygoryz/uptime-checker/internal/entity"
+	"gitlab.com/grygoryz/uptime-checker/internal/repository"
+)
+
+type service struct {
+	r *repository.Registry
+}
+
+func NewService(repositoryRegistry *repository.Registry) *service {
+	return &service{r: repositoryRegistry}
+}
+
+func (s *service) GetPolicies(ctx context.Context, userId int) ([]entity.EscalationPolicy, error) {
+	return s.r.Escalation.GetPolicies(ctx, userId)
+}
+
+func (s *service) CreatePolicy(
+	ctx context.Context,
+	policy entity.CreateEscalationPolicy,
+	steps []entity.EscalationStep,
+) (int, error) {
+	var id int
+	err := s.r.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		id, err = s.r.Escalation.CreatePolicy(ctx, policy)
+		if err != nil {
+			return err
+		}
+
+		return s.r.Escalation.SetSteps(ctx, entity.SetEscalationSteps{
+			Id:     id,
+			UserId: policy.UserId,
+			Steps:  steps,
+		})
+	})
+
+	return id, err
+}
+
+func (s *service) UpdatePolicy(
+	ctx context.Context,
+	policy entity.UpdateEscalationPolicy,
+	steps []entity.EscalationStep,
+) error {
+	return s.r.WithTx(ctx, func(ctx context.Context) error {
+		err := s.r.Escalation.UpdatePolicy(ctx, policy)
+		if err != nil {
+			return err
+		}
+
+		return s.r.Escalation.SetSteps(ctx, entity.SetEscalationSteps{
+			Id:     policy.Id,
+			UserId: policy.UserId,
+			Steps:  steps,
+		})
+	})
+}
+
+func (s *service) DeletePolicy(ctx context.Context, policy entity.DeleteEscalationPolicy) error {
+	return s.r.Escalation.DeletePolicy(ctx, policy)
+}
@@ -0,0 +1,48 @@
+package incident
+
+import (
+	"gitlab.com/grygoryz/uptime-checker/internal/entity"
+	"time"
+)
+
+type Incident struct {
+	Id             int               `json:"id" validate:"required"`
+	CheckId        string            `json:"checkId" validate:"required"`
+	CheckName      string            `json:"checkName" validate:"required"`
+	Cause          entity.FlipReason `json:"cause,omitempty"`
+	StartedAt      time.Time         `json:"startedAt" validate:"required"`
+	EndedAt        *time.Time        `json:"endedAt,omitempty"`
+	Duration       *int              `json:"duration,omitempty"` // seconds, set when incident has ended
+	AcknowledgedAt *time.Time        `json:"acknowledgedAt,omitempty"`
+	AcknowledgedBy *string           `json:"acknowledgedBy,omitempty"` // email of the user or link recipient
+	Notes          []IncidentNote    `json:"notes" validate:"required"`
+}
+
+type IncidentNote struct {
+	Text      string    `json:"text" validate:"required"`
+	Author    string    `json:"author" validate:"required"`
+	CreatedAt time.Time `json:"createdAt" validate:"required"`
+}
+
+type GetIncidentsQuery struct {
+	From   int  `json:"from" validate:"required"`
+	To     int  `json:"to" validate:"required"`
+	Limit  int  `json:"limit" validate:"required,min=1,max=50"`
+	Offset *int `json:"offset" validate:"required"`
+}
+
+type GetIncidentsResponse struct {
+	Total int        `json:"total" validate:"required"`
+	Items []Incident `json:"items" validate:"required"`
+}
+
+type AddNoteBody struct {
+	Text string `json:"text" validate:"required,max=2000"`
+}
+
+// LinkQuery identifies recipient of the notification email with the link
+type LinkQuery struct {
+	Email     string `json:"email" validate:"required,email"`
+	Expires   int    `json:"expires" validate:"required"` // unix seconds, the link is not valid after that
+	Signature string `json:"signature" validate:"required,hexadecimal"`
+}
@@ -0,0 +1,330 @@
+package incident
+
+import (
+	"github.com/go-chi/chi/v5"
+	"gitlab.com/grygoryz/uptime-checker/internal/entity"
+	"gitlab.com/grygoryz/uptime-checker/internal/session"
+	"gitlab.com/grygoryz/uptime-checker/internal/utility/request"
+	"gitlab.com/grygoryz/uptime-checker/internal/utility/respond"
+	"gitlab.com/grygoryz/uptime-checker/internal/validate"
+	"html/template"
+	"net/http"
+	"time"
+)
+
+type handler struct {
+	service   *service
+	validator *validate.Validator
+}
+
+func RegisterHandler(router *chi.Mux, service *service, validator *validate.Validator, sessionRepo *session.Repository) {
+	h := handler{service: service, validator: validator}
+
+	authMiddleware := session.Auth(sessionRepo)
+
+	router.Route("/v1/incidents", func(router chi.Router) {
+		router.Group(func(router chi.Router) {
+			router.Use(authMiddleware)
+			router.Get("/", h.GetIncidents)
+			router.Put("/{id}/acknowledge", h.Acknowledge)
+			router.Post("/{id}/notes", h.AddNote)
+		})
+		// links from notification emails are signed instead of being authorized
+		router.Get("/{id}/link/acknowledge", h.LinkAcknowledgeConfirm)
+		router.Post("/{id}/link/acknowledge", h.LinkAcknowledge)
+		router.Post("/{id}/link/notes", h.LinkAddNote)
+	})
+}
+
+// GetIncidents returns incidents of all user's checks
+// @Tags Incidents
+// @Summary Get incidents
+// @Description Incident starts when check goes down and ends when it goes up
+// @Security cookieAuth
+// @Accept json
+// @Produce json
+// @Param params query GetIncidentsQuery true "params"
+// @Success 200 {object} GetIncidentsResponse
+// @router /v1/incidents [get]
+func (h handler) GetIncidents(w http.ResponseWriter, r *http.Request) {
+	limit, err := request.IntQueryParam(r, "limit")
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+	offset, err := request.IntQueryParam(r, "offset")
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+	from, err := request.IntQueryParam(r, "from")
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+	to, err := request.IntQueryParam(r, "to")
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	err = h.validator.Struct(GetIncidentsQuery{
+		From:   from,
+		To:     to,
+		Limit:  limit,
+		Offset: &offset,
+	})
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	user := session.User(r.Context())
+	incidents, total, err := h.service.GetIncidents(r.Context(), entity.GetIncidents{
+		UserId: user.Id,
+		From:   time.UnixMilli(int64(from)),
+		To:     time.UnixMilli(int64(to)),
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	items := make([]Incident, len(incidents))
+	for i, incident := range incidents {
+		items[i] = incidentDTO(incident)
+	}
+
+	respond.JSON(r.Context(), w, http.StatusOK, GetIncidentsResponse{
+		Total: total,
+		Items: items,
+	})
+}
+
+// Acknowledge acknowledges incident
+// @Tags Incidents
+// @Summary Acknowledge incident
+// @Security cookieAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "incident id"
+// @Success 200
+// @router /v1/incidents/{id}/acknowledge [put]
+func (h handler) Acknowledge(w http.ResponseWriter, r *http.Request) {
+	id, err := request.IntParam(r, "id")
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	user := session.User(r.Context())
+	err = h.service.Acknowledge(r.Context(), entity.AcknowledgeIncident{Id: id, UserId: user.Id, By: user.Email})
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	respond.Status(w, http.StatusOK)
+}
+
+// AddNote adds note to incident
+// @Tags Incidents
+// @Summary Add incident note
+// @Security cookieAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "incident id"
+// @Param note body AddNoteBody true "note"
+// @Success 201
+// @router /v1/incidents/{id}/notes [post]
+func (h handler) AddNote(w http.ResponseWriter, r *http.Request) {
+	body, err := request.Body[AddNoteBody](r, h.validator)
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	id, err := request.IntParam(r, "id")
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	user := session.User(r.Context())
+	err = h.service.AddNote(r.Context(), entity.AddIncidentNote{
+		Id:     id,
+		UserId: user.Id,
+		Text:   body.Text,
+		Author: user.Email,
+	})
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	respond.Status(w, http.StatusCreated)
+}
+
+// acknowledgeConfirmPage lets the recipient of the link confirm acknowledgement, so the incident isn't acknowledged
+// by merely opening the link, e.g. by mail scanners
+var acknowledgeConfirmPage = template.Must(template.New("acknowledge").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Acknowledge incident</title></head>
+<body>
+<form method="post" action="{{.Action}}">
+<p>Acknowledge the incident of check {{.CheckName}} as {{.Email}}?</p>
+<button type="submit">Acknowledge</button>
+</form>
+</body>
+</html>
+`))
+
+// LinkAcknowledgeConfirm returns page which confirms acknowledgement of incident by signed link from notification email
+// @Tags Incidents
+// @Summary Confirm acknowledgement of incident by link
+// @Produce html
+// @Param id path int true "incident id"
+// @Param params query LinkQuery true "params"
+// @Success 200
+// @router /v1/incidents/{id}/link/acknowledge [get]
+func (h handler) LinkAcknowledgeConfirm(w http.ResponseWriter, r *http.Request) {
+	id, email, err := h.link(r)
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	incident, err := h.service.GetByLink(r.Context(), id)
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	respond.HTML(r.Context(), w, http.StatusOK, acknowledgeConfirmPage, map[string]string{
+		"Action":    r.URL.RequestURI(),
+		"CheckName": incident.CheckName,
+		"Email":     email,
+	})
+}
+
+// LinkAcknowledge acknowledges incident by signed link from notification email
+// @Tags Incidents
+// @Summary Acknowledge incident by link
+// @Accept json
+// @Produce json
+// @Param id path int true "incident id"
+// @Param params query LinkQuery true "params"
+// @Success 200
+// @router /v1/incidents/{id}/link/acknowledge [post]
+func (h handler) LinkAcknowledge(w http.ResponseWriter, r *http.Request) {
+	id, email, err := h.link(r)
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	err = h.service.Acknowledge(r.Context(), entity.AcknowledgeIncident{Id: id, By: email})
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	respond.Status(w, http.StatusOK)
+}
+
+// LinkAddNote adds note to incident by signed link from notification email
+// @Tags Incidents
+// @Summary Add incident note by link
+// @Accept json
+// @Produce json
+// @Param id path int true "incident id"
+// @Param params query LinkQuery true "params"
+// @Param note body AddNoteBody true "note"
+// @Success 201
+// @router /v1/incidents/{id}/link/notes [post]
+func (h handler) LinkAddNote(w http.ResponseWriter, r *http.Request) {
+	body, err := request.Body[AddNoteBody](r, h.validator)
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	id, email, err := h.link(r)
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	err = h.service.AddNote(r.Context(), entity.AddIncidentNote{Id: id, Text: body.Text, Author: email})
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	respond.Status(w, http.StatusCreated)
+}
+
+// link returns incident id and email of the recipient of the signed link
+func (h handler) link(r *http.Request) (int, string, error) {
+	id, err := request.IntParam(r, "id")
+	if err != nil {
+		return 0, "", err
+	}
+
+	expires, err := request.IntQueryParam(r, "expires")
+	if err != nil {
+		return 0, "", err
+	}
+
+	query := LinkQuery{
+		Email:     r.URL.Query().Get("email"),
+		Expires:   expires,
+		Signature: r.URL.Query().Get("signature"),
+	}
+	err = h.validator.Struct(query)
+	if err != nil {
+		return 0, "", err
+	}
+
+	err = h.service.VerifyLink(id, query.Email, query.Expires, query.Signature)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return id, query.Email, nil
+}
+
+// incidentDTO transforms entity.Incident to Incident
+func incidentDTO(incident entity.Incident) Incident {
+	response := Incident{
+		Id:             incident.Id,
+		CheckId:        incident.CheckId,
+		CheckName:      incident.CheckName,
+		Cause:          incident.Cause,
+		StartedAt:      incident.StartedAt.UTC(),
+		EndedAt:        utc(incident.EndedAt),
+		Duration:       incident.Duration,
+		AcknowledgedAt: utc(incident.AcknowledgedAt),
+		AcknowledgedBy: incident.AcknowledgedBy,
+		Notes:          make([]IncidentNote, len(incident.Notes)),
+	}
+	for i, note := range incident.Notes {
+		response.Notes[i] = IncidentNote{
+			Text:      note.Text,
+			Author:    note.Author,
+			CreatedAt: note.CreatedAt.UTC(),
+		}
+	}
+
+	return response
+}
+
+func utc(time *time.Time) *time.Time {
+	if time == nil {
+		return nil
+	}
+	val := time.UTC()
+	return &val
+}
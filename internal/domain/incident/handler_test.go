@@ -0,0 +1,220 @@
+package incident_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"gitlab.com/grygoryz/uptime-checker/config"
+	"gitlab.com/grygoryz/uptime-checker/internal/domain/channel"
+	"gitlab.com/grygoryz/uptime-checker/internal/domain/check"
+	"gitlab.com/grygoryz/uptime-checker/internal/domain/incident"
+	"gitlab.com/grygoryz/uptime-checker/internal/entity"
+	"gitlab.com/grygoryz/uptime-checker/internal/server"
+	"gitlab.com/grygoryz/uptime-checker/internal/utility/signature"
+	"gitlab.com/grygoryz/uptime-checker/internal/utility/test"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+var s *server.Server
+var cfg config.Config
+
+func TestMain(m *testing.M) {
+	cfg = config.New(true)
+	s = server.New(cfg)
+	s.Init()
+	m.Run()
+}
+
+// createDownCheck creates check and fails it, so it has open incident
+func createDownCheck(t *testing.T, cookie string) string {
+	req, _ := http.NewRequest("GET", "/v1/channels", nil)
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var channels []channel.GetChannelsResponseItem
+	err := json.Unmarshal(response.Body.Bytes(), &channels)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := json.Marshal(check.CreateCheckBody{
+		Name:        "testcheck",
+		Description: "some description",
+		Interval:    60,
+		Grace:       3600,
+		Channels:    []int{channels[0].Id},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ = http.NewRequest("POST", "/v1/checks", bytes.NewReader(body))
+	req.Header.Set("Cookie", cookie)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusCreated, response.Code)
+
+	var ch check.CreateCheckResponse
+	err = json.Unmarshal(response.Body.Bytes(), &ch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ = http.NewRequest("PUT", "/v1/pings/"+ch.Id+"/fail", nil)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	return ch.Id
+}
+
+func getIncidents(t *testing.T, cookie string) []incident.Incident {
+	url := fmt.Sprintf(
+		"/v1/incidents?from=%v&to=%v&limit=50&offset=0",
+		time.Now().Add(-time.Hour).UnixMilli(),
+		time.Now().Add(time.Hour).UnixMilli(),
+	)
+	req, _ := http.NewRequest("GET", url, nil)
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var incidents incident.GetIncidentsResponse
+	err := json.Unmarshal(response.Body.Bytes(), &incidents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if incidents.Total != len(incidents.Items) {
+		t.Errorf("want total %v, got %v", len(incidents.Items), incidents.Total)
+	}
+
+	return incidents.Items
+}
+
+func TestHandler_GetIncidents(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	checkId := createDownCheck(t, cookie)
+
+	incidents := getIncidents(t, cookie)
+	if len(incidents) != 1 || incidents[0].EndedAt != nil || incidents[0].Cause != entity.FlipFail {
+		t.Fatalf("want 1 open incident caused by fail, got %+v", incidents)
+	}
+
+	req, _ := http.NewRequest("PUT", "/v1/pings/"+checkId, nil)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	incidents = getIncidents(t, cookie)
+	if len(incidents) != 1 || incidents[0].EndedAt == nil || incidents[0].Duration == nil {
+		t.Fatalf("want 1 ended incident with duration, got %+v", incidents)
+	}
+	if incidents[0].CheckId != checkId {
+		t.Errorf("want incident of check %v, got %v", checkId, incidents[0].CheckId)
+	}
+
+	url := fmt.Sprintf(
+		"/v1/checks/%v/incidents?from=%v&to=%v&limit=50&offset=0",
+		checkId,
+		time.Now().Add(-time.Hour).UnixMilli(),
+		time.Now().Add(time.Hour).UnixMilli(),
+	)
+	req, _ = http.NewRequest("GET", url, nil)
+	req.Header.Set("Cookie", cookie)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var checkIncidents check.GetIncidentsResponse
+	err := json.Unmarshal(response.Body.Bytes(), &checkIncidents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkIncidents.Total != 1 || checkIncidents.Items[0].Id != incidents[0].Id {
+		t.Errorf("want check incident %v, got %+v", incidents[0].Id, checkIncidents)
+	}
+}
+
+func TestHandler_Acknowledge(t *testing.T) {
+	cookie, user := test.Authorize(t, s)
+	createDownCheck(t, cookie)
+	id := getIncidents(t, cookie)[0].Id
+
+	req, _ := http.NewRequest("PUT", "/v1/incidents/"+strconv.Itoa(id)+"/acknowledge", nil)
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	req, _ = http.NewRequest("PUT", "/v1/incidents/"+strconv.Itoa(id)+"/acknowledge", nil)
+	req.Header.Set("Cookie", cookie)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusBadRequest, response.Code)
+
+	found := getIncidents(t, cookie)[0]
+	if found.AcknowledgedAt == nil || found.AcknowledgedBy == nil || *found.AcknowledgedBy != user.Email {
+		t.Errorf("want incident to be acknowledged by %v, got %v", user.Email, found.AcknowledgedBy)
+	}
+}
+
+func TestHandler_AddNote(t *testing.T) {
+	cookie, user := test.Authorize(t, s)
+	createDownCheck(t, cookie)
+	id := getIncidents(t, cookie)[0].Id
+
+	body, err := json.Marshal(incident.AddNoteBody{Text: "restarting the database"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest("POST", "/v1/incidents/"+strconv.Itoa(id)+"/notes", bytes.NewReader(body))
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusCreated, response.Code)
+
+	notes := getIncidents(t, cookie)[0].Notes
+	if len(notes) != 1 || notes[0].Text != "restarting the database" || notes[0].Author != user.Email {
+		t.Errorf("want 1 note by %v, got %+v", user.Email, notes)
+	}
+}
+
+func TestHandler_LinkAcknowledge(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	createDownCheck(t, cookie)
+	id := getIncidents(t, cookie)[0].Id
+	email := "oncall@test.com"
+
+	link := func(email string, expires int64, signedEmail string) string {
+		query := url.Values{}
+		query.Set("email", email)
+		query.Set("expires", strconv.FormatInt(expires, 10))
+		query.Set("signature", signature.SignExpiring(cfg.Links.Secret, expires, strconv.Itoa(id), signedEmail))
+		return fmt.Sprintf("/v1/incidents/%v/link/acknowledge?%v", id, query.Encode())
+	}
+	expires := time.Now().Add(time.Hour).Unix()
+
+	req, _ := http.NewRequest("POST", link(email, expires, "another@test.com"), nil)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusForbidden, response.Code)
+
+	req, _ = http.NewRequest("POST", link(email, time.Now().Add(-time.Hour).Unix(), email), nil)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusForbidden, response.Code)
+
+	req, _ = http.NewRequest("GET", link(email, expires, email), nil)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+	if found := getIncidents(t, cookie)[0]; found.AcknowledgedAt != nil {
+		t.Errorf("want incident not to be acknowledged by opening the link, got acknowledged at %v", found.AcknowledgedAt)
+	}
+
+	req, _ = http.NewRequest("POST", link(email, expires, email), nil)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	found := getIncidents(t, cookie)[0]
+	if found.AcknowledgedBy == nil || *found.AcknowledgedBy != email {
+		t.Errorf("want incident to be acknowledged by %v, got %v", email, found.AcknowledgedBy)
+	}
+}
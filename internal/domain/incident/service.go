@@ -0,0 +1,99 @@
+package incident
+
+import (
+	"context"
+	"gitlab.com/grygoryz/uptime-checker/internal/entity"
+	"gitlab.com/grygoryz/uptime-checker/internal/repository"
+	"gitlab.com/grygoryz/uptime-checker/internal/utility/errors"
+	"gitlab.com/grygoryz/uptime-checker/internal/utility/signature"
+	"strconv"
+)
+
+type service struct {
+	r          *repository.Registry
+	linkSecret string
+}
+
+func NewService(repositoryRegistry *repository.Registry, linkSecret string) *service {
+	return &service{r: repositoryRegistry, linkSecret: linkSecret}
+}
+
+func (s *service) GetIncidents(ctx context.Context, params entity.GetIncidents) ([]entity.Incident, int, error) {
+	var incidents []entity.Incident
+	var total int
+	err := s.r.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		total, err = s.r.Incident.GetTotal(ctx, entity.GetIncidentsTotal{
+			UserId: params.UserId,
+			From:   params.From,
+			To:     params.To,
+		})
+		if err != nil {
+			return err
+		}
+
+		incidents, err = s.r.Incident.GetMany(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return incidents, total, nil
+}
+
+func (s *service) Acknowledge(ctx context.Context, params entity.AcknowledgeIncident) error {
+	return s.r.WithTx(ctx, func(ctx context.Context) error {
+		incident, err := s.get(ctx, params.Id, params.UserId)
+		if err != nil {
+			return err
+		}
+		if incident.AcknowledgedAt != nil {
+			return errors.E(errors.Validation, "incident is acknowledged already")
+		}
+
+		return s.r.Incident.Acknowledge(ctx, params)
+	})
+}
+
+func (s *service) AddNote(ctx context.Context, params entity.AddIncidentNote) error {
+	return s.r.WithTx(ctx, func(ctx context.Context) error {
+		_, err := s.get(ctx, params.Id, params.UserId)
+		if err != nil {
+			return err
+		}
+
+		return s.r.Incident.AddNote(ctx, params)
+	})
+}
+
+// GetByLink returns incident accessed by signed link, the link must be verified by VerifyLink
+func (s *service) GetByLink(ctx context.Context, id int) (entity.Incident, error) {
+	return s.get(ctx, id, 0)
+}
+
+// VerifyLink returns error if the link to the incident is not signed for the email or has expired
+func (s *service) VerifyLink(incidentId int, email string, expires int, sig string) error {
+	if !signature.VerifyExpiring(s.linkSecret, sig, int64(expires), strconv.Itoa(incidentId), email) {
+		return errors.E(errors.Forbidden, "invalid or expired link")
+	}
+	return nil
+}
+
+// get returns incident by id. Incident must belong to the user, unless user id is zero, which means that
+// the incident is accessed by signed link
+func (s *service) get(ctx context.Context, id int, userId int) (entity.Incident, error) {
+	incident, err := s.r.Incident.Get(ctx, id)
+	if err != nil {
+		return incident, err
+	}
+	if userId != 0 && incident.UserId != userId {
+		return incident, errors.E(errors.NotExist, "incident not found")
+	}
+
+	return incident, nil
+}
@@ -0,0 +1,34 @@
+package maintenance
+
+import "time"
+
+type MaintenanceWindow struct {
+	Id        int        `json:"id" validate:"required"`
+	Name      string     `json:"name" validate:"required"`
+	StartsAt  *time.Time `json:"startsAt,omitempty"`
+	Cron      string     `json:"cron,omitempty"`
+	Timezone  string     `json:"timezone" validate:"required"`
+	Duration  int        `json:"duration" validate:"required"`
+	Tags      []string   `json:"tags" validate:"required"`
+	Checks    []string   `json:"checks" validate:"required"`
+	NextStart *time.Time `json:"nextStart,omitempty"` // start of the current or the upcoming period
+	NextEnd   *time.Time `json:"nextEnd,omitempty"`
+}
+
+type CreateMaintenanceWindowBody struct {
+	Name     string   `json:"name" validate:"required,max=255"`
+	StartsAt int      `json:"startsAt" validate:"required_without=Cron"`                                 // unix time in milliseconds, start of one-off window
+	Cron     string   `json:"cron" validate:"omitempty,max=100,cron"`                                    // recurring window starts at every run
+	Timezone string   `json:"timezone" validate:"omitempty,timezone"`                                    // UTC by default
+	Duration int      `json:"duration" validate:"required,min=60,max=2592000"`                           // min 1 minute, max 30 days
+	Tags     []string `json:"tags" validate:"required_without=Checks,omitempty,max=10,dive,max=50,slug"` // checks with any of the tags
+	Checks   []string `json:"checks" validate:"required_without=Tags,omitempty,unique,dive,uuid4"`
+}
+
+type CreateMaintenanceWindowResponse struct {
+	Id int `json:"id" validate:"required"`
+}
+
+type UpdateMaintenanceWindowBody struct {
+	CreateMaintenanceWindowBody
+}
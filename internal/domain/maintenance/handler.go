@@ -0,0 +1,200 @@
+package maintenance
+
+import (
+	"github.com/go-chi/chi/v5"
+	"gitlab.com/grygoryz/uptime-checker/internal/entity"
+	"gitlab.com/grygoryz/uptime-checker/internal/session"
+	"gitlab.com/grygoryz/uptime-checker/internal/utility/request"
+	"gitlab.com/grygoryz/uptime-checker/internal/utility/respond"
+	"gitlab.com/grygoryz/uptime-checker/internal/validate"
+	"net/http"
+	"time"
+)
+
+type handler struct {
+	service   *service
+	validator *validate.Validator
+}
+
+func RegisterHandler(router *chi.Mux, service *service, validator *validate.Validator, sessionRepo *session.Repository) {
+	h := handler{service: service, validator: validator}
+
+	authMiddleware := session.Auth(sessionRepo)
+
+	router.Route("/v1/maintenance", func(router chi.Router) {
+		router.Use(authMiddleware)
+		router.Get("/", h.GetWindows)
+		router.Post("/", h.CreateWindow)
+		router.Put("/{id}", h.UpdateWindow)
+		router.Delete("/{id}", h.DeleteWindow)
+	})
+}
+
+// GetWindows returns maintenance windows
+// @Tags Maintenance
+// @Summary Get maintenance windows
+// @Security cookieAuth
+// @Accept json
+// @Produce json
+// @Success 200 {array} MaintenanceWindow
+// @router /v1/maintenance [get]
+func (h handler) GetWindows(w http.ResponseWriter, r *http.Request) {
+	user := session.User(r.Context())
+	windows, err := h.service.GetWindows(r.Context(), user.Id)
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	response := make([]MaintenanceWindow, len(windows))
+	for i, window := range windows {
+		response[i] = MaintenanceWindow{
+			Id:        window.Id,
+			Name:      window.Name,
+			StartsAt:  utc(window.StartsAt),
+			Cron:      window.Cron,
+			Timezone:  window.Timezone,
+			Duration:  window.Duration,
+			Tags:      emptyIfNil(window.Tags),
+			Checks:    emptyIfNil(window.Checks),
+			NextStart: utc(window.NextStart),
+			NextEnd:   utc(window.NextEnd),
+		}
+	}
+	respond.JSON(r.Context(), w, http.StatusOK, response)
+}
+
+// CreateWindow creates maintenance window
+// @Tags Maintenance
+// @Summary Create maintenance window
+// @Description Notifications of the checks and checks with any of the tags are suppressed during the window
+// @Security cookieAuth
+// @Accept json
+// @Produce json
+// @Param window body CreateMaintenanceWindowBody true "maintenance window data"
+// @Success 201 {object} CreateMaintenanceWindowResponse
+// @router /v1/maintenance [post]
+func (h handler) CreateWindow(w http.ResponseWriter, r *http.Request) {
+	body, err := request.Body[CreateMaintenanceWindowBody](r, h.validator)
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	user := session.User(r.Context())
+	startsAt, cron, timezone := windowSchedule(body)
+	id, err := h.service.CreateWindow(r.Context(), entity.CreateMaintenanceWindow{
+		UserId:   user.Id,
+		Name:     body.Name,
+		StartsAt: startsAt,
+		Cron:     cron,
+		Timezone: timezone,
+		Duration: body.Duration,
+		Tags:     body.Tags,
+	}, body.Checks)
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	respond.JSON(r.Context(), w, http.StatusCreated, CreateMaintenanceWindowResponse{Id: id})
+}
+
+// UpdateWindow updates maintenance window
+// @Tags Maintenance
+// @Summary Update maintenance window
+// @Security cookieAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "maintenance window id"
+// @Param window body UpdateMaintenanceWindowBody true "maintenance window data"
+// @Success 200
+// @router /v1/maintenance/{id} [put]
+func (h handler) UpdateWindow(w http.ResponseWriter, r *http.Request) {
+	body, err := request.Body[UpdateMaintenanceWindowBody](r, h.validator)
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	id, err := request.IntParam(r, "id")
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	user := session.User(r.Context())
+	startsAt, cron, timezone := windowSchedule(body.CreateMaintenanceWindowBody)
+	err = h.service.UpdateWindow(r.Context(), entity.UpdateMaintenanceWindow{
+		Id:       id,
+		UserId:   user.Id,
+		Name:     body.Name,
+		StartsAt: startsAt,
+		Cron:     cron,
+		Timezone: timezone,
+		Duration: body.Duration,
+		Tags:     body.Tags,
+	}, body.Checks)
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	respond.Status(w, http.StatusOK)
+}
+
+// DeleteWindow deletes maintenance window by id
+// @Tags Maintenance
+// @Summary Delete maintenance window
+// @Security cookieAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "maintenance window id"
+// @Success 200
+// @router /v1/maintenance/{id} [delete]
+func (h handler) DeleteWindow(w http.ResponseWriter, r *http.Request) {
+	id, err := request.IntParam(r, "id")
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	user := session.User(r.Context())
+	err = h.service.DeleteWindow(r.Context(), entity.DeleteMaintenanceWindow{Id: id, UserId: user.Id})
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	respond.Status(w, http.StatusOK)
+}
+
+// windowSchedule returns start of one-off window or cron expression and time zone of recurring one from
+// the request body
+func windowSchedule(body CreateMaintenanceWindowBody) (*time.Time, string, string) {
+	timezone := body.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	if body.Cron != "" {
+		return nil, body.Cron, timezone
+	}
+
+	startsAt := time.UnixMilli(int64(body.StartsAt))
+	return &startsAt, "", timezone
+}
+
+func emptyIfNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+func utc(time *time.Time) *time.Time {
+	if time == nil {
+		return nil
+	}
+	val := time.UTC()
+	return &val
+}
@@ -0,0 +1,275 @@
+package maintenance_test
+
+import (
+	"bytes"
+	"encoding/json"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"gitlab.com/grygoryz/uptime-checker/config"
+	"gitlab.com/grygoryz/uptime-checker/internal/domain/channel"
+	"gitlab.com/grygoryz/uptime-checker/internal/domain/check"
+	"gitlab.com/grygoryz/uptime-checker/internal/domain/maintenance"
+	"gitlab.com/grygoryz/uptime-checker/internal/server"
+	"gitlab.com/grygoryz/uptime-checker/internal/utility/test"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+var s *server.Server
+
+func TestMain(m *testing.M) {
+	cfg := config.New(true)
+	s = server.New(cfg)
+	s.Init()
+	m.Run()
+}
+
+func createCheck(t *testing.T, cookie string, tags []string) string {
+	req, _ := http.NewRequest("GET", "/v1/channels", nil)
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var channels []channel.GetChannelsResponseItem
+	err := json.Unmarshal(response.Body.Bytes(), &channels)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := json.Marshal(check.CreateCheckBody{
+		Name:        "testcheck",
+		Description: "some description",
+		Tags:        tags,
+		Interval:    60,
+		Grace:       3600,
+		Channels:    []int{channels[0].Id},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ = http.NewRequest("POST", "/v1/checks", bytes.NewReader(body))
+	req.Header.Set("Cookie", cookie)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusCreated, response.Code)
+
+	var ch check.CreateCheckResponse
+	err = json.Unmarshal(response.Body.Bytes(), &ch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ch.Id
+}
+
+func createWindow(
+	t *testing.T,
+	cookie string,
+	dto maintenance.CreateMaintenanceWindowBody,
+) maintenance.CreateMaintenanceWindowResponse {
+	body, err := json.Marshal(dto)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest("POST", "/v1/maintenance", bytes.NewReader(body))
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusCreated, response.Code)
+
+	var window maintenance.CreateMaintenanceWindowResponse
+	err = json.Unmarshal(response.Body.Bytes(), &window)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return window
+}
+
+func getWindows(t *testing.T, cookie string) []maintenance.MaintenanceWindow {
+	req, _ := http.NewRequest("GET", "/v1/maintenance", nil)
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var windows []maintenance.MaintenanceWindow
+	err := json.Unmarshal(response.Body.Bytes(), &windows)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return windows
+}
+
+func TestHandler_CreateWindow_OneOff(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	checkId := createCheck(t, cookie, []string{"db"})
+
+	startsAt := time.Now().Add(-time.Minute)
+	window := createWindow(t, cookie, maintenance.CreateMaintenanceWindowBody{
+		Name:     "db maintenance",
+		StartsAt: int(startsAt.UnixMilli()),
+		Duration: 3600,
+		Tags:     []string{"db"},
+	})
+
+	windows := getWindows(t, cookie)
+	if len(windows) != 1 || windows[0].Id != window.Id {
+		t.Fatalf("want 1 window with id %v, got %+v", window.Id, windows)
+	}
+	if windows[0].NextStart == nil || windows[0].NextStart.UnixMilli() != startsAt.UnixMilli() {
+		t.Errorf("want window NextStart to be %v, got %v", startsAt, windows[0].NextStart)
+	}
+
+	// flips of the check with window's tag are suppressed
+	req, _ := http.NewRequest("PUT", "/v1/pings/"+checkId+"/fail", nil)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var suppressed bool
+	err := s.DB().Get(&suppressed, "SELECT suppressed FROM flips WHERE check_id = $1", checkId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !suppressed {
+		t.Error("want flip of the check in maintenance to be suppressed")
+	}
+}
+
+func TestHandler_CreateWindow_Recurring(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	checkId := createCheck(t, cookie, nil)
+
+	createWindow(t, cookie, maintenance.CreateMaintenanceWindowBody{
+		Name:     "nightly backups",
+		Cron:     "0 3 * * *",
+		Timezone: "Europe/Berlin",
+		Duration: 1800,
+		Checks:   []string{checkId},
+	})
+
+	windows := getWindows(t, cookie)
+	if len(windows) != 1 {
+		t.Fatalf("want 1 window, got %v", len(windows))
+	}
+	w := windows[0]
+	if w.StartsAt != nil || w.NextStart == nil || !w.NextStart.After(time.Now()) {
+		t.Errorf("want recurring window to start in the future, got %+v", w)
+	}
+	if w.NextEnd == nil || w.NextEnd.Sub(*w.NextStart) != time.Second*1800 {
+		t.Errorf("want window period to last 1800 seconds, got %v - %v", w.NextStart, w.NextEnd)
+	}
+	if len(w.Checks) != 1 || w.Checks[0] != checkId {
+		t.Errorf("want window checks to be [%v], got %v", checkId, w.Checks)
+	}
+
+	// flips of the check are not suppressed out of window's period
+	req, _ := http.NewRequest("PUT", "/v1/pings/"+checkId+"/fail", nil)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var suppressed bool
+	err := s.DB().Get(&suppressed, "SELECT suppressed FROM flips WHERE check_id = $1", checkId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suppressed {
+		t.Error("want flip of the check out of maintenance not to be suppressed")
+	}
+}
+
+func TestHandler_CreateWindow_InvalidInput(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	checkId := createCheck(t, cookie, nil)
+
+	cases := []maintenance.CreateMaintenanceWindowBody{
+		// no checks and tags
+		{Name: "window", StartsAt: int(time.Now().UnixMilli()), Duration: 3600},
+		// no start and cron
+		{Name: "window", Duration: 3600, Checks: []string{checkId}},
+		// ended already
+		{Name: "window", StartsAt: int(time.Now().Add(-time.Hour * 2).UnixMilli()), Duration: 3600, Checks: []string{checkId}},
+		// invalid cron
+		{Name: "window", Cron: "invalid", Duration: 3600, Checks: []string{checkId}},
+		// check of another user
+		{Name: "window", StartsAt: int(time.Now().UnixMilli()), Duration: 3600, Checks: []string{"5d8f0e0e-6a3a-4a43-9c1e-2c7a2a0f1b11"}},
+	}
+	for _, c := range cases {
+		body, err := json.Marshal(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req, _ := http.NewRequest("POST", "/v1/maintenance", bytes.NewReader(body))
+		req.Header.Set("Cookie", cookie)
+		response := test.ExecuteRequest(s, req)
+		if response.Code != http.StatusBadRequest && response.Code != http.StatusNotFound {
+			t.Errorf("want status code 400 or 404 for %+v, got %v", c, response.Code)
+		}
+	}
+}
+
+func TestHandler_UpdateWindow(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	checkId := createCheck(t, cookie, nil)
+
+	window := createWindow(t, cookie, maintenance.CreateMaintenanceWindowBody{
+		Name:     "window",
+		StartsAt: int(time.Now().UnixMilli()),
+		Duration: 3600,
+		Checks:   []string{checkId},
+	})
+
+	body, err := json.Marshal(maintenance.UpdateMaintenanceWindowBody{
+		CreateMaintenanceWindowBody: maintenance.CreateMaintenanceWindowBody{
+			Name:     "updated window",
+			StartsAt: int(time.Now().UnixMilli()),
+			Duration: 7200,
+			Tags:     []string{"db"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest("PUT", "/v1/maintenance/"+strconv.Itoa(window.Id), bytes.NewReader(body))
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	windows := getWindows(t, cookie)
+	if len(windows) != 1 {
+		t.Fatalf("want 1 window, got %v", len(windows))
+	}
+	w := windows[0]
+	if w.Name != "updated window" || w.Duration != 7200 || len(w.Checks) != 0 || len(w.Tags) != 1 {
+		t.Errorf("want window to be updated, got %+v", w)
+	}
+}
+
+func TestHandler_DeleteWindow(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	checkId := createCheck(t, cookie, nil)
+
+	window := createWindow(t, cookie, maintenance.CreateMaintenanceWindowBody{
+		Name:     "window",
+		StartsAt: int(time.Now().UnixMilli()),
+		Duration: 3600,
+		Checks:   []string{checkId},
+	})
+
+	req, _ := http.NewRequest("DELETE", "/v1/maintenance/"+strconv.Itoa(window.Id), nil)
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	if windows := getWindows(t, cookie); len(windows) != 0 {
+		t.Errorf("want no windows, got %v", len(windows))
+	}
+
+	req, _ = http.NewRequest("DELETE", "/v1/maintenance/"+strconv.Itoa(window.Id), nil)
+	req.Header.Set("Cookie", cookie)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusNotFound, response.Code)
+}
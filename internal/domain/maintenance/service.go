@@ -0,0 +1,90 @@
+package maintenance
+
+import (
+	"context"
+	"gitlab.com/grygoryz/uptime-checker/internal/entity"
+	"gitlab.com/grygoryz/uptime-checker/internal/repository"
+	"gitlab.com/grygoryz/uptime-checker/internal/schedule"
+	"gitlab.com/grygoryz/uptime-checker/internal/utility/errors"
+	"time"
+)
+
+type service struct {
+	r *repository.Registry
+}
+
+func NewService(repositoryRegistry *repository.Registry) *service {
+	return &service{r: repositoryRegistry}
+}
+
+func (s *service) GetWindows(ctx context.Context, userId int) ([]entity.MaintenanceWindow, error) {
+	return s.r.Maintenance.GetMany(ctx, userId)
+}
+
+func (s *service) CreateWindow(ctx context.Context, window entity.CreateMaintenanceWindow, checks []string) (int, error) {
+	var err error
+	window.NextStart, window.NextEnd, err = period(window.StartsAt, window.Cron, window.Timezone, window.Duration)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int
+	err = s.r.WithTx(ctx, func(ctx context.Context) error {
+		id, err = s.r.Maintenance.Create(ctx, window)
+		if err != nil {
+			return err
+		}
+
+		return s.r.Maintenance.SetChecks(ctx, entity.SetMaintenanceChecks{
+			Id:     id,
+			UserId: window.UserId,
+			Checks: checks,
+		})
+	})
+
+	return id, err
+}
+
+func (s *service) UpdateWindow(ctx context.Context, window entity.UpdateMaintenanceWindow, checks []string) error {
+	var err error
+	window.NextStart, window.NextEnd, err = period(window.StartsAt, window.Cron, window.Timezone, window.Duration)
+	if err != nil {
+		return err
+	}
+
+	return s.r.WithTx(ctx, func(ctx context.Context) error {
+		err := s.r.Maintenance.Update(ctx, window)
+		if err != nil {
+			return err
+		}
+
+		return s.r.Maintenance.SetChecks(ctx, entity.SetMaintenanceChecks{
+			Id:     window.Id,
+			UserId: window.UserId,
+			Checks: checks,
+		})
+	})
+}
+
+func (s *service) DeleteWindow(ctx context.Context, window entity.DeleteMaintenanceWindow) error {
+	return s.r.Maintenance.Delete(ctx, window)
+}
+
+// period returns the current or the upcoming period of the window. One-off window must not have ended yet,
+// recurring one starts at the next run of its cron expression
+func period(startsAt *time.Time, cron string, timezone string, duration int) (time.Time, time.Time, error) {
+	d := time.Second * time.Duration(duration)
+	if cron == "" {
+		end := startsAt.Add(d)
+		if !end.After(time.Now()) {
+			return time.Time{}, time.Time{}, errors.E(errors.Validation, "maintenance window must end in the future")
+		}
+		return *startsAt, end, nil
+	}
+
+	start, err := schedule.Next(entity.Schedule{Kind: entity.ScheduleCron, Cron: cron, Timezone: timezone}, time.Now())
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return start, start.Add(d), nil
+}
@@ -3,3 +3,20 @@ package ping
 type CheckIdParam struct {
 	Id string `json:"id" validate:"uuid4"`
 }
+
+type ExitCodeParam struct {
+	ExitCode int `json:"exitCode" validate:"min=0,max=255"`
+}
+
+type RunIdQuery struct {
+	RunId string `json:"rid" validate:"uuid"`
+}
+
+type InstanceQuery struct {
+	Instance string `json:"instance" validate:"max=100,printascii"`
+}
+
+type SlugParams struct {
+	PingKey string `json:"pingKey" validate:"len=32,hexadecimal"`
+	Slug    string `json:"slug" validate:"max=100,slug"`
+}
@@ -4,9 +4,11 @@ import (
 	"github.com/go-chi/chi/v5"
 	"gitlab.com/grygoryz/uptime-checker/internal/entity"
 	"gitlab.com/grygoryz/uptime-checker/internal/utility/logger"
+	"gitlab.com/grygoryz/uptime-checker/internal/utility/request"
 	"gitlab.com/grygoryz/uptime-checker/internal/utility/respond"
 	"gitlab.com/grygoryz/uptime-checker/internal/validate"
 	"io"
+	"net"
 	"net/http"
 	"time"
 )
@@ -20,10 +22,27 @@ func RegisterHandler(router *chi.Mux, service *service, validator *validate.Vali
 	h := handler{service: service, validator: validator}
 
 	router.Route("/v1/pings", func(router chi.Router) {
-		router.Put("/{checkId}", h.CreateSuccessPing)
-		router.Put("/{checkId}/start", h.CreateStartPing)
-		router.Put("/{checkId}/fail", h.CreateFailPing)
+		handleAll(router, "/{checkId}", h.CreateSuccessPing)
+		handleAll(router, "/{checkId}/start", h.CreateStartPing)
+		handleAll(router, "/{checkId}/fail", h.CreateFailPing)
+		handleAll(router, "/{checkId}/{exitCode}", h.CreateExitCodePing)
 	})
+
+	router.Route("/v1/ping/{pingKey}/{slug}", func(router chi.Router) {
+		handleAll(router, "/", h.CreateSuccessSlugPing)
+		handleAll(router, "/start", h.CreateStartSlugPing)
+		handleAll(router, "/fail", h.CreateFailSlugPing)
+	})
+}
+
+// pingMethods are accepted by all ping endpoints, since cron wrappers and monitoring tools use different ones
+var pingMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut}
+
+// handleAll registers handler for the pattern with all ping methods
+func handleAll(router chi.Router, pattern string, handler http.HandlerFunc) {
+	for _, method := range pingMethods {
+		router.MethodFunc(method, pattern, handler)
+	}
 }
 
 // maxBodySize defines max size of body in bytes
@@ -36,10 +55,15 @@ const maxBodySize = 300000
 // @Produce json
 // @Param body body string false "body"
 // @Param checkId path string true "check id"
+// @Param rid query string false "run id pairing start ping with the finishing one"
+// @Param instance query string false "instance of fleet check, host of the request by default"
 // @Success 200
+// @router /v1/pings/{checkId} [get]
+// @router /v1/pings/{checkId} [head]
+// @router /v1/pings/{checkId} [post]
 // @router /v1/pings/{checkId} [put]
 func (h handler) CreateSuccessPing(w http.ResponseWriter, r *http.Request) {
-	h.pingHandler(w, r, entity.PingSuccess)
+	h.pingHandler(w, r, entity.PingSuccess, nil)
 }
 
 // CreateStartPing creates start ping
@@ -49,10 +73,15 @@ func (h handler) CreateSuccessPing(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param body body string false "body"
 // @Param checkId path string true "check id"
+// @Param rid query string false "run id pairing start ping with the finishing one"
+// @Param instance query string false "instance of fleet check, host of the request by default"
 // @Success 200
+// @router /v1/pings/{checkId}/start [get]
+// @router /v1/pings/{checkId}/start [head]
+// @router /v1/pings/{checkId}/start [post]
 // @router /v1/pings/{checkId}/start [put]
 func (h handler) CreateStartPing(w http.ResponseWriter, r *http.Request) {
-	h.pingHandler(w, r, entity.PingStart)
+	h.pingHandler(w, r, entity.PingStart, nil)
 }
 
 // CreateFailPing creates fail ping
@@ -62,15 +91,115 @@ func (h handler) CreateStartPing(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param body body string false "body"
 // @Param checkId path string true "check id"
+// @Param rid query string false "run id pairing start ping with the finishing one"
+// @Param instance query string false "instance of fleet check, host of the request by default"
 // @Success 200
+// @router /v1/pings/{checkId}/fail [get]
+// @router /v1/pings/{checkId}/fail [head]
+// @router /v1/pings/{checkId}/fail [post]
 // @router /v1/pings/{checkId}/fail [put]
 func (h handler) CreateFailPing(w http.ResponseWriter, r *http.Request) {
-	h.pingHandler(w, r, entity.PingFail)
+	h.pingHandler(w, r, entity.PingFail, nil)
 }
 
-func (h handler) pingHandler(w http.ResponseWriter, r *http.Request, kind entity.PingKind) {
-	log := logger.LogEntry(r.Context())
+// CreateExitCodePing creates success ping for zero exit code and fail ping for any other one
+// @Tags Pings
+// @Summary Create ping with exit code
+// @Accept json
+// @Produce json
+// @Param body body string false "body"
+// @Param checkId path string true "check id"
+// @Param rid query string false "run id pairing start ping with the finishing one"
+// @Param instance query string false "instance of fleet check, host of the request by default"
+// @Param exitCode path int true "exit code of the job, 0-255"
+// @Success 200
+// @router /v1/pings/{checkId}/{exitCode} [get]
+// @router /v1/pings/{checkId}/{exitCode} [head]
+// @router /v1/pings/{checkId}/{exitCode} [post]
+// @router /v1/pings/{checkId}/{exitCode} [put]
+func (h handler) CreateExitCodePing(w http.ResponseWriter, r *http.Request) {
+	exitCode, err := request.IntParam(r, "exitCode")
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+	err = h.validator.Struct(ExitCodeParam{ExitCode: exitCode})
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	kind := entity.PingSuccess
+	if exitCode != 0 {
+		kind = entity.PingFail
+	}
+	h.pingHandler(w, r, kind, &exitCode)
+}
+
+// CreateSuccessSlugPing creates success ping for check with the slug
+// @Tags Pings
+// @Summary Create success ping by slug
+// @Description Unknown check is created if "create" query param is set to 1
+// @Accept json
+// @Produce json
+// @Param body body string false "body"
+// @Param pingKey path string true "user's ping key"
+// @Param slug path string true "check slug"
+// @Param rid query string false "run id pairing start ping with the finishing one"
+// @Param instance query string false "instance of fleet check, host of the request by default"
+// @Param create query int false "create check if it does not exist"
+// @Success 200
+// @router /v1/ping/{pingKey}/{slug} [get]
+// @router /v1/ping/{pingKey}/{slug} [head]
+// @router /v1/ping/{pingKey}/{slug} [post]
+// @router /v1/ping/{pingKey}/{slug} [put]
+func (h handler) CreateSuccessSlugPing(w http.ResponseWriter, r *http.Request) {
+	h.slugPingHandler(w, r, entity.PingSuccess)
+}
+
+// CreateStartSlugPing creates start ping for check with the slug
+// @Tags Pings
+// @Summary Create start ping by slug
+// @Description Unknown check is created if "create" query param is set to 1
+// @Accept json
+// @Produce json
+// @Param body body string false "body"
+// @Param pingKey path string true "user's ping key"
+// @Param slug path string true "check slug"
+// @Param rid query string false "run id pairing start ping with the finishing one"
+// @Param instance query string false "instance of fleet check, host of the request by default"
+// @Param create query int false "create check if it does not exist"
+// @Success 200
+// @router /v1/ping/{pingKey}/{slug}/start [get]
+// @router /v1/ping/{pingKey}/{slug}/start [head]
+// @router /v1/ping/{pingKey}/{slug}/start [post]
+// @router /v1/ping/{pingKey}/{slug}/start [put]
+func (h handler) CreateStartSlugPing(w http.ResponseWriter, r *http.Request) {
+	h.slugPingHandler(w, r, entity.PingStart)
+}
+
+// CreateFailSlugPing creates fail ping for check with the slug
+// @Tags Pings
+// @Summary Create fail ping by slug
+// @Description Unknown check is created if "create" query param is set to 1
+// @Accept json
+// @Produce json
+// @Param body body string false "body"
+// @Param pingKey path string true "user's ping key"
+// @Param slug path string true "check slug"
+// @Param rid query string false "run id pairing start ping with the finishing one"
+// @Param instance query string false "instance of fleet check, host of the request by default"
+// @Param create query int false "create check if it does not exist"
+// @Success 200
+// @router /v1/ping/{pingKey}/{slug}/fail [get]
+// @router /v1/ping/{pingKey}/{slug}/fail [head]
+// @router /v1/ping/{pingKey}/{slug}/fail [post]
+// @router /v1/ping/{pingKey}/{slug}/fail [put]
+func (h handler) CreateFailSlugPing(w http.ResponseWriter, r *http.Request) {
+	h.slugPingHandler(w, r, entity.PingFail)
+}
 
+func (h handler) pingHandler(w http.ResponseWriter, r *http.Request, kind entity.PingKind, exitCode *int) {
 	checkId := chi.URLParam(r, "checkId")
 	err := h.validator.Struct(CheckIdParam{Id: checkId})
 	if err != nil {
@@ -78,6 +207,77 @@ func (h handler) pingHandler(w http.ResponseWriter, r *http.Request, kind entity
 		return
 	}
 
+	ping, err := h.newPing(w, r, kind)
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+	ping.CheckId = checkId
+	ping.ExitCode = exitCode
+	err = h.service.CreatePing(r.Context(), ping)
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	respond.Status(w, http.StatusOK)
+}
+
+func (h handler) slugPingHandler(w http.ResponseWriter, r *http.Request, kind entity.PingKind) {
+	params := SlugParams{
+		PingKey: chi.URLParam(r, "pingKey"),
+		Slug:    chi.URLParam(r, "slug"),
+	}
+	err := h.validator.Struct(params)
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	ping, err := h.newPing(w, r, kind)
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+	err = h.service.CreateSlugPing(r.Context(), entity.GetCheckBySlug{
+		PingKey: params.PingKey,
+		Slug:    params.Slug,
+	}, r.URL.Query().Get("create") == "1", ping)
+	if err != nil {
+		respond.Error(r.Context(), w, err)
+		return
+	}
+
+	respond.Status(w, http.StatusOK)
+}
+
+// newPing returns ping of the kind filled with request data
+func (h handler) newPing(w http.ResponseWriter, r *http.Request, kind entity.PingKind) (entity.CreatePing, error) {
+	log := logger.LogEntry(r.Context())
+
+	var runId *string
+	if r.URL.Query().Has("rid") {
+		rid := r.URL.Query().Get("rid")
+		err := h.validator.Struct(RunIdQuery{RunId: rid})
+		if err != nil {
+			return entity.CreatePing{}, err
+		}
+		runId = &rid
+	}
+
+	// instances of fleet checks are told apart by client-supplied name or by host the pings come from
+	instance := r.URL.Query().Get("instance")
+	if instance != "" {
+		err := h.validator.Struct(InstanceQuery{Instance: instance})
+		if err != nil {
+			return entity.CreatePing{}, err
+		}
+	} else if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		instance = host
+	} else {
+		instance = r.RemoteAddr
+	}
+
 	body := ""
 	if r.Body != nil {
 		r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
@@ -88,18 +288,13 @@ func (h handler) pingHandler(w http.ResponseWriter, r *http.Request, kind entity
 		body = string(b)
 	}
 
-	err = h.service.CreatePing(r.Context(), entity.CreatePing{
-		CheckId:   checkId,
+	return entity.CreatePing{
 		Type:      kind,
 		Source:    r.RemoteAddr,
 		UserAgent: r.UserAgent(),
 		Body:      body,
 		Date:      time.Now(),
-	})
-	if err != nil {
-		respond.Error(r.Context(), w, err)
-		return
-	}
-
-	respond.Status(w, http.StatusOK)
+		RunId:     runId,
+		Instance:  instance,
+	}, nil
 }
@@ -4,15 +4,18 @@ import (
 	"bytes"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"github.com/google/go-cmp/cmp"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"gitlab.com/grygoryz/uptime-checker/config"
+	"gitlab.com/grygoryz/uptime-checker/internal/domain/auth"
 	"gitlab.com/grygoryz/uptime-checker/internal/domain/channel"
 	"gitlab.com/grygoryz/uptime-checker/internal/domain/check"
 	"gitlab.com/grygoryz/uptime-checker/internal/entity"
 	"gitlab.com/grygoryz/uptime-checker/internal/server"
 	"gitlab.com/grygoryz/uptime-checker/internal/utility/test"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 )
@@ -82,7 +85,7 @@ func getCheck(t *testing.T, cookie string, id string) check.Check {
 
 func getLastPing(t *testing.T, checkId string) entity.Ping {
 	var ping entity.Ping
-	err := s.DB().Get(&ping, `SELECT id, "type", "date", source, user_agent, duration, body
+	err := s.DB().Get(&ping, `SELECT id, "type", "date", source, user_agent, duration, exit_code, run_id, body
     FROM pings
 	WHERE check_id = $1
 	ORDER BY date DESC
@@ -99,7 +102,7 @@ func getLastPing(t *testing.T, checkId string) entity.Ping {
 
 func getLastFlip(t *testing.T, checkId string) entity.Flip {
 	var flip entity.Flip
-	err := s.DB().Get(&flip, `SELECT "to", "date"
+	err := s.DB().Get(&flip, `SELECT "to", exit_code, "date"
     FROM flips
 	WHERE check_id = $1
 	ORDER BY date DESC
@@ -333,6 +336,75 @@ func TestHandler_CreateSuccessPing_PausedCheck(t *testing.T) {
 	}
 }
 
+func TestHandler_CreateSuccessPing_PausedCheckIgnorePolicy(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+
+	req, _ := http.NewRequest("GET", "/v1/channels", nil)
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var channels []channel.GetChannelsResponseItem
+	err := json.Unmarshal(response.Body.Bytes(), &channels)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := json.Marshal(check.CreateCheckBody{
+		Name:        "testcheck",
+		Description: "some description",
+		Interval:    60,
+		Grace:       3600,
+		PausePolicy: entity.PauseIgnore,
+		Channels:    []int{channels[0].Id},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ = http.NewRequest("POST", "/v1/checks", bytes.NewReader(body))
+	req.Header.Set("Cookie", cookie)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusCreated, response.Code)
+
+	var created check.CreateCheckResponse
+	err = json.Unmarshal(response.Body.Bytes(), &created)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := created.Id
+
+	req, _ = http.NewRequest("PUT", "/v1/checks/"+id+"/pause", nil)
+	req.Header.Set("Cookie", cookie)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	req, _ = http.NewRequest("PUT", "/v1/pings/"+id, nil)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	// verify ping is stored
+	ping := getLastPing(t, id)
+	if ping.Type != entity.PingSuccess {
+		t.Errorf("want ping Type to be %v, got %v", entity.PingSuccess, ping.Type)
+	}
+
+	// verify check stays paused
+	ch := getCheck(t, cookie, id)
+	if ch.Status != entity.CheckPaused {
+		t.Errorf("want check Status to be %v, got %v", entity.CheckPaused, ch.Status)
+	}
+	if ch.LastPing != nil {
+		t.Errorf("want check LastPing to be nil, got %v", ch.LastPing)
+	}
+
+	// verify flip
+	f := getLastFlip(t, id)
+	if f.To != entity.FlipPaused {
+		t.Errorf("want flip status to be %v, got %v", entity.FlipPaused, f.To)
+	}
+}
+
 func TestHandler_CreateSuccessPing_StartedCheck(t *testing.T) {
 	cookie, _ := test.Authorize(t, s)
 	id := createCheck(t, cookie)
@@ -486,6 +558,33 @@ func TestHandler_CreateSuccessPing_UpCheck(t *testing.T) {
 	}
 }
 
+func TestHandler_CreateSuccessPing_StartedUpCheck(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	id := createCheck(t, cookie)
+
+	for _, path := range []string{"/v1/pings/" + id, "/v1/pings/" + id + "/start", "/v1/pings/" + id} {
+		req, _ := http.NewRequest("PUT", path, nil)
+		req.Header.Set("Cookie", cookie)
+		response := test.ExecuteRequest(s, req)
+		test.CheckCode(t, http.StatusOK, response.Code)
+	}
+
+	ch := getCheck(t, cookie, id)
+	if ch.Status != entity.CheckUp {
+		t.Errorf("want check Status to be %v, got %v", entity.CheckUp, ch.Status)
+	}
+
+	// finished run of up check doesn't flip it up again
+	var count int
+	err := s.DB().Get(&count, `SELECT count(*) FROM flips WHERE check_id = $1`, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("want flips count to be 1, got %v", count)
+	}
+}
+
 func TestHandler_FailPing_NewCheck(t *testing.T) {
 	cookie, _ := test.Authorize(t, s)
 	id := createCheck(t, cookie)
@@ -801,3 +900,530 @@ func TestHandler_StartPing(t *testing.T) {
 		t.Errorf("want check LastStarted to be %v, got %v", ping.Date, ch.LastStarted)
 	}
 }
+
+func getPingKey(t *testing.T, cookie string) string {
+	req, _ := http.NewRequest("GET", "/v1/auth/ping-key", nil)
+	req.Header.Set("Cookie", cookie)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var key auth.PingKeyResponse
+	err := json.Unmarshal(response.Body.Bytes(), &key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key.PingKey
+}
+
+func TestHandler_CreateSlugPing_UnknownSlug(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	pingKey := getPingKey(t, cookie)
+
+	req, _ := http.NewRequest("PUT", "/v1/ping/"+pingKey+"/unknown-job", nil)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusNotFound, response.Code)
+}
+
+func TestHandler_CreateSlugPing_AutoCreate(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	pingKey := getPingKey(t, cookie)
+
+	req, _ := http.NewRequest("PUT", "/v1/ping/"+pingKey+"/nightly-backup/start?create=1", nil)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	req, _ = http.NewRequest("PUT", "/v1/ping/"+pingKey+"/nightly-backup", nil)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	req, _ = http.NewRequest("GET", "/v1/checks", nil)
+	req.Header.Set("Cookie", cookie)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var checks []check.Check
+	err := json.Unmarshal(response.Body.Bytes(), &checks)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(checks) != 1 {
+		t.Fatalf("want 1 check to be created, got %v", len(checks))
+	}
+	ch := checks[0]
+	if ch.Slug == nil || *ch.Slug != "nightly-backup" {
+		t.Errorf("want check Slug to be %q, got %v", "nightly-backup", ch.Slug)
+	}
+	if ch.Status != entity.CheckUp {
+		t.Errorf("want check Status to be %v, got %v", entity.CheckUp, ch.Status)
+	}
+	if len(ch.Channels) == 0 {
+		t.Error("want check to have user's channels")
+	}
+
+	ping := getLastPing(t, ch.Id)
+	if ping.Type != entity.PingSuccess || ping.Duration == nil {
+		t.Errorf("want success ping with duration, got %v with duration %v", ping.Type, ping.Duration)
+	}
+}
+
+func TestHandler_CreatePing_AllMethods(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	id := createCheck(t, cookie)
+
+	for _, method := range []string{"GET", "HEAD", "POST", "PUT"} {
+		req, _ := http.NewRequest(method, "/v1/pings/"+id, nil)
+		response := test.ExecuteRequest(s, req)
+		test.CheckCode(t, http.StatusOK, response.Code)
+	}
+
+	var total int
+	err := s.DB().Get(&total, "SELECT count(*) FROM pings WHERE check_id = $1", id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 4 {
+		t.Errorf("want 4 pings, got %v", total)
+	}
+}
+
+func TestHandler_CreateExitCodePing(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	id := createCheck(t, cookie)
+
+	req, _ := http.NewRequest("GET", "/v1/pings/"+id+"/0", nil)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	ping := getLastPing(t, id)
+	if ping.Type != entity.PingSuccess || ping.ExitCode == nil || *ping.ExitCode != 0 {
+		t.Errorf("want success ping with exit code 0, got %v ping with exit code %v", ping.Type, ping.ExitCode)
+	}
+
+	req, _ = http.NewRequest("POST", "/v1/pings/"+id+"/3", nil)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	ping = getLastPing(t, id)
+	if ping.Type != entity.PingFail || ping.ExitCode == nil || *ping.ExitCode != 3 {
+		t.Errorf("want fail ping with exit code 3, got %v ping with exit code %v", ping.Type, ping.ExitCode)
+	}
+
+	ch := getCheck(t, cookie, id)
+	if ch.Status != entity.CheckDown {
+		t.Errorf("want check Status to be %v, got %v", entity.CheckDown, ch.Status)
+	}
+
+	f := getLastFlip(t, id)
+	if f.To != entity.FlipDown || f.ExitCode == nil || *f.ExitCode != 3 {
+		t.Errorf("want down flip with exit code 3, got %v flip with exit code %v", f.To, f.ExitCode)
+	}
+}
+
+func TestHandler_CreateExitCodePing_InvalidCode(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	id := createCheck(t, cookie)
+
+	for _, code := range []string{"-1", "256", "abc"} {
+		req, _ := http.NewRequest("PUT", "/v1/pings/"+id+"/"+code, nil)
+		response := test.ExecuteRequest(s, req)
+		test.CheckCode(t, http.StatusBadRequest, response.Code)
+	}
+}
+
+func TestHandler_CreatePing_RunId(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	id := createCheck(t, cookie)
+
+	ridA := "0b7c1c5e-5d0a-4d47-9a3c-2f6b8e1d7a01"
+	ridB := "0b7c1c5e-5d0a-4d47-9a3c-2f6b8e1d7a02"
+	req, _ := http.NewRequest("PUT", "/v1/pings/"+id+"/start?rid="+ridA, nil)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	// move start of the first run to the past, so its duration differs from the second one's
+	_, err := s.DB().Exec(`UPDATE pings SET date = date - interval '20 seconds' WHERE run_id = $1`, ridA)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ = http.NewRequest("PUT", "/v1/pings/"+id+"/start?rid="+ridB, nil)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	req, _ = http.NewRequest("PUT", "/v1/pings/"+id+"?rid="+ridA, nil)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	ping := getLastPing(t, id)
+	if ping.RunId == nil || *ping.RunId != ridA {
+		t.Fatalf("want ping RunId to be %v, got %v", ridA, ping.RunId)
+	}
+	if ping.Duration == nil || *ping.Duration < 20 {
+		t.Errorf("want ping Duration to be at least 20, got %v", ping.Duration)
+	}
+
+	req, _ = http.NewRequest("PUT", "/v1/pings/"+id+"?rid="+ridB, nil)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	ping = getLastPing(t, id)
+	if ping.Duration == nil || *ping.Duration > 1 {
+		t.Errorf("want ping Duration to be less than 2, got %v", ping.Duration)
+	}
+}
+
+func TestHandler_CreatePing_InvalidRunId(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	id := createCheck(t, cookie)
+
+	req, _ := http.NewRequest("PUT", "/v1/pings/"+id+"/start?rid=invalid", nil)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusBadRequest, response.Code)
+}
+
+func TestHandler_CreateSuccessPing_LateCheck(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	id := createCheck(t, cookie)
+
+	req, _ := http.NewRequest("PUT", "/v1/pings/"+id, nil)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	_, err := s.DB().Exec("UPDATE checks SET status = 'late' WHERE id = $1", id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ = http.NewRequest("PUT", "/v1/pings/"+id, nil)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	ch := getCheck(t, cookie, id)
+	if ch.Status != entity.CheckUp {
+		t.Errorf("want check Status to be %v, got %v", entity.CheckUp, ch.Status)
+	}
+
+	var total int
+	err = s.DB().Get(&total, "SELECT count(*) FROM flips WHERE check_id = $1", id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 {
+		t.Errorf("want only 1 flip from new to up, got %v", total)
+	}
+}
+
+func TestHandler_FailPing_FailureThreshold(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	id := createCheck(t, cookie)
+
+	_, err := s.DB().Exec("UPDATE checks SET failure_threshold = 2 WHERE id = $1", id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// failure of new check is counted, but the check stays new
+	req, _ := http.NewRequest("PUT", "/v1/pings/"+id+"/fail", nil)
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	ch := getCheck(t, cookie, id)
+	if ch.Status != entity.CheckNew || ch.Failures != 1 {
+		t.Errorf("want check Status to be %v with 1 failure, got %v with %v", entity.CheckNew, ch.Status, ch.Failures)
+	}
+	if ch.NextPing == nil {
+		t.Error("want check NextPing not to be nil")
+	}
+
+	var total int
+	err = s.DB().Get(&total, "SELECT count(*) FROM flips WHERE check_id = $1", id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 0 {
+		t.Errorf("want no flips before threshold is reached, got %v", total)
+	}
+
+	// failed run of up check below the threshold gets the check back up
+	for _, path := range []string{"", "/start", "/fail"} {
+		req, _ = http.NewRequest("PUT", "/v1/pings/"+id+path, nil)
+		response = test.ExecuteRequest(s, req)
+		test.CheckCode(t, http.StatusOK, response.Code)
+	}
+
+	ch = getCheck(t, cookie, id)
+	if ch.Status != entity.CheckUp || ch.Failures != 1 {
+		t.Errorf("want check Status to be %v with 1 failure, got %v with %v", entity.CheckUp, ch.Status, ch.Failures)
+	}
+
+	req, _ = http.NewRequest("PUT", "/v1/pings/"+id+"/fail", nil)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	ch = getCheck(t, cookie, id)
+	if ch.Status != entity.CheckDown || ch.Failures != 2 {
+		t.Errorf("want check Status to be %v with 2 failures, got %v with %v", entity.CheckDown, ch.Status, ch.Failures)
+	}
+	f := getLastFlip(t, id)
+	if f.To != entity.FlipDown {
+		t.Errorf("want flip status to be %v, got %v", entity.FlipDown, f.To)
+	}
+
+	// success ping resets failures
+	req, _ = http.NewRequest("PUT", "/v1/pings/"+id, nil)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	ch = getCheck(t, cookie, id)
+	if ch.Status != entity.CheckUp || ch.Failures != 0 {
+		t.Errorf("want check Status to be %v with 0 failures, got %v with %v", entity.CheckUp, ch.Status, ch.Failures)
+	}
+
+	// failure of paused check below the threshold resumes it
+	_, err = s.DB().Exec(
+		"UPDATE checks SET status = 'paused', resume_at = current_timestamp + interval '1 hour' WHERE id = $1",
+		id,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ = http.NewRequest("PUT", "/v1/pings/"+id+"/fail", nil)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	ch = getCheck(t, cookie, id)
+	if ch.Status != entity.CheckUp || ch.Failures != 1 {
+		t.Errorf("want check Status to be %v with 1 failure, got %v with %v", entity.CheckUp, ch.Status, ch.Failures)
+	}
+	f = getLastFlip(t, id)
+	if f.To != entity.FlipResumed {
+		t.Errorf("want flip status to be %v, got %v", entity.FlipResumed, f.To)
+	}
+}
+
+func TestHandler_CreateSuccessPing_TooFast(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	id := createCheck(t, cookie)
+
+	_, err := s.DB().Exec("UPDATE checks SET min_duration = 60, min_duration_action = 'fail' WHERE id = $1", id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{"/start", ""} {
+		req, _ := http.NewRequest("PUT", "/v1/pings/"+id+path, nil)
+		response := test.ExecuteRequest(s, req)
+		test.CheckCode(t, http.StatusOK, response.Code)
+	}
+
+	ch := getCheck(t, cookie, id)
+	if ch.Status != entity.CheckDown {
+		t.Errorf("want check Status to be %v, got %v", entity.CheckDown, ch.Status)
+	}
+	p := getLastPing(t, id)
+	if p.Type != entity.PingFail {
+		t.Errorf("want ping type to be %v, got %v", entity.PingFail, p.Type)
+	}
+
+	var reason entity.FlipReason
+	err = s.DB().Get(&reason, "SELECT reason FROM flips WHERE check_id = $1 ORDER BY date DESC LIMIT 1", id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reason != entity.FlipTooFast {
+		t.Errorf("want flip reason to be %v, got %v", entity.FlipTooFast, reason)
+	}
+}
+
+func TestHandler_CreateSuccessPing_TooFastWarning(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	id := createCheck(t, cookie)
+
+	_, err := s.DB().Exec("UPDATE checks SET min_duration = 60, min_duration_action = 'warn' WHERE id = $1", id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{"/start", ""} {
+		req, _ := http.NewRequest("PUT", "/v1/pings/"+id+path, nil)
+		response := test.ExecuteRequest(s, req)
+		test.CheckCode(t, http.StatusOK, response.Code)
+	}
+
+	ch := getCheck(t, cookie, id)
+	if ch.Status != entity.CheckUp {
+		t.Errorf("want check Status to be %v, got %v", entity.CheckUp, ch.Status)
+	}
+
+	var threshold *int
+	err = s.DB().Get(&threshold, `SELECT fast_threshold FROM pings WHERE check_id = $1 AND "type" = 'success'`, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if threshold == nil || *threshold != 60 {
+		t.Errorf("want ping fast threshold to be 60, got %v", threshold)
+	}
+}
+
+func TestHandler_CreatePing_BodyRules(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	id := createCheck(t, cookie)
+
+	_, err := s.DB().Exec(`INSERT INTO body_rules (check_id, position, match, pattern, outcome)
+	VALUES ($1, 0, 'regex', '(?i)\berror\b', 'fail'), ($1, 1, 'substring', 'retrying', 'success')`, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest("POST", "/v1/pings/"+id, strings.NewReader("backup: Error: disk is full"))
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	ch := getCheck(t, cookie, id)
+	if ch.Status != entity.CheckDown {
+		t.Errorf("want check Status to be %v, got %v", entity.CheckDown, ch.Status)
+	}
+
+	var rule *entity.BodyRule
+	err = s.DB().Get(&rule, "SELECT body_rule FROM pings WHERE check_id = $1", id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rule == nil || rule.Match != entity.BodyRuleRegex || rule.Outcome != entity.PingFail {
+		t.Errorf("want ping to record the regex rule, got %+v", rule)
+	}
+
+	req, _ = http.NewRequest("POST", "/v1/pings/"+id+"/fail", strings.NewReader("connection lost, retrying"))
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	ch = getCheck(t, cookie, id)
+	if ch.Status != entity.CheckUp {
+		t.Errorf("want check Status to be %v, got %v", entity.CheckUp, ch.Status)
+	}
+	p := getLastPing(t, id)
+	if p.Type != entity.PingSuccess {
+		t.Errorf("want ping type to be %v, got %v", entity.PingSuccess, p.Type)
+	}
+}
+
+func TestHandler_CreatePing_MetricRules(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	id := createCheck(t, cookie)
+
+	_, err := s.DB().Exec(`INSERT INTO check_metrics (check_id, position, name, path) VALUES ($1, 0, 'rows', 'stats.rows')`, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.DB().Exec(`INSERT INTO metric_rules (check_id, position, metric, op, threshold, outcome)
+	VALUES ($1, 0, 'rows', 'lt', 1000, 'fail')`, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from := time.Now().Add(-time.Minute)
+	req, _ := http.NewRequest("POST", "/v1/pings/"+id, strings.NewReader(`{"stats": {"rows": 10}}`))
+	response := test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	ch := getCheck(t, cookie, id)
+	if ch.Status != entity.CheckDown {
+		t.Errorf("want check Status to be %v, got %v", entity.CheckDown, ch.Status)
+	}
+
+	var rule *entity.MetricRule
+	err = s.DB().Get(&rule, "SELECT metric_rule FROM pings WHERE check_id = $1", id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rule == nil || rule.Metric != "rows" || rule.Outcome != entity.PingFail {
+		t.Errorf("want ping to record the rows rule, got %+v", rule)
+	}
+
+	req, _ = http.NewRequest("POST", "/v1/pings/"+id, strings.NewReader(`{"stats": {"rows": 5000}}`))
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	ch = getCheck(t, cookie, id)
+	if ch.Status != entity.CheckUp {
+		t.Errorf("want check Status to be %v, got %v", entity.CheckUp, ch.Status)
+	}
+
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/v1/checks/%v/metrics/rows?from=%v&to=%v",
+		id, from.UnixMilli(), time.Now().Add(time.Minute).UnixMilli()), nil)
+	req.Header.Set("Cookie", cookie)
+	response = test.ExecuteRequest(s, req)
+	test.CheckCode(t, http.StatusOK, response.Code)
+
+	var points []check.MetricPoint
+	err = json.Unmarshal(response.Body.Bytes(), &points)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(points) != 2 || points[0].Value != 10 || points[1].Value != 5000 {
+		t.Errorf("want metric history to be [10 5000], got %+v", points)
+	}
+}
+
+func TestHandler_CreatePing_Fleet(t *testing.T) {
+	cookie, _ := test.Authorize(t, s)
+	id := createCheck(t, cookie)
+
+	_, err := s.DB().Exec("UPDATE checks SET fleet = true, fleet_tolerance = 1 WHERE id = $1", id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ping := func(path string, instance string) {
+		req, _ := http.NewRequest("POST", "/v1/pings/"+id+path+"?instance="+instance, nil)
+		response := test.ExecuteRequest(s, req)
+		test.CheckCode(t, http.StatusOK, response.Code)
+	}
+
+	ping("", "host-1")
+	ping("", "host-2")
+	ping("", "host-3")
+	ping("/fail", "host-1")
+
+	ch := getCheck(t, cookie, id)
+	if ch.Status != entity.CheckUp {
+		t.Errorf("want check Status to be %v with one tolerated down instance, got %v", entity.CheckUp, ch.Status)
+	}
+	statuses := make(map[string]entity.InstanceStatus)
+	for _, instance := range ch.Instances {
+		statuses[instance.Name] = instance.Status
+	}
+	want := map[string]entity.InstanceStatus{
+		"host-1": entity.InstanceDown,
+		"host-2": entity.InstanceUp,
+		"host-3": entity.InstanceUp,
+	}
+	if diff := cmp.Diff(want, statuses); diff != "" {
+		t.Errorf("instances mismatch (-want +got):\n%s", diff)
+	}
+
+	ping("/fail", "host-2")
+	ch = getCheck(t, cookie, id)
+	if ch.Status != entity.CheckDown {
+		t.Errorf("want check Status to be %v, got %v", entity.CheckDown, ch.Status)
+	}
+
+	// a healthy instance doesn't bring the check up while too many instances are down
+	ping("", "host-3")
+	ch = getCheck(t, cookie, id)
+	if ch.Status != entity.CheckDown {
+		t.Errorf("want check Status to be %v, got %v", entity.CheckDown, ch.Status)
+	}
+
+	ping("", "host-2")
+	ch = getCheck(t, cookie, id)
+	if ch.Status != entity.CheckUp {
+		t.Errorf("want check Status to be %v, got %v", entity.CheckUp, ch.Status)
+	}
+	flip := getLastFlip(t, id)
+	if flip.To != entity.FlipUp {
+		t.Errorf("want last flip to be %v, got %v", entity.FlipUp, flip.To)
+	}
+}
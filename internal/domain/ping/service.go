@@ -3,9 +3,26 @@ package ping
 import (
 	"context"
 	"gitlab.com/grygoryz/uptime-checker/internal/entity"
+	"gitlab.com/grygoryz/uptime-checker/internal/flapping"
+	"gitlab.com/grygoryz/uptime-checker/internal/incident"
 	"gitlab.com/grygoryz/uptime-checker/internal/repository"
+	"gitlab.com/grygoryz/uptime-checker/internal/schedule"
 	"gitlab.com/grygoryz/uptime-checker/internal/utility/errors"
 	"math"
+	"time"
+)
+
+// defaultInterval and defaultGrace are used for checks created by the first ping, 1 day and 1 hour
+const (
+	defaultInterval = 86400
+	defaultGrace    = 3600
+)
+
+// slowRunsPeriod and slowRunsMin define recent runs which p95 duration relative slow run threshold is based on:
+// runs of the last 30 days, at least 10 of them
+const (
+	slowRunsPeriod = time.Hour * 24 * 30
+	slowRunsMin    = 10
 )
 
 type service struct {
@@ -18,64 +35,352 @@ func NewService(repositoryRegistry *repository.Registry) *service {
 
 func (s *service) CreatePing(ctx context.Context, ping entity.CreatePing) error {
 	return s.r.WithTx(ctx, func(ctx context.Context) error {
-		status, err := s.r.Check.GetStatus(ctx, ping.CheckId)
+		return s.createPing(ctx, ping)
+	})
+}
+
+// CreateSlugPing creates ping for check found by user's ping key and check's slug. If the check does not exist
+// and create is true, the check is created with default settings and all user's channels
+func (s *service) CreateSlugPing(
+	ctx context.Context,
+	params entity.GetCheckBySlug,
+	create bool,
+	ping entity.CreatePing,
+) error {
+	return s.r.WithTx(ctx, func(ctx context.Context) error {
+		checkId, err := s.r.Check.GetIdBySlug(ctx, params)
 		if err != nil {
-			return err
-		}
+			appErr, ok := err.(errors.AppError)
+			if !ok || appErr.Kind != errors.NotExist || !create {
+				return err
+			}
 
-		switch ping.Type {
-		case entity.PingStart:
-			err = s.r.Check.PingStart(ctx, ping.CheckId, ping.Date)
-		case entity.PingSuccess:
-			err = s.r.Check.PingSuccess(ctx, ping.CheckId, ping.Date)
+			checkId, err = s.createSlugCheck(ctx, params)
 			if err != nil {
 				return err
 			}
+		}
+
+		ping.CheckId = checkId
+		return s.createPing(ctx, ping)
+	})
+}
+
+// createSlugCheck creates check with the slug for the user with the ping key
+func (s *service) createSlugCheck(ctx context.Context, params entity.GetCheckBySlug) (string, error) {
+	userId, err := s.r.User.GetIdByPingKey(ctx, params.PingKey)
+	if err != nil {
+		return "", err
+	}
+
+	slug := params.Slug
+	checkId, err := s.r.Check.Create(ctx, entity.CreateCheck{
+		UserId:            userId,
+		Name:              slug,
+		Slug:              &slug,
+		Description:       "",
+		Schedule:          entity.ScheduleSimple,
+		Interval:          defaultInterval,
+		Timezone:          entity.DefaultTimezone,
+		Grace:             defaultGrace,
+		FailureThreshold:  entity.DefaultFailureThreshold,
+		FlapWindow:        entity.DefaultFlapPeriod,
+		FlapStable:        entity.DefaultFlapPeriod,
+		ReminderLimit:     entity.DefaultReminderLimit,
+		MinDurationAction: entity.DefaultMinDurationAction,
+		InstanceTTL:       entity.DefaultInstanceTTL,
+		Severity:          entity.DefaultSeverity,
+		PausePolicy:       entity.DefaultPausePolicy,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	channels, err := s.r.Channel.GetMany(ctx, userId)
+	if err != nil {
+		return "", err
+	}
+	if len(channels) > 0 {
+		ids := make([]int, len(channels))
+		for i, channel := range channels {
+			ids[i] = channel.Id
+		}
+		err = s.r.Check.AddChannels(ctx, entity.AddChannels{Id: checkId, Channels: ids})
+		if err != nil {
+			return "", err
+		}
+	}
 
-			if status != entity.CheckUp {
-				err = s.r.Flip.Create(ctx, entity.CreateFlip{
-					To:      entity.FlipUp,
-					Date:    ping.Date,
-					CheckId: ping.CheckId,
-				})
+	return checkId, nil
+}
+
+// createPing applies ping to check and stores it
+func (s *service) createPing(ctx context.Context, ping entity.CreatePing) error {
+	check, err := s.r.Check.GetForPing(ctx, ping.CheckId)
+	if err != nil {
+		return err
+	}
+
+	if ping.Type != entity.PingStart {
+		// pair the ping with the start of the same run, so overlapping runs get their own durations
+		lastPing, err := s.r.Ping.GetLastTypeAndDate(ctx, ping.CheckId, ping.RunId)
+		if err != nil {
+			appErr, ok := err.(errors.AppError)
+			if !ok || appErr.Kind != errors.NotExist {
+				return err
 			}
-		case entity.PingFail:
-			err = s.r.Check.PingFail(ctx, ping.CheckId, ping.Date)
+		}
+		if lastPing != nil && lastPing.Type == entity.PingStart {
+			ping.Duration.Int32 = int32(math.Round(ping.Date.Sub(lastPing.Date).Seconds()))
+			ping.Duration.Valid = true
+		}
+
+		// the first body rule which matches decides outcome of the run, metric rules are evaluated if none does
+		values := check.Metrics.Extract(ping.Body)
+		if rule := check.BodyRules.Decide(ping.Body); rule != nil {
+			ping.Type = rule.Outcome
+			ping.BodyRule = rule
+		} else if rule := check.MetricRules.Decide(values); rule != nil {
+			ping.Type = rule.Outcome
+			ping.MetricRule = rule
+		}
+
+		if len(values) > 0 {
+			err = s.r.Metric.CreateValues(ctx, entity.CreateMetricValues{
+				CheckId: ping.CheckId,
+				Values:  values,
+				Date:    ping.Date,
+			})
 			if err != nil {
 				return err
 			}
+		}
+	}
 
-			if status != entity.CheckDown {
-				err = s.r.Flip.Create(ctx, entity.CreateFlip{
-					To:      entity.FlipDown,
-					Date:    ping.Date,
-					CheckId: ping.CheckId,
-				})
+	// runs of paused checks are not expected, so they are not checked for slowness
+	if ping.Type == entity.PingSuccess && ping.Duration.Valid && check.Status != entity.CheckPaused {
+		threshold, err := s.slowThreshold(ctx, check.SlowRun, ping)
+		if err != nil {
+			return err
+		}
+		if threshold != nil && int(ping.Duration.Int32) > *threshold {
+			ping.SlowThreshold = threshold
+		}
+
+		// too fast run fails the check, or is warned about leaving the check up
+		if check.MinDuration != nil && int(ping.Duration.Int32) < *check.MinDuration {
+			ping.FastThreshold = check.MinDuration
+			if check.MinDurationAction == entity.MinDurationFail {
+				ping.Type = entity.PingFail
 			}
 		}
+	}
+
+	// instances are tracked for fleet checks only
+	if !check.Fleet.Fleet {
+		ping.Instance = ""
+	}
+
+	// pings of paused check with ignore policy are stored only
+	if check.Status != entity.CheckPaused || check.PausePolicy != entity.PauseIgnore {
+		if check.Fleet.Fleet {
+			err = s.applyFleetPing(ctx, check, ping)
+		} else {
+			err = s.applyPing(ctx, check, ping)
+		}
 		if err != nil {
 			return err
 		}
+	}
 
-		if ping.Type != entity.PingStart {
-			lastPing, err := s.r.Ping.GetLastTypeAndDate(ctx, ping.CheckId)
-			if err != nil {
-				appErr, ok := err.(errors.AppError)
-				if !ok || appErr.Kind != errors.NotExist {
-					return err
-				}
-			}
-			if lastPing != nil && lastPing.Type == entity.PingStart {
-				ping.Duration.Int32 = int32(math.Round(ping.Date.Sub(lastPing.Date).Seconds()))
-				ping.Duration.Valid = true
-			}
+	err = s.r.Ping.Create(ctx, ping)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// applyPing updates check's status according to the ping and creates flip if status has changed
+func (s *service) applyPing(ctx context.Context, check entity.CheckForPing, ping entity.CreatePing) error {
+	switch ping.Type {
+	case entity.PingStart:
+		err := s.r.Check.PingStart(ctx, ping.CheckId, ping.Date)
+		if err != nil {
+			return err
+		}
+
+		if check.Status == entity.CheckPaused {
+			return s.r.Flip.Create(ctx, entity.CreateFlip{
+				To:      entity.FlipResumed,
+				Date:    ping.Date,
+				CheckId: ping.CheckId,
+			})
+		}
+	case entity.PingSuccess:
+		next, err := schedule.Next(check.Schedule, ping.Date)
+		if err != nil {
+			return err
+		}
+
+		err = s.r.Check.PingSuccess(ctx, ping.CheckId, ping.Date, next)
+		if err != nil {
+			return err
+		}
+
+		// late check gets back up without flip, since it has not been down. Started check is compared by the status
+		// it had before the run, so finished runs of up checks don't flip
+		if check.StatusBeforeRun != entity.CheckUp && check.StatusBeforeRun != entity.CheckLate {
+			return s.createFlip(ctx, check, entity.CreateFlip{
+				To:      entity.FlipUp,
+				Date:    ping.Date,
+				CheckId: ping.CheckId,
+			})
+		}
+	case entity.PingFail:
+		reason := entity.FlipFail
+		if ping.FastThreshold != nil {
+			reason = entity.FlipTooFast
+		}
+		return s.applyFail(ctx, check, ping, reason)
+	}
+
+	return nil
+}
+
+// applyFail applies fail ping to check, the check goes down for the reason when its failure threshold is reached
+func (s *service) applyFail(
+	ctx context.Context,
+	check entity.CheckForPing,
+	ping entity.CreatePing,
+	reason entity.FlipReason,
+) error {
+	// failures below the threshold are counted only, check goes down when the threshold is reached
+	if check.StatusBeforeRun != entity.CheckDown && check.Failures+1 < check.FailureThreshold {
+		next, err := schedule.Next(check.Schedule, ping.Date)
+		if err != nil {
+			return err
 		}
 
-		err = s.r.Ping.Create(ctx, ping)
+		err = s.r.Check.PingFailBelowThreshold(ctx, ping.CheckId, ping.Date, next)
 		if err != nil {
 			return err
 		}
 
+		if check.Status == entity.CheckPaused {
+			return s.r.Flip.Create(ctx, entity.CreateFlip{
+				To:      entity.FlipResumed,
+				Date:    ping.Date,
+				CheckId: ping.CheckId,
+			})
+		}
 		return nil
+	}
+
+	err := s.r.Check.PingFail(ctx, ping.CheckId, ping.Date)
+	if err != nil {
+		return err
+	}
+
+	if check.StatusBeforeRun != entity.CheckDown {
+		return s.createFlip(ctx, check, entity.CreateFlip{
+			To:       entity.FlipDown,
+			Reason:   reason,
+			ExitCode: ping.ExitCode,
+			Date:     ping.Date,
+			CheckId:  ping.CheckId,
+		})
+	}
+
+	return nil
+}
+
+// applyFleetPing updates state of the ping's instance and applies the ping to fleet check: the check is up while
+// the fleet tolerates its down instances and goes down otherwise. Start pings don't change state of instances
+func (s *service) applyFleetPing(ctx context.Context, check entity.CheckForPing, ping entity.CreatePing) error {
+	if ping.Type == entity.PingStart {
+		return s.applyPing(ctx, check, ping)
+	}
+
+	instance := entity.PingInstance{
+		CheckId: ping.CheckId,
+		Name:    ping.Instance,
+		Status:  entity.InstanceDown,
+		Date:    ping.Date,
+	}
+	if ping.Type == entity.PingSuccess {
+		next, err := schedule.Next(check.Schedule, ping.Date)
+		if err != nil {
+			return err
+		}
+		instance.Status = entity.InstanceUp
+		instance.NextPing = &next
+	}
+	err := s.r.Instance.Ping(ctx, instance)
+	if err != nil {
+		return err
+	}
+
+	instances, err := s.r.Instance.GetMany(ctx, ping.CheckId)
+	if err != nil {
+		return err
+	}
+	if check.Fleet.Healthy(instances) {
+		ping.Type = entity.PingSuccess
+		return s.applyPing(ctx, check, ping)
+	}
+
+	// failure of the instance itself is reported as usual, success ping of one instance doesn't make up for
+	// the others
+	if ping.Type == entity.PingFail {
+		return s.applyPing(ctx, check, ping)
+	}
+	ping.Type = entity.PingFail
+	return s.applyFail(ctx, check, ping, entity.FlipInstancesMissing)
+}
+
+// slowThreshold returns duration in seconds which check's runs are slow if they exceed, or nil if the check has no
+// threshold. Threshold relative to p95 duration is used when the check has enough recent runs only
+func (s *service) slowThreshold(ctx context.Context, slow entity.SlowRun, ping entity.CreatePing) (*int, error) {
+	if slow.SlowDuration != nil {
+		return slow.SlowDuration, nil
+	}
+	if slow.SlowFactor == nil {
+		return nil, nil
+	}
+
+	stats, err := s.r.Ping.GetDurationStats(ctx, entity.GetDurationStats{
+		CheckId: ping.CheckId,
+		From:    ping.Date.Add(-slowRunsPeriod),
+		To:      ping.Date,
 	})
+	if err != nil {
+		return nil, err
+	}
+	if stats.Runs < slowRunsMin {
+		return nil, nil
+	}
+
+	threshold := int(math.Round(stats.P95 * *slow.SlowFactor))
+	return &threshold, nil
+}
+
+// createFlip creates status flip of the check, the flip is suppressed if the check is in maintenance or flapping
+func (s *service) createFlip(ctx context.Context, check entity.CheckForPing, flip entity.CreateFlip) error {
+	_, err := incident.Track(ctx, s.r, flip)
+	if err != nil {
+		return err
+	}
+
+	if check.InMaintenance {
+		flip.Suppressed = true
+		return s.r.Flip.Create(ctx, flip)
+	}
+
+	err = flapping.Apply(ctx, s.r, check.FlapDetection, &flip)
+	if err != nil {
+		return err
+	}
+
+	return s.r.Flip.Create(ctx, flip)
 }
@@ -0,0 +1,88 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type BodyRuleMatch string
+
+const (
+	BodyRuleSubstring BodyRuleMatch = "substring"
+	BodyRuleRegex     BodyRuleMatch = "regex"
+)
+
+// BodyRule decides outcome of success and fail pings which body matches the pattern
+type BodyRule struct {
+	Match   BodyRuleMatch `json:"match"`
+	Pattern string        `json:"pattern"`
+	Outcome PingKind      `json:"outcome"`
+}
+
+// Matches reports whether the body matches the rule. Regex patterns are validated when rules are set, so invalid
+// pattern matches nothing
+func (r BodyRule) Matches(body string) bool {
+	switch r.Match {
+	case BodyRuleSubstring:
+		return strings.Contains(body, r.Pattern)
+	case BodyRuleRegex:
+		re, err := regexp.Compile(r.Pattern)
+		return err == nil && re.MatchString(body)
+	default:
+		return false
+	}
+}
+
+// Scan converts the data returned from the DB into the rule.
+func (r *BodyRule) Scan(v interface{}) error {
+	switch vv := v.(type) {
+	case []byte:
+		return json.Unmarshal(vv, r)
+	case string:
+		return json.Unmarshal([]byte(vv), r)
+	default:
+		return fmt.Errorf("unsupported type: %T", v)
+	}
+}
+
+// Value converts the rule into the data stored in the DB.
+func (r BodyRule) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+// BodyRules are check's rules in order of evaluation
+type BodyRules []BodyRule
+
+// Scan converts the data returned from the DB into the rules.
+func (r *BodyRules) Scan(v interface{}) error {
+	switch vv := v.(type) {
+	case []byte:
+		return json.Unmarshal(vv, r)
+	case string:
+		return json.Unmarshal([]byte(vv), r)
+	case nil:
+		// check without rules
+		*r = nil
+		return nil
+	default:
+		return fmt.Errorf("unsupported type: %T", v)
+	}
+}
+
+// Decide returns the first rule which matches the body, or nil if there is none
+func (r BodyRules) Decide(body string) *BodyRule {
+	for i := range r {
+		if r[i].Matches(body) {
+			return &r[i]
+		}
+	}
+	return nil
+}
+
+type SetBodyRules struct {
+	CheckId string
+	Rules   BodyRules
+}
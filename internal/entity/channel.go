@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"database/sql/driver"
 	"encoding/json"
 	"fmt"
 )
@@ -8,25 +9,82 @@ import (
 type ChannelKind string
 
 const (
-	EmailChannel   ChannelKind = "email"
-	WebhookChannel ChannelKind = "webhook"
+	EmailChannel     ChannelKind = "email"
+	WebhookChannel   ChannelKind = "webhook"
+	SlackChannel     ChannelKind = "slack"
+	TelegramChannel  ChannelKind = "telegram"
+	PagerDutyChannel ChannelKind = "pagerduty"
 )
 
 type Channel struct {
-	Id             int         `db:"id"`
-	Kind           ChannelKind `db:"kind"`
-	Email          string      `db:"email"`
-	WebhookURLUp   string      `db:"webhook_url_up"`
-	WebhookURLDown string      `db:"webhook_url_down"`
-	UserId         int         `db:"user_id"`
+	Id                  int         `db:"id"`
+	Kind                ChannelKind `db:"kind"`
+	Email               string      `db:"email"`
+	WebhookURLUp        string      `db:"webhook_url_up"`
+	WebhookURLDown      string      `db:"webhook_url_down"`
+	SlackWebhookURL     string      `db:"slack_webhook_url"`
+	TelegramBotToken    string      `db:"telegram_bot_token"`
+	TelegramChatId      string      `db:"telegram_chat_id"`
+	PagerDutyRoutingKey string      `db:"pagerduty_routing_key"`
+	NotifyLate          bool        `db:"notify_late"`
+	ReminderInterval    *int        `db:"reminder_interval"`
+	UserId              int         `db:"user_id"`
+	WebhookRequest
+}
+
+// WebhookRequest defines request which webhook channel sends. URL replaces up and down URLs of the channel for all
+// notifications if set, and Body is a text/template filled in from the notification
+type WebhookRequest struct {
+	URL     string         `db:"webhook_url"`
+	Method  string         `db:"webhook_method"`
+	Headers WebhookHeaders `db:"webhook_headers"`
+	Body    string         `db:"webhook_body"`
+}
+
+// WebhookHeaders are custom headers of webhook requests
+type WebhookHeaders map[string]string
+
+// Scan converts the data returned from the DB into the headers.
+func (h *WebhookHeaders) Scan(v interface{}) error {
+	switch vv := v.(type) {
+	case []byte:
+		return json.Unmarshal(vv, h)
+	case string:
+		return json.Unmarshal([]byte(vv), h)
+	case nil:
+		// channel without headers
+		*h = nil
+		return nil
+	default:
+		return fmt.Errorf("unsupported type: %T", v)
+	}
+}
+
+// Value converts the headers into the data stored in the DB.
+func (h WebhookHeaders) Value() (driver.Value, error) {
+	if len(h) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(h)
 }
 
 type ChannelShort struct {
-	Id             int         `db:"id" json:"id"`
-	Kind           ChannelKind `db:"kind" json:"kind"`
-	Email          *string     `db:"email" json:"email"`
-	WebhookURLUp   *string     `db:"webhook_url_up" json:"webhook_url_up"`
-	WebhookURLDown *string     `db:"webhook_url_down" json:"webhook_url_down"`
+	Id                  int            `db:"id" json:"id"`
+	Kind                ChannelKind    `db:"kind" json:"kind"`
+	Email               *string        `db:"email" json:"email"`
+	WebhookURLUp        *string        `db:"webhook_url_up" json:"webhook_url_up"`
+	WebhookURLDown      *string        `db:"webhook_url_down" json:"webhook_url_down"`
+	WebhookURL          *string        `db:"webhook_url" json:"webhook_url"`
+	WebhookMethod       string         `db:"webhook_method" json:"webhook_method"`
+	WebhookHeaders      WebhookHeaders `db:"webhook_headers" json:"webhook_headers"`
+	WebhookBody         *string        `db:"webhook_body" json:"webhook_body"`
+	WebhookSecret       string         `db:"webhook_secret" json:"webhook_secret"`
+	SlackWebhookURL     *string        `db:"slack_webhook_url" json:"slack_webhook_url"`
+	TelegramBotToken    *string        `db:"telegram_bot_token" json:"telegram_bot_token"`
+	TelegramChatId      *string        `db:"telegram_chat_id" json:"telegram_chat_id"`
+	PagerDutyRoutingKey *string        `db:"pagerduty_routing_key" json:"pagerduty_routing_key"`
+	NotifyLate          bool           `db:"notify_late" json:"notify_late"`
+	ReminderInterval    *int           `db:"reminder_interval" json:"reminder_interval"`
 }
 
 type Channels []ChannelShort
@@ -38,17 +96,33 @@ func (c *Channels) Scan(v interface{}) error {
 		return json.Unmarshal(vv, c)
 	case string:
 		return json.Unmarshal([]byte(vv), c)
+	case nil:
+		// check without channels
+		*c = nil
+		return nil
 	default:
 		return fmt.Errorf("unsupported type: %T", v)
 	}
 }
 
 type CreateChannel struct {
-	Kind           ChannelKind
-	Email          string
-	WebhookURLUp   string
-	WebhookURLDown string
-	UserId         int
+	Kind                ChannelKind
+	Email               string
+	WebhookURLUp        string
+	WebhookURLDown      string
+	SlackWebhookURL     string
+	TelegramBotToken    string
+	TelegramChatId      string
+	PagerDutyRoutingKey string
+	NotifyLate          bool
+	ReminderInterval    *int
+	UserId              int
+	WebhookRequest
+}
+
+type RotateWebhookSecret struct {
+	Id     int
+	UserId int
 }
 
 type DeleteChannel struct {
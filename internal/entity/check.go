@@ -12,19 +12,147 @@ const (
 	CheckUp      CheckStatus = "up"
 	CheckDown    CheckStatus = "down"
 	CheckPaused  CheckStatus = "paused"
+	// CheckLate means that next ping time has passed, but grace period has not expired yet
+	CheckLate CheckStatus = "late"
+)
+
+type ScheduleKind string
+
+const (
+	ScheduleSimple ScheduleKind = "simple"
+	ScheduleCron   ScheduleKind = "cron"
+)
+
+// Schedule defines when check's pings are expected. Simple schedule expects a ping every Interval seconds,
+// cron schedule expects a ping at every run of Cron expression in Timezone.
+type Schedule struct {
+	Kind     ScheduleKind `db:"schedule"`
+	Interval int          `db:"interval"`
+	Cron     string       `db:"cron"`
+	Timezone string       `db:"timezone"`
+}
+
+// FlapDetection defines when check is flapping: it has more than FlapThreshold flips within FlapWindow seconds.
+// Detection is disabled if FlapThreshold is nil
+type FlapDetection struct {
+	FlapThreshold *int       `db:"flap_threshold"`
+	FlapWindow    int        `db:"flap_window"`
+	FlappingSince *time.Time `db:"flapping_since"`
+}
+
+// SlowRun defines when finished run is slow: it has lasted longer than SlowDuration seconds, or longer than
+// SlowFactor times p95 duration of the check's recent runs. Detection is disabled if both are nil
+type SlowRun struct {
+	SlowDuration *int     `db:"slow_duration"`
+	SlowFactor   *float64 `db:"slow_factor"`
+}
+
+// MinDurationAction defines what a run which has finished faster than check's min duration does to the check
+type MinDurationAction string
+
+const (
+	// MinDurationFail fails the check as if the run reported a failure
+	MinDurationFail MinDurationAction = "fail"
+	// MinDurationWarn leaves the check up and warns users about the run
+	MinDurationWarn MinDurationAction = "warn"
+)
+
+// MinRun defines when successful run is too fast: it has lasted less than MinDuration seconds. Detection is
+// disabled if MinDuration is nil
+type MinRun struct {
+	MinDuration       *int              `db:"min_duration"`
+	MinDurationAction MinDurationAction `db:"min_duration_action"`
+}
+
+// Fleet defines check which is pinged by multiple instances, e.g. the same job running on many hosts. Instances are
+// told apart by client-supplied instance name or ping source, and the check goes down when more than FleetTolerance
+// of them are down. Instances which haven't pinged for InstanceTTL seconds are forgotten
+type Fleet struct {
+	Fleet          bool `db:"fleet"`
+	FleetTolerance int  `db:"fleet_tolerance"`
+	InstanceTTL    int  `db:"instance_ttl"`
+}
+
+// Healthy reports whether the fleet tolerates its down instances
+func (f Fleet) Healthy(instances Instances) bool {
+	return instances.Down() <= f.FleetTolerance
+}
+
+// CheckSeverity is severity of check's incidents reported to incident management services like PagerDuty
+type CheckSeverity string
+
+const (
+	SeverityCritical CheckSeverity = "critical"
+	SeverityError    CheckSeverity = "error"
+	SeverityWarning  CheckSeverity = "warning"
+	SeverityInfo     CheckSeverity = "info"
+)
+
+// PausePolicy defines what a ping does to a paused check
+type PausePolicy string
+
+const (
+	// PauseResume resumes paused check and applies the ping to it
+	PauseResume PausePolicy = "resume"
+	// PauseIgnore stores the ping but leaves the check paused
+	PauseIgnore PausePolicy = "ignore"
+)
+
+// Defaults of the optional check settings, they match defaults of the checks table columns
+const (
+	DefaultTimezone          = "UTC"
+	DefaultFailureThreshold  = 1
+	DefaultFlapPeriod        = 3600 // seconds, both window and stable period
+	DefaultReminderLimit     = 10
+	DefaultInstanceTTL       = 86400 // seconds
+	DefaultMinDurationAction = MinDurationFail
+	DefaultSeverity          = SeverityCritical
+	DefaultPausePolicy       = PauseResume
 )
 
 type Check struct {
-	Id          string      `db:"id"`
-	Name        string      `db:"name"`
-	Description string      `db:"description"`
-	Interval    int         `db:"interval"`
-	Grace       int         `db:"grace"`
-	LastPing    *time.Time  `db:"last_ping"`
-	NextPing    *time.Time  `db:"next_ping"`
-	LastStarted *time.Time  `db:"last_started"`
-	Status      CheckStatus `db:"status"`
-	Channels    Channels    `db:"channels"`
+	Id                 string            `db:"id"`
+	Name               string            `db:"name"`
+	Slug               *string           `db:"slug"`
+	Description        string            `db:"description"`
+	Tags               Tags              `db:"tags"`
+	Schedule           ScheduleKind      `db:"schedule"`
+	Interval           int               `db:"interval"`
+	Cron               string            `db:"cron"`
+	Timezone           string            `db:"timezone"`
+	Grace              int               `db:"grace"`
+	MaxDuration        *int              `db:"max_duration"`
+	FirstPingDeadline  *int              `db:"first_ping_deadline"`
+	FailureThreshold   int               `db:"failure_threshold"`
+	Failures           int               `db:"failures"`
+	FlapThreshold      *int              `db:"flap_threshold"`
+	FlapWindow         int               `db:"flap_window"`
+	FlapStable         int               `db:"flap_stable"`
+	FlappingSince      *time.Time        `db:"flapping_since"`
+	EscalationPolicyId *int              `db:"escalation_policy_id"`
+	ReminderInterval   *int              `db:"reminder_interval"`
+	ReminderLimit      int               `db:"reminder_limit"`
+	RemindersStoppedAt *time.Time        `db:"reminders_stopped_at"`
+	SlowDuration       *int              `db:"slow_duration"`
+	SlowFactor         *float64          `db:"slow_factor"`
+	MinDuration        *int              `db:"min_duration"`
+	MinDurationAction  MinDurationAction `db:"min_duration_action"`
+	Fleet              bool              `db:"fleet"`
+	FleetTolerance     int               `db:"fleet_tolerance"`
+	InstanceTTL        int               `db:"instance_ttl"`
+	Severity           CheckSeverity     `db:"severity"`
+	CreatedAt          time.Time         `db:"created_at"`
+	PausePolicy        PausePolicy       `db:"pause_policy"`
+	ResumeAt           *time.Time        `db:"resume_at"`
+	LastPing           *time.Time        `db:"last_ping"`
+	NextPing           *time.Time        `db:"next_ping"`
+	LastStarted        *time.Time        `db:"last_started"`
+	Status             CheckStatus       `db:"status"`
+	Channels           Channels          `db:"channels"`
+	BodyRules          BodyRules         `db:"body_rules"`
+	Metrics            Metrics           `db:"metrics"`
+	MetricRules        MetricRules       `db:"metric_rules"`
+	Instances          Instances         `db:"instances"`
 }
 
 type GetCheck struct {
@@ -33,20 +161,73 @@ type GetCheck struct {
 }
 
 type CreateCheck struct {
-	UserId      int
-	Name        string
-	Description string
-	Interval    int
-	Grace       int
+	UserId             int
+	Name               string
+	Slug               *string
+	Description        string
+	Tags               Tags
+	Schedule           ScheduleKind
+	Interval           int
+	Cron               string
+	Timezone           string
+	Grace              int
+	MaxDuration        *int
+	FirstPingDeadline  *int
+	FailureThreshold   int
+	FlapThreshold      *int
+	FlapWindow         int
+	FlapStable         int
+	EscalationPolicyId *int
+	ReminderInterval   *int
+	ReminderLimit      int
+	SlowDuration       *int
+	SlowFactor         *float64
+	MinDuration        *int
+	MinDurationAction  MinDurationAction
+	Fleet              bool
+	FleetTolerance     int
+	InstanceTTL        int
+	Severity           CheckSeverity
+	PausePolicy        PausePolicy
 }
 
 type UpdateCheck struct {
-	Id          string
-	UserId      int
-	Name        string
-	Description string
-	Interval    int
-	Grace       int
+	Id                 string
+	UserId             int
+	Name               string
+	Slug               *string
+	Description        string
+	Tags               Tags
+	Schedule           ScheduleKind
+	Interval           int
+	Cron               string
+	Timezone           string
+	Grace              int
+	MaxDuration        *int
+	FirstPingDeadline  *int
+	FailureThreshold   int
+	FlapThreshold      *int
+	FlapWindow         int
+	FlapStable         int
+	EscalationPolicyId *int
+	ReminderInterval   *int
+	ReminderLimit      int
+	SlowDuration       *int
+	SlowFactor         *float64
+	MinDuration        *int
+	MinDurationAction  MinDurationAction
+	Fleet              bool
+	FleetTolerance     int
+	InstanceTTL        int
+	Severity           CheckSeverity
+	PausePolicy        PausePolicy
+}
+
+// PingRules are check's rules which decide outcome of its pings and extract metrics from them
+type PingRules struct {
+	Body        BodyRules
+	Metrics     Metrics
+	MetricRules MetricRules
 }
 
 type DeleteCheck struct {
@@ -60,16 +241,96 @@ type SetCheckStatus struct {
 	Status CheckStatus
 }
 
+type GetCheckBySlug struct {
+	PingKey string
+	Slug    string
+}
+
+type StopReminders struct {
+	Id     string
+	UserId int
+}
+
+type PauseCheck struct {
+	Id       string
+	UserId   int
+	ResumeAt *time.Time
+}
+
+// CheckDetails are check's data added to its notifications
+type CheckDetails struct {
+	Id          string        `db:"id"`
+	Description string        `db:"description"`
+	Tags        Tags          `db:"tags"`
+	Severity    CheckSeverity `db:"severity"`
+}
+
+type CheckForPing struct {
+	Status           CheckStatus `db:"status"`
+	StatusBeforeRun  CheckStatus `db:"status_before_run"` // status the check had before the current run started
+	PausePolicy      PausePolicy `db:"pause_policy"`
+	FailureThreshold int         `db:"failure_threshold"`
+	Failures         int         `db:"failures"`
+	InMaintenance    bool        `db:"in_maintenance"`
+	BodyRules        BodyRules   `db:"body_rules"`
+	Metrics          Metrics     `db:"metrics"`
+	MetricRules      MetricRules `db:"metric_rules"`
+	Schedule
+	FlapDetection
+	SlowRun
+	MinRun
+	Fleet
+}
+
+type CheckToResume struct {
+	Id string `db:"id"`
+	Schedule
+}
+
 type AddChannels struct {
 	Id       string
 	Channels []int
 }
 
+type CheckRunningLate struct {
+	Id            string    `db:"id"`
+	Name          string    `db:"name"`
+	NextPing      time.Time `db:"next_ping"`
+	InMaintenance bool      `db:"in_maintenance"`
+	Channels      Channels  `db:"channels"`
+}
+
+type CheckStable struct {
+	Id     string      `db:"id"`
+	Status CheckStatus `db:"status"`
+}
+
 type CheckExpired struct {
-	Id        string     `db:"id"`
-	Name      string     `db:"name"`
-	Grace     int        `db:"grace"`
-	NextPing  *time.Time `db:"next_ping"`
-	UserEmail string     `db:"email"`
-	Channels  Channels   `db:"channels"`
+	Id               string     `db:"id"`
+	Name             string     `db:"name"`
+	Reason           FlipReason `db:"reason"`
+	ExpiredAt        time.Time  `db:"expired_at"`
+	NextPing         *time.Time `db:"next_ping"`
+	FailureThreshold int        `db:"failure_threshold"`
+	Failures         int        `db:"failures"`
+	InMaintenance    bool       `db:"in_maintenance"`
+	Channels         Channels   `db:"channels"`
+	Schedule
+	FlapDetection
+}
+
+// ReachesThreshold reports whether the check's expiration is the failure which reaches its failure threshold.
+// Check that has never been pinged goes down immediately, since it has no periods to count
+func (c CheckExpired) ReachesThreshold() bool {
+	return c.Reason == FlipNoFirstPing || c.Failures+1 >= c.FailureThreshold
+}
+
+// CheckReminder is "still down" reminder of check which is sent to one of its channels
+type CheckReminder struct {
+	CheckId   string    `db:"id"`
+	Name      string    `db:"name"`
+	DownSince time.Time `db:"down_since"`
+	Reminder  int       `db:"reminders"` // number of the reminder
+	Limit     int       `db:"reminder_limit"`
+	Channels  Channels  `db:"channels"`
 }
@@ -0,0 +1,85 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// EscalationPolicy notifies its steps' channels one by one while check stays down. Each step is notified
+// Delay seconds after the check went down, and check's channels are not notified about the down flip. Steps
+// notified already are notified when the check is up again
+type EscalationPolicy struct {
+	Id    int             `db:"id"`
+	Name  string          `db:"name"`
+	Steps EscalationSteps `db:"steps"`
+}
+
+type EscalationStep struct {
+	Delay     int `json:"delay"`
+	ChannelId int `json:"channel_id"`
+}
+
+type EscalationSteps []EscalationStep
+
+// Scan converts the data returned from the DB into the struct.
+func (s *EscalationSteps) Scan(v interface{}) error {
+	switch vv := v.(type) {
+	case []byte:
+		return json.Unmarshal(vv, s)
+	case string:
+		return json.Unmarshal([]byte(vv), s)
+	case nil:
+		// policy without steps
+		*s = nil
+		return nil
+	default:
+		return fmt.Errorf("unsupported type: %T", v)
+	}
+}
+
+type CreateEscalationPolicy struct {
+	UserId int
+	Name   string
+}
+
+type UpdateEscalationPolicy struct {
+	Id     int
+	UserId int
+	Name   string
+}
+
+type DeleteEscalationPolicy struct {
+	Id     int
+	UserId int
+}
+
+type GetEscalationPolicy struct {
+	Id     int
+	UserId int
+}
+
+type SetEscalationSteps struct {
+	Id     int
+	UserId int
+	Steps  []EscalationStep
+}
+
+// EscalationStepDue is a step of open escalation which delay has passed
+type EscalationStepDue struct {
+	EscalationId int        `db:"escalation_id"`
+	Position     int        `db:"position"`
+	CheckId      string     `db:"check_id"`
+	CheckName    string     `db:"name"`
+	FlipReason   FlipReason `db:"reason"`
+	ExitCode     *int       `db:"exit_code"`
+	FlipDate     time.Time  `db:"date"`
+	IncidentId   *int       `db:"incident_id"` // incident opened by the down flip
+	Channels     Channels   `db:"channels"`    // channel of the step
+}
+
+// EscalationClosed is a closed escalation of check with channels of its steps that were notified already
+type EscalationClosed struct {
+	CheckId  string   `db:"check_id"`
+	Channels Channels `db:"channels"`
+}
@@ -5,15 +5,37 @@ import "time"
 type FlipState string
 
 const (
-	FlipUp     FlipState = "up"
-	FlipDown   FlipState = "down"
-	FlipPaused FlipState = "paused"
+	FlipUp      FlipState = "up"
+	FlipDown    FlipState = "down"
+	FlipPaused  FlipState = "paused"
+	FlipResumed FlipState = "resumed"
+	// FlipFlapping starts flapping period of a check, FlipStable ends it
+	FlipFlapping FlipState = "flapping"
+	FlipStable   FlipState = "stable"
+)
+
+// FlipReason describes why check went down
+type FlipReason string
+
+const (
+	FlipMissed  FlipReason = "missed"
+	FlipFail    FlipReason = "fail"
+	FlipTimeout FlipReason = "timeout"
+	// FlipNoFirstPing means that new check has not received its first ping before the deadline
+	FlipNoFirstPing FlipReason = "no_first_ping"
+	// FlipTooFast means that the run has finished faster than check's min duration, so it has likely done nothing
+	FlipTooFast FlipReason = "too_fast"
+	// FlipInstancesMissing means that more instances of fleet check are down than the check tolerates
+	FlipInstancesMissing FlipReason = "instances_missing"
 )
 
 type CreateFlip struct {
-	To      FlipState
-	Date    time.Time
-	CheckId string
+	To         FlipState
+	Reason     FlipReason // empty for flips without reason
+	ExitCode   *int       // exit code of the failed run, nil if not reported
+	Suppressed bool       // suppressed flips are recorded, but users are not notified about them
+	Date       time.Time
+	CheckId    string
 }
 
 type GetFlipsTotal struct {
@@ -31,15 +53,35 @@ type GetFlips struct {
 }
 
 type Flip struct {
-	To   FlipState `db:"to"`
-	Date time.Time `db:"date"`
+	To         FlipState  `db:"to"`
+	Reason     FlipReason `db:"reason"`
+	ExitCode   *int       `db:"exit_code"`
+	Suppressed bool       `db:"suppressed"`
+	Date       time.Time  `db:"date"`
 }
 
 type FlipUnprocessed struct {
-	Id            int       `db:"id"`
-	To            FlipState `db:"to"`
-	Date          time.Time `db:"date"`
-	CheckName     string    `db:"name"`
-	UserEmail     string    `db:"email"`
-	CheckChannels Channels  `db:"channels"`
+	Id            int         `db:"id"`
+	To            FlipState   `db:"to"`
+	Reason        FlipReason  `db:"reason"`
+	ExitCode      *int        `db:"exit_code"`
+	Date          time.Time   `db:"date"`
+	CheckId       string      `db:"check_id"`
+	CheckName     string      `db:"name"`
+	CheckStatus   CheckStatus `db:"status"`
+	IncidentId    *int        `db:"incident_id"`
+	CheckChannels Channels    `db:"channels"`
+}
+
+type GetFlipChanges struct {
+	CheckIds []string
+	From     time.Time
+	To       time.Time
+}
+
+// FlipChange is flip which changes check's state: up, down, paused or resumed
+type FlipChange struct {
+	CheckId string    `db:"check_id"`
+	To      FlipState `db:"to"`
+	Date    time.Time `db:"date"`
 }
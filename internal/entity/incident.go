@@ -0,0 +1,87 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Incident is a period during which check was down. It starts with down flip and ends with the next up flip
+type Incident struct {
+	Id             int           `db:"id"`
+	CheckId        string        `db:"check_id"`
+	CheckName      string        `db:"check_name"`
+	UserId         int           `db:"user_id"`
+	Cause          FlipReason    `db:"cause"`
+	StartedAt      time.Time     `db:"started_at"`
+	EndedAt        *time.Time    `db:"ended_at"`
+	Duration       *int          `db:"duration"` // seconds, nil if incident hasn't ended yet
+	AcknowledgedAt *time.Time    `db:"acknowledged_at"`
+	AcknowledgedBy *string       `db:"acknowledged_by"`
+	Notes          IncidentNotes `db:"notes"`
+}
+
+type IncidentNote struct {
+	Text      string    `json:"text"`
+	Author    string    `json:"author"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type IncidentNotes []IncidentNote
+
+// Scan converts the data returned from the DB into the struct.
+func (n *IncidentNotes) Scan(v interface{}) error {
+	switch vv := v.(type) {
+	case []byte:
+		return json.Unmarshal(vv, n)
+	case string:
+		return json.Unmarshal([]byte(vv), n)
+	case nil:
+		// incident without notes
+		*n = nil
+		return nil
+	default:
+		return fmt.Errorf("unsupported type: %T", v)
+	}
+}
+
+type OpenIncident struct {
+	CheckId   string
+	Cause     FlipReason
+	StartedAt time.Time
+}
+
+type CloseIncident struct {
+	CheckId string
+	EndedAt time.Time
+}
+
+// GetIncidentsTotal and GetIncidents filter incidents of the check or, if CheckId is empty, of all user's checks
+type GetIncidentsTotal struct {
+	CheckId string
+	UserId  int
+	From    time.Time
+	To      time.Time
+}
+
+type GetIncidents struct {
+	CheckId string
+	UserId  int
+	From    time.Time
+	To      time.Time
+	Limit   int
+	Offset  int
+}
+
+type AcknowledgeIncident struct {
+	Id     int
+	UserId int    // zero if incident is acknowledged by signed link
+	By     string // email of the user or recipient of the link
+}
+
+type AddIncidentNote struct {
+	Id     int
+	UserId int // zero if note is added by signed link
+	Text   string
+	Author string
+}
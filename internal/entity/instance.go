@@ -0,0 +1,69 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// InstanceStatus is status of one of fleet check's instances
+type InstanceStatus string
+
+const (
+	InstanceUp InstanceStatus = "up"
+	// InstanceDown means that the instance has reported a failure or missed its deadline
+	InstanceDown InstanceStatus = "down"
+)
+
+// Instance is one of the sources pinging fleet check. NextPing is nil if the instance is down
+type Instance struct {
+	Name     string         `db:"name" json:"name"`
+	Status   InstanceStatus `db:"status" json:"status"`
+	LastPing time.Time      `db:"last_ping" json:"last_ping"`
+	NextPing *time.Time     `db:"next_ping" json:"next_ping"`
+}
+
+type Instances []Instance
+
+// Scan converts the data returned from the DB into the instances.
+func (i *Instances) Scan(v interface{}) error {
+	switch vv := v.(type) {
+	case []byte:
+		return json.Unmarshal(vv, i)
+	case string:
+		return json.Unmarshal([]byte(vv), i)
+	case nil:
+		// check without instances
+		*i = nil
+		return nil
+	default:
+		return fmt.Errorf("unsupported type: %T", v)
+	}
+}
+
+// Down returns number of down instances
+func (i Instances) Down() int {
+	down := 0
+	for _, instance := range i {
+		if instance.Status == InstanceDown {
+			down++
+		}
+	}
+	return down
+}
+
+// PingInstance is state of instance after its ping. NextPing is nil for fail pings
+type PingInstance struct {
+	CheckId  string
+	Name     string
+	Status   InstanceStatus
+	Date     time.Time
+	NextPing *time.Time
+}
+
+// InstanceMissed is instance which has missed its deadline at ExpiredAt
+type InstanceMissed struct {
+	CheckId   string    `db:"check_id"`
+	Name      string    `db:"name"`
+	ExpiredAt time.Time `db:"expired_at"`
+}
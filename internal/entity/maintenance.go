@@ -0,0 +1,114 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// MaintenanceWindow silences notifications of its checks and checks with its tags. One-off window starts at
+// StartsAt, recurring one starts at every run of Cron expression in Timezone. Both last Duration seconds.
+// NextStart and NextEnd define the current or the upcoming period of the window, they are nil if one-off
+// window has ended
+type MaintenanceWindow struct {
+	Id        int        `db:"id"`
+	Name      string     `db:"name"`
+	StartsAt  *time.Time `db:"starts_at"`
+	Cron      string     `db:"cron"`
+	Timezone  string     `db:"timezone"`
+	Duration  int        `db:"duration"`
+	Tags      Tags       `db:"tags"`
+	Checks    CheckIds   `db:"checks"`
+	NextStart *time.Time `db:"next_start"`
+	NextEnd   *time.Time `db:"next_end"`
+}
+
+// Recurring reports whether the window recurs on cron schedule
+func (w MaintenanceWindow) Recurring() bool {
+	return w.Cron != ""
+}
+
+type CheckIds []string
+
+// Scan converts the data returned from the DB into the struct.
+func (c *CheckIds) Scan(v interface{}) error {
+	switch vv := v.(type) {
+	case []byte:
+		return json.Unmarshal(vv, c)
+	case string:
+		return json.Unmarshal([]byte(vv), c)
+	case nil:
+		*c = nil
+		return nil
+	default:
+		return fmt.Errorf("unsupported type: %T", v)
+	}
+}
+
+type CreateMaintenanceWindow struct {
+	UserId    int
+	Name      string
+	StartsAt  *time.Time
+	Cron      string
+	Timezone  string
+	Duration  int
+	Tags      Tags
+	NextStart time.Time
+	NextEnd   time.Time
+}
+
+type UpdateMaintenanceWindow struct {
+	Id        int
+	UserId    int
+	Name      string
+	StartsAt  *time.Time
+	Cron      string
+	Timezone  string
+	Duration  int
+	Tags      Tags
+	NextStart time.Time
+	NextEnd   time.Time
+}
+
+type DeleteMaintenanceWindow struct {
+	Id     int
+	UserId int
+}
+
+type SetMaintenanceChecks struct {
+	Id     int
+	UserId int
+	Checks []string
+}
+
+// MaintenanceFlip is a flip suppressed during maintenance window
+type MaintenanceFlip struct {
+	CheckName string    `json:"check_name"`
+	To        FlipState `json:"to"`
+	Date      time.Time `json:"date"`
+}
+
+type MaintenanceFlips []MaintenanceFlip
+
+// Scan converts the data returned from the DB into the struct.
+func (f *MaintenanceFlips) Scan(v interface{}) error {
+	switch vv := v.(type) {
+	case []byte:
+		return json.Unmarshal(vv, f)
+	case string:
+		return json.Unmarshal([]byte(vv), f)
+	case nil:
+		// no flips during the window
+		*f = nil
+		return nil
+	default:
+		return fmt.Errorf("unsupported type: %T", v)
+	}
+}
+
+// MaintenanceWindowEnded is a window which period has ended with the flips suppressed during the period
+type MaintenanceWindowEnded struct {
+	MaintenanceWindow
+	UserEmail string           `db:"email"`
+	Flips     MaintenanceFlips `db:"flips"`
+}
@@ -0,0 +1,168 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"github.com/tidwall/gjson"
+	"time"
+)
+
+// Metric is a number which is extracted from JSON body of check's pings by the path in gjson syntax,
+// e.g. "stats.rows"
+type Metric struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+type Metrics []Metric
+
+// Scan converts the data returned from the DB into the metrics.
+func (m *Metrics) Scan(v interface{}) error {
+	switch vv := v.(type) {
+	case []byte:
+		return json.Unmarshal(vv, m)
+	case string:
+		return json.Unmarshal([]byte(vv), m)
+	case nil:
+		// check without metrics
+		*m = nil
+		return nil
+	default:
+		return fmt.Errorf("unsupported type: %T", v)
+	}
+}
+
+// Extract returns values of the metrics found in the body by their names. Body which is not JSON has no values,
+// and values which are not numbers are skipped
+func (m Metrics) Extract(body string) map[string]float64 {
+	if len(m) == 0 || !gjson.Valid(body) {
+		return nil
+	}
+
+	values := make(map[string]float64)
+	for _, metric := range m {
+		result := gjson.Get(body, metric.Path)
+		if result.Type == gjson.Number {
+			values[metric.Name] = result.Num
+		}
+	}
+
+	return values
+}
+
+type MetricOp string
+
+const (
+	MetricLt  MetricOp = "lt"
+	MetricLte MetricOp = "lte"
+	MetricGt  MetricOp = "gt"
+	MetricGte MetricOp = "gte"
+	MetricEq  MetricOp = "eq"
+	MetricNe  MetricOp = "ne"
+)
+
+// MetricRule decides outcome of success and fail pings which value of the metric compared with Threshold by Op
+// is true, e.g. rows lt 1000 fails the ping
+type MetricRule struct {
+	Metric    string   `json:"metric"`
+	Op        MetricOp `json:"op"`
+	Threshold float64  `json:"threshold"`
+	Outcome   PingKind `json:"outcome"`
+}
+
+// Matches reports whether the values match the rule. Rule of the metric which has no value matches nothing
+func (r MetricRule) Matches(values map[string]float64) bool {
+	value, ok := values[r.Metric]
+	if !ok {
+		return false
+	}
+
+	switch r.Op {
+	case MetricLt:
+		return value < r.Threshold
+	case MetricLte:
+		return value <= r.Threshold
+	case MetricGt:
+		return value > r.Threshold
+	case MetricGte:
+		return value >= r.Threshold
+	case MetricEq:
+		return value == r.Threshold
+	case MetricNe:
+		return value != r.Threshold
+	default:
+		return false
+	}
+}
+
+// Scan converts the data returned from the DB into the rule.
+func (r *MetricRule) Scan(v interface{}) error {
+	switch vv := v.(type) {
+	case []byte:
+		return json.Unmarshal(vv, r)
+	case string:
+		return json.Unmarshal([]byte(vv), r)
+	default:
+		return fmt.Errorf("unsupported type: %T", v)
+	}
+}
+
+// Value converts the rule into the data stored in the DB.
+func (r MetricRule) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+// MetricRules are check's rules in order of evaluation
+type MetricRules []MetricRule
+
+// Scan converts the data returned from the DB into the rules.
+func (r *MetricRules) Scan(v interface{}) error {
+	switch vv := v.(type) {
+	case []byte:
+		return json.Unmarshal(vv, r)
+	case string:
+		return json.Unmarshal([]byte(vv), r)
+	case nil:
+		// check without rules
+		*r = nil
+		return nil
+	default:
+		return fmt.Errorf("unsupported type: %T", v)
+	}
+}
+
+// Decide returns the first rule which matches the values, or nil if there is none
+func (r MetricRules) Decide(values map[string]float64) *MetricRule {
+	for i := range r {
+		if r[i].Matches(values) {
+			return &r[i]
+		}
+	}
+	return nil
+}
+
+type SetMetrics struct {
+	CheckId string
+	Metrics Metrics
+	Rules   MetricRules
+}
+
+type CreateMetricValues struct {
+	CheckId string
+	Values  map[string]float64
+	Date    time.Time
+}
+
+type GetMetricHistory struct {
+	CheckId string
+	UserId  int
+	Name    string
+	From    time.Time
+	To      time.Time
+}
+
+type MetricPoint struct {
+	Date  time.Time `db:"date"`
+	Value float64   `db:"value"`
+}
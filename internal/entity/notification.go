@@ -7,11 +7,60 @@ type NotificationFlipStatus string
 const (
 	NotificationFlipUp   NotificationFlipStatus = "up"
 	NotificationFlipDown NotificationFlipStatus = "down"
+	// NotificationFlipLate warns that check is running late, it's sent to channels that opt in only
+	NotificationFlipLate NotificationFlipStatus = "late"
+	// NotificationFlipFlapping is sent once when check starts flapping, NotificationFlipStable when it stops
+	NotificationFlipFlapping NotificationFlipStatus = "flapping"
+	NotificationFlipStable   NotificationFlipStatus = "stable"
+	// NotificationFlipStillDown reminds that check is still down, FlipDate is the time when it went down
+	NotificationFlipStillDown NotificationFlipStatus = "still_down"
+	// NotificationSlowRun warns that check's run has lasted longer than its slow run threshold, check's status
+	// doesn't change
+	NotificationSlowRun NotificationFlipStatus = "slow_run"
+	// NotificationFastRun warns that check's run has finished faster than its min duration, check's status
+	// doesn't change
+	NotificationFastRun NotificationFlipStatus = "fast_run"
+	// NotificationMaintenanceEnded summarizes flips suppressed during maintenance window, CheckName is the name
+	// of the window and FlipDate is the end of its period
+	NotificationMaintenanceEnded NotificationFlipStatus = "maintenance_ended"
 )
 
 type Notification struct {
+	CheckId       string
 	CheckName     string
 	FlipTo        NotificationFlipStatus
+	FlipReason    FlipReason
+	ExitCode      *int
 	FlipDate      time.Time
+	CheckStatus   CheckStatus // current status of the check, set for stable notifications
+	IncidentId    *int        // incident opened by down flip, recipients can acknowledge it by link
 	CheckChannels Channels
+	LastPingBody  string `json:",omitempty"` // body of the last ping of the check, truncated
+	// CheckDescription, CheckTags and CheckSeverity are details of the check
+	CheckDescription string        `json:",omitempty"`
+	CheckTags        Tags          `json:",omitempty"`
+	CheckSeverity    CheckSeverity `json:",omitempty"`
+	// MaintenanceStart and MaintenanceFlips are set for maintenance summaries only
+	MaintenanceStart *time.Time       `json:",omitempty"`
+	MaintenanceFlips MaintenanceFlips `json:",omitempty"`
+	// Reminder is the number of "still down" reminder and RemindersLimit is max number of them
+	Reminder       int `json:",omitempty"`
+	RemindersLimit int `json:",omitempty"`
+	// Duration of the slow or too fast run and SlowThreshold it has exceeded or MinDuration it hasn't reached,
+	// in seconds
+	Duration      int `json:",omitempty"`
+	SlowThreshold int `json:",omitempty"`
+	MinDuration   int `json:",omitempty"`
+}
+
+// Status returns status which the notification reports, stable notification reports status the check has
+// settled in
+func (n Notification) Status() NotificationFlipStatus {
+	if n.FlipTo == NotificationFlipStable {
+		if n.CheckStatus == CheckDown {
+			return NotificationFlipDown
+		}
+		return NotificationFlipUp
+	}
+	return n.FlipTo
 }
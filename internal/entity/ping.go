@@ -21,6 +21,15 @@ type CreatePing struct {
 	Body      string
 	Date      time.Time
 	Duration  sql.NullInt32
+	ExitCode  *int    // exit code reported by the job, nil if not reported
+	RunId     *string // client-supplied id pairing start ping with the finishing one, nil if not supplied
+	// SlowThreshold is set if the run finished by the ping has lasted longer than the threshold, FastThreshold is
+	// set if it has lasted less than the threshold, in seconds
+	SlowThreshold *int
+	FastThreshold *int
+	BodyRule      *BodyRule   // rule which has decided outcome of the ping, nil if no rule has matched its body
+	MetricRule    *MetricRule // rule which has decided outcome of the ping, nil if no rule has matched its metrics
+	Instance      string      // instance which has sent the ping, empty for pings of checks which are not fleets
 }
 
 type PingTypeAndDate struct {
@@ -28,6 +37,11 @@ type PingTypeAndDate struct {
 	Date time.Time `db:"date"`
 }
 
+type PingBody struct {
+	CheckId string `db:"check_id"`
+	Body    string `db:"body"`
+}
+
 type GetPingsTotal struct {
 	CheckId string
 	From    time.Time
@@ -50,4 +64,83 @@ type Ping struct {
 	Body      string    `db:"body"`
 	Date      time.Time `db:"date"`
 	Duration  *int      `db:"duration"`
+	ExitCode  *int      `db:"exit_code"`
+	RunId     *string   `db:"run_id"`
+	// SlowThreshold is set if the run finished by the ping was slow, FastThreshold if it was too fast
+	SlowThreshold *int        `db:"slow_threshold"`
+	FastThreshold *int        `db:"fast_threshold"`
+	BodyRule      *BodyRule   `db:"body_rule"`
+	MetricRule    *MetricRule `db:"metric_rule"`
+	Instance      *string     `db:"instance"`
+}
+
+type GetRunsTotal struct {
+	CheckId string
+	From    time.Time
+	To      time.Time
+}
+
+type GetRuns struct {
+	CheckId string
+	From    time.Time
+	To      time.Time
+	Limit   int
+	Offset  int
+}
+
+// Run is a job run started by start ping and finished by the next success or fail ping with the same run id
+// (or without run id for runs without it). End, Outcome and Duration are nil for unfinished runs
+type Run struct {
+	RunId    *string    `db:"run_id"`
+	Start    time.Time  `db:"start"`
+	End      *time.Time `db:"end"`
+	Outcome  *PingKind  `db:"outcome"`
+	Duration *int       `db:"duration"`
+}
+
+type DurationBucket string
+
+const (
+	DurationHour  DurationBucket = "hour"
+	DurationDay   DurationBucket = "day"
+	DurationWeek  DurationBucket = "week"
+	DurationMonth DurationBucket = "month"
+)
+
+type GetDurationStats struct {
+	CheckId string
+	From    time.Time
+	To      time.Time
+}
+
+type GetDurations struct {
+	CheckId string
+	UserId  int
+	From    time.Time
+	To      time.Time
+	Bucket  DurationBucket
+}
+
+// DurationStats are statistics of durations of runs which finished within the period, in seconds. Start is the
+// start of the period for time series, all the values are zero if there are no runs
+type DurationStats struct {
+	Start time.Time `db:"start"`
+	Runs  int       `db:"runs"`
+	Min   int       `db:"min"`
+	Avg   float64   `db:"avg"`
+	P50   float64   `db:"p50"`
+	P95   float64   `db:"p95"`
+	Max   int       `db:"max"`
+}
+
+// PingWarning is success ping which has finished slow or too fast run, one of the thresholds is set
+type PingWarning struct {
+	CheckId       string    `db:"check_id"`
+	CheckName     string    `db:"name"`
+	Duration      int       `db:"duration"`
+	SlowThreshold *int      `db:"slow_threshold"`
+	FastThreshold *int      `db:"fast_threshold"`
+	Date          time.Time `db:"date"`
+	InMaintenance bool      `db:"in_maintenance"`
+	Channels      Channels  `db:"channels"`
 }
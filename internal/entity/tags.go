@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// Tags are stored in the DB as space-separated string
+type Tags []string
+
+// Scan converts the data returned from the DB into the tags.
+func (t *Tags) Scan(v interface{}) error {
+	switch vv := v.(type) {
+	case []byte:
+		*t = strings.Fields(string(vv))
+	case string:
+		*t = strings.Fields(vv)
+	case nil:
+		*t = nil
+	default:
+		return fmt.Errorf("unsupported type: %T", v)
+	}
+
+	return nil
+}
+
+// Value converts the tags into space-separated string.
+func (t Tags) Value() (driver.Value, error) {
+	return strings.Join(t, " "), nil
+}
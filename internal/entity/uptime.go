@@ -0,0 +1,40 @@
+package entity
+
+import "time"
+
+type UptimeBucket string
+
+const (
+	UptimeDay   UptimeBucket = "day"
+	UptimeWeek  UptimeBucket = "week"
+	UptimeMonth UptimeBucket = "month"
+)
+
+type GetUptime struct {
+	CheckId string
+	UserId  int
+	From    time.Time
+	To      time.Time
+	Bucket  UptimeBucket
+}
+
+type GetTagsUptime struct {
+	UserId int
+	From   time.Time
+	To     time.Time
+	Bucket UptimeBucket
+}
+
+// Uptime is time which check spent in each state within the period from Start to End
+type Uptime struct {
+	Start  time.Time
+	End    time.Time
+	Up     time.Duration
+	Down   time.Duration
+	Paused time.Duration
+}
+
+type TagUptime struct {
+	Tag     string
+	Buckets []Uptime
+}
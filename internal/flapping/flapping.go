@@ -0,0 +1,44 @@
+// Package flapping detects checks which keep changing their status. Flips of a flapping check are recorded
+// suppressed, so users get one notification when the check starts flapping instead of one per flip.
+package flapping
+
+import (
+	"context"
+	"gitlab.com/grygoryz/uptime-checker/internal/entity"
+	"gitlab.com/grygoryz/uptime-checker/internal/repository"
+	"time"
+)
+
+// Apply suppresses the flip of the check if the check is flapping or starts flapping with this flip. Check that
+// starts flapping is marked as flapping and gets flapping flip, which notifies users about it
+func Apply(ctx context.Context, r *repository.Registry, d entity.FlapDetection, flip *entity.CreateFlip) error {
+	if d.FlappingSince != nil {
+		flip.Suppressed = true
+		return nil
+	}
+	if d.FlapThreshold == nil {
+		return nil
+	}
+
+	since := flip.Date.Add(-time.Second * time.Duration(d.FlapWindow))
+	flips, err := r.Flip.CountChanges(ctx, flip.CheckId, since)
+	if err != nil {
+		return err
+	}
+	// the flip itself is not created yet
+	if flips+1 <= *d.FlapThreshold {
+		return nil
+	}
+
+	flip.Suppressed = true
+	err = r.Check.SetFlapping(ctx, flip.CheckId, flip.Date)
+	if err != nil {
+		return err
+	}
+
+	return r.Flip.Create(ctx, entity.CreateFlip{
+		To:      entity.FlipFlapping,
+		Date:    flip.Date,
+		CheckId: flip.CheckId,
+	})
+}
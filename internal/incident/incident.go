@@ -0,0 +1,26 @@
+// Package incident tracks incidents of checks: an incident is opened by down flip of the check and ends with
+// its next up flip.
+package incident
+
+import (
+	"context"
+	"gitlab.com/grygoryz/uptime-checker/internal/entity"
+	"gitlab.com/grygoryz/uptime-checker/internal/repository"
+)
+
+// Track opens incident of the check if the flip is down and closes it if the flip is up. Returns id of the
+// incident opened by down flip. It should be called for suppressed flips too, since the check is down anyway
+func Track(ctx context.Context, r *repository.Registry, flip entity.CreateFlip) (int, error) {
+	switch flip.To {
+	case entity.FlipDown:
+		return r.Incident.Open(ctx, entity.OpenIncident{
+			CheckId:   flip.CheckId,
+			Cause:     flip.Reason,
+			StartedAt: flip.Date,
+		})
+	case entity.FlipUp:
+		return 0, r.Incident.Close(ctx, entity.CloseIncident{CheckId: flip.CheckId, EndedAt: flip.Date})
+	default:
+		return 0, nil
+	}
+}
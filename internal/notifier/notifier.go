@@ -1,6 +1,6 @@
 // Package notifier implements a notification system that consumes flip notifications from a queue concurrently
-// and notifies users through provided channels. The notifier handles emails and webhooks, and sends
-// them to the respective recipients.
+// and notifies users through provided channels. The notifier handles emails, webhooks, slack and telegram
+// messages and pagerduty events, and sends them to the respective recipients.
 package notifier
 
 import (
@@ -13,9 +13,14 @@ import (
 	"gitlab.com/grygoryz/uptime-checker/config"
 	"gitlab.com/grygoryz/uptime-checker/internal/entity"
 	"gitlab.com/grygoryz/uptime-checker/internal/queue"
+	"gitlab.com/grygoryz/uptime-checker/internal/utility/signature"
+	"gitlab.com/grygoryz/uptime-checker/internal/webhook"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -69,43 +74,115 @@ func (n *notifier) handler(msg amqp091.Delivery) bool {
 		return false
 	}
 
-	// extract emails and webhooks
+	// extract emails and webhooks, slack messages and pagerduty events are sent as webhooks
 	var emails []string
-	var webhooks []string
+	var webhooks []*http.Request
+	var telegrams []telegramSend
 	for _, channel := range notification.CheckChannels {
+		if notification.FlipTo == entity.NotificationFlipLate && !channel.NotifyLate {
+			continue
+		}
+
 		switch channel.Kind {
 		case entity.EmailChannel:
 			emails = append(emails, *channel.Email)
 		case entity.WebhookChannel:
-			if notification.FlipTo == entity.NotificationFlipUp {
-				webhooks = append(webhooks, *channel.WebhookURLUp)
-			} else {
-				webhooks = append(webhooks, *channel.WebhookURLDown)
+			url := webhookURL(channel, notification)
+			if url == "" {
+				continue
+			}
+			req, err := webhook.NewRequest(channel, url, notification)
+			if err != nil {
+				log.Err(err).Msgf("Building webhook request failed for channel %v", channel.Id)
+				continue
+			}
+			webhooks = append(webhooks, req)
+		case entity.SlackChannel:
+			req, err := n.slackRequest(channel, notification)
+			if err != nil {
+				log.Err(err).Msgf("Building slack request failed for channel %v", channel.Id)
+				continue
+			}
+			webhooks = append(webhooks, req)
+		case entity.TelegramChannel:
+			req, err := n.telegramRequest(channel, notification)
+			if err != nil {
+				log.Err(err).Msgf("Building telegram request failed for channel %v", channel.Id)
+				continue
+			}
+			telegrams = append(telegrams, telegramSend{channelId: channel.Id, req: req})
+		case entity.PagerDutyChannel:
+			req, err := n.pagerDutyRequest(channel, notification)
+			if err != nil {
+				log.Err(err).Msgf("Building pagerduty request failed for channel %v", channel.Id)
+				continue
+			}
+			if req != nil {
+				webhooks = append(webhooks, req)
 			}
 		}
 	}
 
-	// send emails and trigger webhooks concurrently
+	// send emails and messages, and trigger webhooks concurrently
 	var wg sync.WaitGroup
-	wg.Add(len(emails) + len(webhooks))
+	wg.Add(len(webhooks) + len(telegrams))
 
-	go func() {
-		n.sendEmail(&log, emails, notification.CheckName, notification.FlipTo, notification.FlipDate)
-		wg.Done()
-	}()
+	// all emails are sent with one message
+	if len(emails) > 0 {
+		wg.Add(1)
+		go func() {
+			n.sendEmail(&log, emails, notification)
+			wg.Done()
+		}()
+	}
 
-	for _, webhook := range webhooks {
-		go func(webhook string) {
-			n.triggerWebhook(&log, webhook)
+	for _, req := range webhooks {
+		go func(req *http.Request) {
+			n.triggerWebhook(&log, req)
 			wg.Done()
-		}(webhook)
+		}(req)
+	}
+
+	for _, telegram := range telegrams {
+		go func(telegram telegramSend) {
+			n.sendTelegram(&log, telegram.channelId, telegram.req)
+			wg.Done()
+		}(telegram)
 	}
 	wg.Wait()
 
 	return true
 }
 
-func (n *notifier) sendEmail(log *zerolog.Logger, to []string, checkName string, status entity.NotificationFlipStatus, date time.Time) {
+// webhookURL returns url of the webhook channel to trigger for the notification, or empty string if there is
+// nothing to trigger. Single url of the channel is triggered for all the notifications
+func webhookURL(channel entity.ChannelShort, notification entity.Notification) string {
+	switch notification.FlipTo {
+	case entity.NotificationFlipUp, entity.NotificationFlipDown, entity.NotificationFlipLate,
+		entity.NotificationFlipStillDown, entity.NotificationFlipStable:
+		if channel.WebhookURL != nil && *channel.WebhookURL != "" {
+			return *channel.WebhookURL
+		}
+	}
+
+	switch notification.FlipTo {
+	case entity.NotificationFlipUp:
+		return *channel.WebhookURLUp
+	case entity.NotificationFlipDown, entity.NotificationFlipLate, entity.NotificationFlipStillDown:
+		// late check is going to be down soon, so down webhook is triggered for it
+		return *channel.WebhookURLDown
+	case entity.NotificationFlipStable:
+		// flips of flapping check were suppressed, so webhook of the status it has settled in is triggered
+		if notification.CheckStatus == entity.CheckDown {
+			return *channel.WebhookURLDown
+		}
+		return *channel.WebhookURLUp
+	default:
+		return ""
+	}
+}
+
+func (n *notifier) sendEmail(log *zerolog.Logger, to []string, notification entity.Notification) {
 	message := mailjet.InfoMessagesV31{
 		From: &mailjet.RecipientV31{
 			Email: n.cfg.Mailjet.SenderEmail,
@@ -113,22 +190,27 @@ func (n *notifier) sendEmail(log *zerolog.Logger, to []string, checkName string,
 		},
 	}
 
-	recipients := make(mailjet.RecipientsV31, len(to))
-	for i, email := range to {
-		recipients[i] = mailjet.RecipientV31{Email: email}
-	}
-	message.To = &recipients
+	message.Subject, message.TextPart = messageText(notification)
 
-	switch status {
-	case entity.NotificationFlipDown:
-		message.Subject = fmt.Sprintf("Check %v is down", checkName)
-		message.TextPart = fmt.Sprintf("Your check %v is down. Date: %v", checkName, date.UTC().String())
-	case entity.NotificationFlipUp:
-		message.Subject = fmt.Sprintf("Check %v is up", checkName)
-		message.TextPart = fmt.Sprintf("Your check %v is up. Date: %v", checkName, date.UTC().String())
+	var info []mailjet.InfoMessagesV31
+	if notification.FlipTo == entity.NotificationFlipDown && notification.IncidentId != nil {
+		// every recipient gets own signed links to the incident, so it's known who has acknowledged it
+		info = make([]mailjet.InfoMessagesV31, len(to))
+		for i, email := range to {
+			info[i] = message
+			info[i].To = &mailjet.RecipientsV31{{Email: email}}
+			info[i].TextPart += "\n\n" + n.incidentLinks(*notification.IncidentId, email)
+		}
+	} else {
+		recipients := make(mailjet.RecipientsV31, len(to))
+		for i, email := range to {
+			recipients[i] = mailjet.RecipientV31{Email: email}
+		}
+		message.To = &recipients
+		info = []mailjet.InfoMessagesV31{message}
 	}
 
-	messages := mailjet.MessagesV31{Info: []mailjet.InfoMessagesV31{message}}
+	messages := mailjet.MessagesV31{Info: info}
 	_, err := n.mj.SendMailV31(&messages)
 	if err != nil {
 		log.Err(err).Msg("Send email failed")
@@ -136,28 +218,192 @@ func (n *notifier) sendEmail(log *zerolog.Logger, to []string, checkName string,
 	log.Info().Msg("Send email success")
 }
 
-func (n *notifier) triggerWebhook(log *zerolog.Logger, webhook string) {
-	var err error
-	var res *http.Response
+// messageText returns subject and text of the message about the notification
+func messageText(notification entity.Notification) (subject string, text string) {
+	checkName := notification.CheckName
+	date := notification.FlipDate.UTC().String()
+	switch notification.FlipTo {
+	case entity.NotificationFlipDown:
+		subject = fmt.Sprintf("Check %v is down", checkName)
+		if notification.FlipReason == entity.FlipTimeout {
+			subject = fmt.Sprintf("Check %v run timed out", checkName)
+		}
+		down := fmt.Sprintf("Your check %v is down.", checkName)
+		if reason := downReason(notification.FlipReason); reason != "" {
+			down += " " + reason
+		}
+		if notification.ExitCode != nil {
+			down += fmt.Sprintf(" Exit code: %v.", *notification.ExitCode)
+		}
+		text = fmt.Sprintf("%v Date: %v", down, date)
+	case entity.NotificationFlipUp:
+		subject = fmt.Sprintf("Check %v is up", checkName)
+		text = fmt.Sprintf("Your check %v is up. Date: %v", checkName, date)
+	case entity.NotificationFlipStillDown:
+		subject = fmt.Sprintf("Check %v is still down", checkName)
+		text = fmt.Sprintf(
+			"Your check %v is still down since %v. Reminder %v of %v.",
+			checkName,
+			date,
+			notification.Reminder,
+			notification.RemindersLimit,
+		)
+	case entity.NotificationFlipLate:
+		subject = fmt.Sprintf("Check %v is running late", checkName)
+		text = fmt.Sprintf(
+			"Your check %v is running late: the expected ping has not been received yet, "+
+				"the check goes down when its grace period expires. Expected at: %v",
+			checkName,
+			date,
+		)
+	case entity.NotificationFlipFlapping:
+		subject = fmt.Sprintf("Check %v is flapping", checkName)
+		text = fmt.Sprintf(
+			"Your check %v keeps changing its status. You won't be notified about its flips until it becomes "+
+				"stable. Date: %v",
+			checkName,
+			date,
+		)
+	case entity.NotificationFlipStable:
+		subject = fmt.Sprintf("Check %v is stable", checkName)
+		text = fmt.Sprintf(
+			"Your check %v has stopped flapping and is %v now. Date: %v",
+			checkName,
+			notification.CheckStatus,
+			date,
+		)
+	case entity.NotificationSlowRun:
+		subject = fmt.Sprintf("Check %v run was slow", checkName)
+		text = fmt.Sprintf(
+			"Your check %v has finished a run which lasted %vs, longer than its slow run threshold of %vs. "+
+				"The check is still up. Date: %v",
+			checkName,
+			notification.Duration,
+			notification.SlowThreshold,
+			date,
+		)
+	case entity.NotificationFastRun:
+		subject = fmt.Sprintf("Check %v run was too fast", checkName)
+		text = fmt.Sprintf(
+			"Your check %v has finished a run which lasted %vs, less than its min duration of %vs, so the run "+
+				"has likely done nothing. The check is still up. Date: %v",
+			checkName,
+			notification.Duration,
+			notification.MinDuration,
+			date,
+		)
+	case entity.NotificationMaintenanceEnded:
+		subject = fmt.Sprintf("Maintenance window %v has ended", checkName)
+		text = maintenanceSummary(notification)
+	}
+
+	return subject, text
+}
+
+// incidentLinks returns text with links which let the recipient acknowledge the incident and add notes to it
+func (n *notifier) incidentLinks(incidentId int, email string) string {
+	id := strconv.Itoa(incidentId)
+	expires := time.Now().Add(n.cfg.Links.TTL).Unix()
+	query := url.Values{}
+	query.Set("email", email)
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", signature.SignExpiring(n.cfg.Links.Secret, expires, id, email))
+	base := fmt.Sprintf("%v/v1/incidents/%v/link", strings.TrimSuffix(n.cfg.Links.BaseURL, "/"), id)
+
+	return fmt.Sprintf(
+		"Acknowledge the incident: open %[1]v/acknowledge?%[2]v\n"+
+			"Add a note: send POST request with {\"text\": \"...\"} body to %[1]v/notes?%[2]v",
+		base,
+		query.Encode(),
+	)
+}
+
+// downReason returns human-readable description of the reason why check went down
+func downReason(reason entity.FlipReason) string {
+	switch reason {
+	case entity.FlipMissed:
+		return "The expected ping was not received in time."
+	case entity.FlipFail:
+		return "The job reported a failure."
+	case entity.FlipTimeout:
+		return "The run has timed out: it was started but did not finish within the max duration."
+	case entity.FlipNoFirstPing:
+		return "The check has not received its first ping before the deadline."
+	case entity.FlipTooFast:
+		return "The run has finished faster than the min duration, so it has likely done nothing."
+	case entity.FlipInstancesMissing:
+		return "More instances of the fleet are down than the check tolerates."
+	default:
+		return ""
+	}
+}
+
+// maintenanceSummary returns text of the maintenance window summary with flips suppressed during the window
+func maintenanceSummary(notification entity.Notification) string {
+	var sb strings.Builder
+	end := notification.FlipDate.UTC().String()
+	if notification.MaintenanceStart != nil {
+		start := notification.MaintenanceStart.UTC().String()
+		sb.WriteString(fmt.Sprintf("Maintenance window %v has ended. Period: %v - %v\n", notification.CheckName, start, end))
+	} else {
+		sb.WriteString(fmt.Sprintf("Maintenance window %v has ended. Date: %v\n", notification.CheckName, end))
+	}
+	if len(notification.MaintenanceFlips) == 0 {
+		sb.WriteString("None of the checks changed their status during the window.")
+		return sb.String()
+	}
+
+	sb.WriteString("Status changes of the checks during the window:\n")
+	for _, flip := range notification.MaintenanceFlips {
+		sb.WriteString(fmt.Sprintf("%v: %v at %v\n", flip.CheckName, flip.To, flip.Date.UTC().String()))
+	}
+	return sb.String()
+}
+
+// triggerWebhook sends the webhook request, retrying it until it gets 2xx status code
+func (n *notifier) triggerWebhook(log *zerolog.Logger, req *http.Request) {
 	retries := 3
 	for {
 		if retries == 0 {
-			break
+			log.Error().Msgf("Webhook trigger failed: %v %v", req.Method, req.URL)
+			return
+		}
+
+		attempt, err := cloneRequest(req)
+		if err != nil {
+			log.Err(err).Msg("Reading webhook body failed")
+			return
 		}
-		res, err = client.Get(webhook)
+
+		res, err := client.Do(attempt)
 		if err != nil {
 			log.Err(err).Msg("Request failed with error, retrying...")
 			retries--
 			continue
 		}
-		if res.StatusCode != http.StatusOK {
-			log.Error().Msg("Request got non-200 status code, retrying...")
+		res.Body.Close()
+		if res.StatusCode < 200 || res.StatusCode > 299 {
+			log.Error().Msg("Request got non-2xx status code, retrying...")
 			retries--
 			continue
 		}
 		break
 	}
-	log.Info().Msgf("Webhook trigger success: %v", webhook)
+	log.Info().Msgf("Webhook trigger success: %v %v", req.Method, req.URL)
+}
+
+// cloneRequest returns copy of the request to be sent by another attempt, body is read by every attempt, so each
+// one gets its own copy of it
+func cloneRequest(req *http.Request) (*http.Request, error) {
+	attempt := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		attempt.Body = body
+	}
+	return attempt, nil
 }
 
 func (n *notifier) shutdown() {
@@ -0,0 +1,117 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gitlab.com/grygoryz/uptime-checker/internal/entity"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// pagerDutyEvent is an event of Events API v2, payload is set for trigger events only
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+	Client      string            `json:"client,omitempty"`
+	ClientURL   string            `json:"client_url,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string                 `json:"summary"`
+	Source        string                 `json:"source"`
+	Severity      entity.CheckSeverity   `json:"severity"`
+	Timestamp     string                 `json:"timestamp"`
+	Class         entity.FlipReason      `json:"class,omitempty"`
+	CustomDetails map[string]interface{} `json:"custom_details"`
+}
+
+const (
+	pagerDutyTrigger = "trigger"
+	pagerDutyResolve = "resolve"
+)
+
+// maxPagerDutyExcerpt defines max length of the last ping body excerpt in runes
+const maxPagerDutyExcerpt = 1000
+
+// pagerDutyRequest returns request sending event about the notification to the pagerduty channel, or nil if the
+// notification doesn't trigger or resolve incidents. Events of the same check share dedup key, so down flip
+// triggers an incident and the following up flip resolves it
+func (n *notifier) pagerDutyRequest(
+	channel entity.ChannelShort,
+	notification entity.Notification,
+) (*http.Request, error) {
+	var action string
+	switch notification.FlipTo {
+	case entity.NotificationFlipDown, entity.NotificationFlipUp, entity.NotificationFlipStable:
+		action = pagerDutyResolve
+		if notification.Status() == entity.NotificationFlipDown {
+			action = pagerDutyTrigger
+		}
+	default:
+		return nil, nil
+	}
+
+	event := pagerDutyEvent{
+		RoutingKey:  *channel.PagerDutyRoutingKey,
+		EventAction: action,
+		DedupKey:    "uptime-checker/" + notification.CheckId,
+	}
+	if action == pagerDutyTrigger {
+		subject, _ := messageText(notification)
+		severity := notification.CheckSeverity
+		if severity == "" {
+			severity = entity.SeverityCritical
+		}
+
+		details := map[string]interface{}{
+			"check_id":    notification.CheckId,
+			"description": notification.CheckDescription,
+			"tags":        notification.CheckTags,
+		}
+		if reason := downReason(notification.FlipReason); reason != "" {
+			details["reason"] = reason
+		}
+		if notification.ExitCode != nil {
+			details["exit_code"] = *notification.ExitCode
+		}
+		if notification.LastPingBody != "" {
+			excerpt := []rune(notification.LastPingBody)
+			if len(excerpt) > maxPagerDutyExcerpt {
+				excerpt = append(excerpt[:maxPagerDutyExcerpt], '…')
+			}
+			details["last_ping_body"] = string(excerpt)
+		}
+
+		event.Payload = &pagerDutyPayload{
+			Summary:       subject,
+			Source:        notification.CheckName,
+			Severity:      severity,
+			Timestamp:     notification.FlipDate.UTC().Format(time.RFC3339),
+			Class:         notification.FlipReason,
+			CustomDetails: details,
+		}
+		event.Client = "Uptime Checker"
+		event.ClientURL = fmt.Sprintf(
+			"%v/v1/checks/%v",
+			strings.TrimSuffix(n.cfg.Links.BaseURL, "/"),
+			notification.CheckId,
+		)
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, n.cfg.PagerDuty.EventsURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return req, nil
+}
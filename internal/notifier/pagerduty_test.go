@@ -0,0 +1,136 @@
+package notifier
+
+import (
+	"encoding/json"
+	"github.com/rs/zerolog"
+	"gitlab.com/grygoryz/uptime-checker/config"
+	"gitlab.com/grygoryz/uptime-checker/internal/entity"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPagerDutyRequest(t *testing.T) {
+	tests := []struct {
+		name         string
+		notification entity.Notification
+		action       string // empty if no event is sent
+		severity     entity.CheckSeverity
+	}{
+		{
+			name:         "down triggers",
+			notification: entity.Notification{FlipTo: entity.NotificationFlipDown, CheckSeverity: entity.SeverityWarning},
+			action:       pagerDutyTrigger,
+			severity:     entity.SeverityWarning,
+		},
+		{
+			name:         "down without severity triggers critical",
+			notification: entity.Notification{FlipTo: entity.NotificationFlipDown},
+			action:       pagerDutyTrigger,
+			severity:     entity.SeverityCritical,
+		},
+		{
+			name:         "up resolves",
+			notification: entity.Notification{FlipTo: entity.NotificationFlipUp},
+			action:       pagerDutyResolve,
+		},
+		{
+			name:         "stable up resolves",
+			notification: entity.Notification{FlipTo: entity.NotificationFlipStable, CheckStatus: entity.CheckUp},
+			action:       pagerDutyResolve,
+		},
+		{
+			name:         "stable down triggers",
+			notification: entity.Notification{FlipTo: entity.NotificationFlipStable, CheckStatus: entity.CheckDown},
+			action:       pagerDutyTrigger,
+			severity:     entity.SeverityCritical,
+		},
+		{
+			name:         "still down is not sent",
+			notification: entity.Notification{FlipTo: entity.NotificationFlipStillDown},
+		},
+		{
+			name:         "late is not sent",
+			notification: entity.Notification{FlipTo: entity.NotificationFlipLate},
+		},
+		{
+			name:         "flapping is not sent",
+			notification: entity.Notification{FlipTo: entity.NotificationFlipFlapping},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var event *pagerDutyEvent
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				event = &pagerDutyEvent{}
+				err := json.NewDecoder(r.Body).Decode(event)
+				if err != nil {
+					t.Errorf("want event to be decoded, got %v", err)
+				}
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defer server.Close()
+			n := &notifier{cfg: config.Config{
+				PagerDuty: config.PagerDuty{EventsURL: server.URL},
+				Links:     config.Links{BaseURL: "http://localhost:3000"},
+			}}
+
+			key := "routing-key"
+			notification := tt.notification
+			notification.CheckId = "id"
+			notification.CheckName = "backup"
+			notification.FlipDate = time.Now()
+			req, err := n.pagerDutyRequest(entity.ChannelShort{
+				Kind:                entity.PagerDutyChannel,
+				PagerDutyRoutingKey: &key,
+			}, notification)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.action == "" {
+				if req != nil {
+					t.Errorf("want no event to be sent, got request to %v", req.URL)
+				}
+				return
+			}
+
+			log := zerolog.Nop()
+			n.triggerWebhook(&log, req)
+			if event == nil {
+				t.Fatal("want event to be sent")
+			}
+
+			if event.EventAction != tt.action {
+				t.Errorf("want event action to be %v, got %v", tt.action, event.EventAction)
+			}
+			if event.RoutingKey != key {
+				t.Errorf("want routing key to be %v, got %v", key, event.RoutingKey)
+			}
+			// trigger and resolve events of the check share dedup key, so they refer to the same incident
+			if event.DedupKey != "uptime-checker/id" {
+				t.Errorf("want dedup key to be uptime-checker/id, got %v", event.DedupKey)
+			}
+
+			if tt.action == pagerDutyResolve {
+				if event.Payload != nil {
+					t.Errorf("want resolve event without payload, got %+v", event.Payload)
+				}
+				return
+			}
+			if event.Payload == nil {
+				t.Fatal("want trigger event with payload")
+			}
+			if event.Payload.Severity != tt.severity {
+				t.Errorf("want severity to be %v, got %v", tt.severity, event.Payload.Severity)
+			}
+			if event.Payload.Source != "backup" || event.Payload.Summary == "" {
+				t.Errorf("want payload with summary about check backup, got %+v", event.Payload)
+			}
+			if event.ClientURL != "http://localhost:3000/v1/checks/id" {
+				t.Errorf("want client URL to link the check, got %v", event.ClientURL)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gitlab.com/grygoryz/uptime-checker/internal/entity"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// slackMessage is a message posted to slack incoming webhook, its blocks are put into the attachment,
+// so the message is highlighted with the colour of the status
+type slackMessage struct {
+	Text        string            `json:"text"` // fallback shown in notifications
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+type slackAttachment struct {
+	Color  string       `json:"color"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+type slackBlock struct {
+	Type   string      `json:"type"`
+	Text   *slackText  `json:"text,omitempty"`
+	Fields []slackText `json:"fields,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+const (
+	slackColorUp   = "#2eb886"
+	slackColorDown = "#e01e5a"
+	slackColorWarn = "#ecb22e"
+)
+
+// maxSlackExcerpt defines max length of the last ping body excerpt in runes
+const maxSlackExcerpt = 500
+
+// slackRequest returns request posting message about the notification to incoming webhook of the slack channel
+func (n *notifier) slackRequest(channel entity.ChannelShort, notification entity.Notification) (*http.Request, error) {
+	subject, text := messageText(notification)
+	status := notification.Status()
+
+	title := "*" + slackEscape(subject) + "*"
+	if notification.CheckId != "" {
+		link := fmt.Sprintf("%v/v1/checks/%v", strings.TrimSuffix(n.cfg.Links.BaseURL, "/"), notification.CheckId)
+		title = fmt.Sprintf("*<%v|%v>*", link, slackEscape(subject))
+	}
+
+	blocks := []slackBlock{
+		{Type: "section", Text: &slackText{Type: "mrkdwn", Text: title}},
+		{Type: "section", Fields: []slackText{
+			{Type: "mrkdwn", Text: fmt.Sprintf("*Check*\n%v", slackEscape(notification.CheckName))},
+			{Type: "mrkdwn", Text: fmt.Sprintf("*Status*\n%v", status)},
+			{Type: "mrkdwn", Text: fmt.Sprintf("*Date*\n%v", notification.FlipDate.UTC().Format(time.RFC1123))},
+		}},
+		{Type: "section", Text: &slackText{Type: "mrkdwn", Text: slackEscape(text)}},
+	}
+	if notification.LastPingBody != "" {
+		excerpt := []rune(notification.LastPingBody)
+		if len(excerpt) > maxSlackExcerpt {
+			excerpt = append(excerpt[:maxSlackExcerpt], '…')
+		}
+		blocks = append(blocks, slackBlock{Type: "section", Text: &slackText{
+			Type: "mrkdwn",
+			Text: fmt.Sprintf("*Last ping body*\n```%v```", slackEscape(string(excerpt))),
+		}})
+	}
+
+	body, err := json.Marshal(slackMessage{
+		Text:        subject,
+		Attachments: []slackAttachment{{Color: slackColor(status), Blocks: blocks}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, *channel.SlackWebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return req, nil
+}
+
+// slackColor returns colour of the message about notification with the status
+func slackColor(status entity.NotificationFlipStatus) string {
+	switch status {
+	case entity.NotificationFlipUp:
+		return slackColorUp
+	case entity.NotificationFlipDown, entity.NotificationFlipStillDown:
+		return slackColorDown
+	default:
+		return slackColorWarn
+	}
+}
+
+var slackReplacer = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// slackEscape escapes control characters of slack mrkdwn
+func slackEscape(s string) string {
+	return slackReplacer.Replace(s)
+}
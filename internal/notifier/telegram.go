@@ -0,0 +1,159 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/rs/zerolog"
+	"gitlab.com/grygoryz/uptime-checker/internal/entity"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// telegramMessage is a body of sendMessage method of the Bot API
+type telegramMessage struct {
+	ChatId                string `json:"chat_id"`
+	Text                  string `json:"text"`
+	ParseMode             string `json:"parse_mode"`
+	DisableWebPagePreview bool   `json:"disable_web_page_preview"`
+}
+
+// telegramResponse is a response of the Bot API, description and parameters are set for failed requests
+type telegramResponse struct {
+	Ok          bool   `json:"ok"`
+	Description string `json:"description"`
+	Parameters  struct {
+		RetryAfter int `json:"retry_after"` // seconds to wait before the request can be repeated
+	} `json:"parameters"`
+}
+
+// maxTelegramExcerpt defines max length of the last ping body excerpt in runes
+const maxTelegramExcerpt = 500
+
+// maxTelegramRetryAfter defines max delay requested by the Bot API which the notifier waits for before retrying
+const maxTelegramRetryAfter = time.Minute
+
+// telegramSend is a message request of the telegram channel. Bot token is a part of the request url, so the request
+// is tracked by id of the channel
+type telegramSend struct {
+	channelId int
+	req       *http.Request
+}
+
+// telegramRequest returns request sending message about the notification to the chat of the telegram channel
+func (n *notifier) telegramRequest(
+	channel entity.ChannelShort,
+	notification entity.Notification,
+) (*http.Request, error) {
+	subject, text := messageText(notification)
+
+	var sb strings.Builder
+	if notification.CheckId != "" {
+		link := fmt.Sprintf("%v/v1/checks/%v", strings.TrimSuffix(n.cfg.Links.BaseURL, "/"), notification.CheckId)
+		sb.WriteString(fmt.Sprintf("<b><a href=\"%v\">%v</a></b>\n\n", html.EscapeString(link), html.EscapeString(subject)))
+	} else {
+		sb.WriteString(fmt.Sprintf("<b>%v</b>\n\n", html.EscapeString(subject)))
+	}
+	sb.WriteString(fmt.Sprintf("<b>Check:</b> %v\n", html.EscapeString(notification.CheckName)))
+	sb.WriteString(fmt.Sprintf("<b>Status:</b> %v\n", notification.Status()))
+	sb.WriteString(fmt.Sprintf("<b>Date:</b> %v\n\n", notification.FlipDate.UTC().Format(time.RFC1123)))
+	sb.WriteString(html.EscapeString(text))
+	if notification.LastPingBody != "" {
+		excerpt := []rune(notification.LastPingBody)
+		if len(excerpt) > maxTelegramExcerpt {
+			excerpt = append(excerpt[:maxTelegramExcerpt], '…')
+		}
+		sb.WriteString(fmt.Sprintf("\n\n<b>Last ping body</b>\n<pre>%v</pre>", html.EscapeString(string(excerpt))))
+	}
+
+	body, err := json.Marshal(telegramMessage{
+		ChatId:                *channel.TelegramChatId,
+		Text:                  sb.String(),
+		ParseMode:             "HTML",
+		DisableWebPagePreview: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf(
+		"%v/bot%v/sendMessage",
+		strings.TrimSuffix(n.cfg.Telegram.ApiURL, "/"),
+		*channel.TelegramBotToken,
+	)
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return req, nil
+}
+
+// sendTelegram sends the message request of the telegram channel. The request is retried on network and server
+// errors, and after the delay the Bot API asks for when it's rate limited. Other errors like "chat not found" or
+// blocked bot are not retried, since repeating the request won't help. URL of the request contains bot token,
+// so the channel is logged instead of it
+func (n *notifier) sendTelegram(log *zerolog.Logger, channelId int, req *http.Request) {
+	retries := 3
+	for {
+		if retries == 0 {
+			log.Error().Msgf("Telegram message failed for channel %v", channelId)
+			return
+		}
+
+		attempt, err := cloneRequest(req)
+		if err != nil {
+			log.Err(err).Msg("Reading telegram message body failed")
+			return
+		}
+
+		res, err := client.Do(attempt)
+		if err != nil {
+			if urlErr, ok := err.(*url.Error); ok {
+				err = urlErr.Err
+			}
+			log.Err(err).Msg("Telegram request failed with error, retrying...")
+			retries--
+			continue
+		}
+		var response telegramResponse
+		err = json.NewDecoder(res.Body).Decode(&response)
+		res.Body.Close()
+		if err != nil {
+			log.Err(err).Msg("Decoding telegram response failed")
+		}
+
+		switch {
+		case res.StatusCode >= 200 && res.StatusCode <= 299:
+			log.Info().Msgf("Telegram message success for channel %v", channelId)
+			return
+		case res.StatusCode == http.StatusTooManyRequests:
+			delay := time.Duration(response.Parameters.RetryAfter) * time.Second
+			if delay > maxTelegramRetryAfter {
+				log.Error().Msgf("Telegram message failed for channel %v: rate limited for %v", channelId, delay)
+				return
+			}
+			if delay == 0 {
+				delay = time.Second
+			}
+			log.Warn().Msgf("Telegram rate limit hit, retrying in %v...", delay)
+			time.Sleep(delay)
+			retries--
+		case res.StatusCode >= 500:
+			log.Error().Msgf("Telegram request got %v status code, retrying...", res.StatusCode)
+			retries--
+		default:
+			log.Error().Msgf(
+				"Telegram message rejected for channel %v: %v %v",
+				channelId,
+				res.StatusCode,
+				response.Description,
+			)
+			return
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package notifier
+
+import (
+	"encoding/json"
+	"github.com/rs/zerolog"
+	"gitlab.com/grygoryz/uptime-checker/config"
+	"gitlab.com/grygoryz/uptime-checker/internal/entity"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// telegramReply is a response of the Bot API stand-in
+type telegramReply struct {
+	status int
+	body   string
+}
+
+// telegramServer returns stand-in of the Bot API which replies with the replies in their order, repeating the last
+// one, and counter of the received requests. Received messages are passed to check
+func telegramServer(t *testing.T, replies []telegramReply, check func(message telegramMessage)) (*httptest.Server, *int32) {
+	var received int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&received, 1))
+		if r.URL.Path != "/bottoken/sendMessage" {
+			t.Errorf("want request to /bottoken/sendMessage, got %v", r.URL.Path)
+		}
+
+		var message telegramMessage
+		err := json.NewDecoder(r.Body).Decode(&message)
+		if err != nil {
+			t.Errorf("want message to be decoded, got %v", err)
+		}
+		if check != nil {
+			check(message)
+		}
+
+		reply := replies[len(replies)-1]
+		if n <= len(replies) {
+			reply = replies[n-1]
+		}
+		w.WriteHeader(reply.status)
+		w.Write([]byte(reply.body))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &received
+}
+
+func telegramChannel() entity.ChannelShort {
+	token := "token"
+	chatId := "42"
+	return entity.ChannelShort{
+		Id:               1,
+		Kind:             entity.TelegramChannel,
+		TelegramBotToken: &token,
+		TelegramChatId:   &chatId,
+	}
+}
+
+func TestSendTelegram(t *testing.T) {
+	tests := []struct {
+		name     string
+		replies  []telegramReply
+		attempts int32
+		minDelay time.Duration
+	}{
+		{
+			name:     "success",
+			replies:  []telegramReply{{http.StatusOK, `{"ok": true}`}},
+			attempts: 1,
+		},
+		{
+			name: "rate limited",
+			replies: []telegramReply{
+				{http.StatusTooManyRequests, `{"ok": false, "parameters": {"retry_after": 1}}`},
+				{http.StatusOK, `{"ok": true}`},
+			},
+			attempts: 2,
+			minDelay: time.Second,
+		},
+		{
+			name:     "chat not found",
+			replies:  []telegramReply{{http.StatusBadRequest, `{"ok": false, "description": "Bad Request: chat not found"}`}},
+			attempts: 1,
+		},
+		{
+			name:     "server error",
+			replies:  []telegramReply{{http.StatusBadGateway, `{"ok": false}`}},
+			attempts: 3,
+		},
+		{
+			name: "server error recovered",
+			replies: []telegramReply{
+				{http.StatusInternalServerError, `{"ok": false}`},
+				{http.StatusOK, `{"ok": true}`},
+			},
+			attempts: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, received := telegramServer(t, tt.replies, nil)
+			n := &notifier{cfg: config.Config{Telegram: config.Telegram{ApiURL: server.URL}}}
+
+			req, err := n.telegramRequest(telegramChannel(), entity.Notification{
+				CheckName: "backup",
+				FlipTo:    entity.NotificationFlipDown,
+				FlipDate:  time.Now(),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			log := zerolog.Nop()
+			start := time.Now()
+			n.sendTelegram(&log, 1, req)
+
+			if attempts := atomic.LoadInt32(received); attempts != tt.attempts {
+				t.Errorf("want %v attempts, got %v", tt.attempts, attempts)
+			}
+			if elapsed := time.Since(start); elapsed < tt.minDelay {
+				t.Errorf("want retry to wait for %v, got %v", tt.minDelay, elapsed)
+			}
+		})
+	}
+}
+
+func TestTelegramRequest_EscapesHTML(t *testing.T) {
+	server, received := telegramServer(t, []telegramReply{{http.StatusOK, `{"ok": true}`}}, func(message telegramMessage) {
+		if message.ChatId != "42" || message.ParseMode != "HTML" {
+			t.Errorf("want message to chat 42 in HTML parse mode, got %+v", message)
+		}
+		if strings.Contains(message.Text, "<db>") || strings.Contains(message.Text, "<script>") {
+			t.Errorf("want check name and ping body to be escaped, got %q", message.Text)
+		}
+		for _, escaped := range []string{"&lt;db&gt; &amp; co", "&lt;script&gt;"} {
+			if !strings.Contains(message.Text, escaped) {
+				t.Errorf("want text to contain %q, got %q", escaped, message.Text)
+			}
+		}
+		if !strings.Contains(message.Text, `<a href="http://localhost:3000/v1/checks/id">`) {
+			t.Errorf("want text to link the check, got %q", message.Text)
+		}
+	})
+	n := &notifier{cfg: config.Config{
+		Telegram: config.Telegram{ApiURL: server.URL},
+		Links:    config.Links{BaseURL: "http://localhost:3000"},
+	}}
+
+	req, err := n.telegramRequest(telegramChannel(), entity.Notification{
+		CheckId:      "id",
+		CheckName:    "<db> & co",
+		FlipTo:       entity.NotificationFlipDown,
+		FlipDate:     time.Now(),
+		LastPingBody: "<script>",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	log := zerolog.Nop()
+	n.sendTelegram(&log, 1, req)
+	if attempts := atomic.LoadInt32(received); attempts != 1 {
+		t.Errorf("want 1 attempt, got %v", attempts)
+	}
+}
@@ -9,8 +9,11 @@ import (
 	"github.com/rs/zerolog/log"
 	"gitlab.com/grygoryz/uptime-checker/config"
 	"gitlab.com/grygoryz/uptime-checker/internal/entity"
+	"gitlab.com/grygoryz/uptime-checker/internal/flapping"
+	"gitlab.com/grygoryz/uptime-checker/internal/incident"
 	"gitlab.com/grygoryz/uptime-checker/internal/queue"
 	"gitlab.com/grygoryz/uptime-checker/internal/repository"
+	"gitlab.com/grygoryz/uptime-checker/internal/schedule"
 	"gitlab.com/grygoryz/uptime-checker/third_party/database"
 	"os"
 	"os/signal"
@@ -55,118 +58,666 @@ loop:
 func (p *poller) poll() {
 	log.Info().Msg("Poll start")
 	err := p.r.WithTx(context.Background(), func(ctx context.Context) error {
-		// get expired checks
-		expired, err := p.r.Check.GetExpired(ctx)
+		// resume paused checks which resume time has come
+		err := p.resumeChecks(ctx)
 		if err != nil {
 			return err
 		}
-		log.Info().Msgf("Expired checks: %+v", expired)
 
-		// update expired checks status to down
-		if len(expired) > 0 {
-			checkIds := make([]string, len(expired))
-			for i, check := range expired {
-				checkIds[i] = check.Id
-			}
-			err = p.r.Check.SetDown(ctx, checkIds)
-			if err != nil {
-				return err
-			}
+		// end flapping of checks which have been stable long enough
+		err = p.stabilizeChecks(ctx)
+		if err != nil {
+			return err
 		}
 
-		// get unprocessed flips
-		flips, err := p.r.Flip.GetUnprocessed(ctx)
+		// send summaries of ended maintenance windows and schedule their next periods
+		err = p.endMaintenance(ctx)
 		if err != nil {
 			return err
 		}
-		log.Info().Msgf("Unprocessed flips: %+v", flips)
 
-		// create new flips from expired checks
-		var newFlipsIds []int
-		var newFlips []entity.CreateFlip
-		if len(expired) > 0 {
-			newFlips = make([]entity.CreateFlip, len(expired))
-			for i, check := range expired {
-				newFlips[i] = entity.CreateFlip{
-					To:      entity.FlipDown,
-					Date:    check.NextPing.Add(time.Second * time.Duration(check.Grace)),
-					CheckId: check.Id,
-				}
-			}
-			newFlipsIds, err = p.r.Flip.CreateMany(ctx, newFlips)
+		// set missing instances of fleet checks down, and their checks too if they don't tolerate them
+		err = p.checkInstances(ctx)
+		if err != nil {
+			return err
+		}
+
+		// update expired checks and send all the flips to queue
+		err = p.processFlips(ctx)
+		if err != nil {
+			return err
+		}
+
+		// notify due steps of open escalations
+		err = p.escalate(ctx)
+		if err != nil {
+			return err
+		}
+
+		// remind about checks which are still down
+		err = p.remind(ctx)
+		if err != nil {
+			return err
+		}
+
+		// warn about slow and too fast runs
+		err = p.warnRuns(ctx)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		log.Err(err).Msg("Polling transaction error")
+	}
+	log.Info().Msg("Poll end")
+}
+
+// processFlips updates status of expired checks, transforms them to flips and sends all unprocessed flips
+// to queue. Flips of flapping checks and checks in maintenance are suppressed instead. Checks which next ping
+// has passed become late and are warned about. Down flips of checks with escalation policy open escalations
+// and are sent to channels of the escalation steps by escalate instead of channels of the checks
+func (p *poller) processFlips(ctx context.Context) error {
+	// get expired checks
+	expired, err := p.r.Check.GetExpired(ctx)
+	if err != nil {
+		return err
+	}
+	log.Info().Msgf("Expired checks: %+v", expired)
+
+	// count failures of checks which haven't reached failure threshold, the rest go down
+	expired, err = p.countMissed(ctx, expired)
+	if err != nil {
+		return err
+	}
+
+	// update expired checks status to down
+	if len(expired) > 0 {
+		checkIds := make([]string, len(expired))
+		for i, check := range expired {
+			checkIds[i] = check.Id
+		}
+		err = p.r.Check.SetDown(ctx, checkIds)
+		if err != nil {
+			return err
+		}
+	}
+
+	// set checks which next ping has passed to late and warn about them
+	late, err := p.r.Check.SetLate(ctx)
+	if err != nil {
+		return err
+	}
+	if len(late) > 0 {
+		log.Info().Msgf("Late checks: %+v", late)
+		err = p.sendLateToQueue(ctx, late)
+		if err != nil {
+			return err
+		}
+	}
+
+	// build new flips from expired checks, flips of checks in maintenance and flapping checks are suppressed.
+	// It's done before getting unprocessed flips, so flapping flips of checks which start flapping are sent
+	// right away
+	newFlips := make([]entity.CreateFlip, len(expired))
+	incidents := make([]int, len(expired))
+	for i, check := range expired {
+		newFlips[i] = entity.CreateFlip{
+			To:      entity.FlipDown,
+			Reason:  check.Reason,
+			Date:    check.ExpiredAt,
+			CheckId: check.Id,
+		}
+		incidents[i], err = incident.Track(ctx, p.r, newFlips[i])
+		if err != nil {
+			return err
+		}
+		if check.InMaintenance {
+			newFlips[i].Suppressed = true
+			continue
+		}
+		err = flapping.Apply(ctx, p.r, check.FlapDetection, &newFlips[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	// get unprocessed flips
+	flips, err := p.r.Flip.GetUnprocessed(ctx)
+	if err != nil {
+		return err
+	}
+	log.Info().Msgf("Unprocessed flips: %+v", flips)
+
+	// create new flips from expired checks
+	var newFlipsIds []int
+	if len(newFlips) > 0 {
+		newFlipsIds, err = p.r.Flip.CreateMany(ctx, newFlips)
+		if err != nil {
+			return err
+		}
+		log.Info().Msgf("Created flips ids: %v", newFlipsIds)
+	}
+
+	if len(expired) == 0 && len(flips) == 0 {
+		log.Info().Msg("No flips to process")
+		return nil
+	}
+
+	// cancel escalations of checks which are up again, channels of notified steps get their up notifications
+	err = p.closeEscalations(ctx, flips)
+	if err != nil {
+		return err
+	}
+
+	// open escalations of down flips, their steps notify the flips instead of channels of the checks
+	flipIds := make([]int, 0, len(newFlipsIds)+len(flips))
+	for _, id := range newFlipsIds {
+		flipIds = append(flipIds, id)
+	}
+	for _, flip := range flips {
+		flipIds = append(flipIds, flip.Id)
+	}
+	escalated, err := p.r.Escalation.Open(ctx, flipIds)
+	if err != nil {
+		return err
+	}
+
+	// build messages and send to queue
+	err = p.sendToQueue(ctx, expired, newFlips, incidents, flips, escalated)
+	if err != nil {
+		return err
+	}
+
+	// set processed: true to all flips
+	err = p.r.Flip.SetProcessed(ctx, flipIds)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// checkInstances forgets instances of fleet checks which haven't pinged for instance TTL, sets instances which have
+// missed their deadline down and sets checks which don't tolerate their down instances anymore down. Down flips of
+// the checks are sent to queue with the rest of unprocessed flips
+func (p *poller) checkInstances(ctx context.Context) error {
+	err := p.r.Instance.DeleteExpired(ctx)
+	if err != nil {
+		return err
+	}
+
+	missed, err := p.r.Instance.SetMissed(ctx)
+	if err != nil {
+		return err
+	}
+	if len(missed) == 0 {
+		return nil
+	}
+	log.Info().Msgf("Missed instances: %+v", missed)
+
+	// check goes down when the last of its instances which have missed the deadline does
+	expiredAt := make(map[string]time.Time)
+	for _, instance := range missed {
+		if instance.ExpiredAt.After(expiredAt[instance.CheckId]) {
+			expiredAt[instance.CheckId] = instance.ExpiredAt
+		}
+	}
+
+	var checkIds []string
+	var flips []entity.CreateFlip
+	for checkId, date := range expiredAt {
+		check, err := p.r.Check.GetForPing(ctx, checkId)
+		if err != nil {
+			return err
+		}
+		instances, err := p.r.Instance.GetMany(ctx, checkId)
+		if err != nil {
+			return err
+		}
+		if check.Fleet.Healthy(instances) {
+			continue
+		}
+
+		flip := entity.CreateFlip{
+			To:      entity.FlipDown,
+			Reason:  entity.FlipInstancesMissing,
+			Date:    date,
+			CheckId: checkId,
+		}
+		_, err = incident.Track(ctx, p.r, flip)
+		if err != nil {
+			return err
+		}
+		if check.InMaintenance {
+			flip.Suppressed = true
+		} else {
+			err = flapping.Apply(ctx, p.r, check.FlapDetection, &flip)
 			if err != nil {
 				return err
 			}
-			log.Info().Msgf("Created flips ids: %v", newFlipsIds)
 		}
+		checkIds = append(checkIds, checkId)
+		flips = append(flips, flip)
+	}
+	if len(flips) == 0 {
+		return nil
+	}
+
+	err = p.r.Check.SetDown(ctx, checkIds)
+	if err != nil {
+		return err
+	}
+	_, err = p.r.Flip.CreateMany(ctx, flips)
+	return err
+}
+
+// resumeChecks resumes paused checks which resume time has come and creates flips for them, the flips are sent
+// to queue with the rest of unprocessed flips
+func (p *poller) resumeChecks(ctx context.Context) error {
+	checks, err := p.r.Check.GetToResume(ctx)
+	if err != nil {
+		return err
+	}
+	if len(checks) == 0 {
+		return nil
+	}
+	log.Info().Msgf("Checks to resume: %+v", checks)
 
-		if len(expired) == 0 && len(flips) == 0 {
-			log.Info().Msg("No flips to process")
-			return nil
+	now := time.Now()
+	flips := make([]entity.CreateFlip, 0, len(checks))
+	for _, check := range checks {
+		next, err := schedule.Next(check.Schedule, now)
+		if err != nil {
+			log.Err(err).Msgf("Calculating next ping failed for check %v", check.Id)
+			continue
 		}
 
-		// build messages and send to queue
-		err = p.sendToQueue(ctx, expired, newFlips, flips)
+		err = p.r.Check.Resume(ctx, check.Id, next)
 		if err != nil {
 			return err
 		}
+		flips = append(flips, entity.CreateFlip{
+			To:      entity.FlipResumed,
+			Date:    now,
+			CheckId: check.Id,
+		})
+	}
+	if len(flips) == 0 {
+		return nil
+	}
 
-		// set processed: true to all flips
-		flipIds := make([]int, 0, len(newFlipsIds)+len(flips))
-		for _, id := range newFlipsIds {
-			flipIds = append(flipIds, id)
+	_, err = p.r.Flip.CreateMany(ctx, flips)
+	return err
+}
+
+// countMissed counts failures of expired checks which haven't reached their failure threshold yet and
+// returns the rest of the checks
+func (p *poller) countMissed(ctx context.Context, expired []entity.CheckExpired) ([]entity.CheckExpired, error) {
+	down := make([]entity.CheckExpired, 0, len(expired))
+	for _, check := range expired {
+		if check.ReachesThreshold() {
+			down = append(down, check)
+			continue
+		}
+
+		// missed check expects the ping of the next period, timed out one expects next ping after the timeout
+		from := check.ExpiredAt
+		if check.Reason == entity.FlipMissed && check.NextPing != nil {
+			from = *check.NextPing
+		}
+		next, err := schedule.Next(check.Schedule, from)
+		if err != nil {
+			log.Err(err).Msgf("Calculating next ping failed for check %v, setting it down", check.Id)
+			down = append(down, check)
+			continue
+		}
+
+		err = p.r.Check.CountMissed(ctx, check.Id, next)
+		if err != nil {
+			return nil, err
 		}
-		for _, flip := range flips {
-			flipIds = append(flipIds, flip.Id)
+	}
+
+	return down, nil
+}
+
+// stabilizeChecks ends flapping of checks which status hasn't changed for their stable period and creates
+// stable flips for them
+func (p *poller) stabilizeChecks(ctx context.Context) error {
+	checks, err := p.r.Check.SetStable(ctx)
+	if err != nil {
+		return err
+	}
+	if len(checks) == 0 {
+		return nil
+	}
+	log.Info().Msgf("Stable checks: %+v", checks)
+
+	now := time.Now()
+	flips := make([]entity.CreateFlip, len(checks))
+	for i, check := range checks {
+		flips[i] = entity.CreateFlip{
+			To:      entity.FlipStable,
+			Date:    now,
+			CheckId: check.Id,
+		}
+	}
+
+	_, err = p.r.Flip.CreateMany(ctx, flips)
+	return err
+}
+
+// endMaintenance sends summaries of maintenance windows which period has ended to their users and schedules
+// next periods of recurring windows
+func (p *poller) endMaintenance(ctx context.Context) error {
+	windows, err := p.r.Maintenance.GetEnded(ctx)
+	if err != nil {
+		return err
+	}
+	if len(windows) == 0 {
+		return nil
+	}
+	log.Info().Msgf("Ended maintenance windows: %+v", windows)
+
+	notifications := make([]entity.Notification, len(windows))
+	for i, window := range windows {
+		email := window.UserEmail
+		n := entity.Notification{
+			CheckName:        window.Name,
+			FlipTo:           entity.NotificationMaintenanceEnded,
+			FlipDate:         *window.NextEnd,
+			MaintenanceStart: window.NextStart,
+			MaintenanceFlips: window.Flips,
+			CheckChannels:    entity.Channels{{Kind: entity.EmailChannel, Email: &email}},
+		}
+		notifications[i] = n
+
+		var start, end *time.Time
+		if window.Recurring() {
+			next, err := schedule.Next(entity.Schedule{
+				Kind:     entity.ScheduleCron,
+				Cron:     window.Cron,
+				Timezone: window.Timezone,
+			}, *window.NextEnd)
+			if err != nil {
+				log.Err(err).Msgf("Calculating next period failed for maintenance window %v", window.Id)
+			} else {
+				nextEnd := next.Add(time.Second * time.Duration(window.Duration))
+				start, end = &next, &nextEnd
+			}
 		}
-		err = p.r.Flip.SetProcessed(ctx, flipIds)
+		err = p.r.Maintenance.SetNext(ctx, window.Id, start, end)
 		if err != nil {
 			return err
 		}
+	}
+
+	return p.publish(ctx, notifications)
+}
 
+// closeEscalations cancels pending steps of escalations of checks which are up again. Up flips of the checks are
+// sent to channels of the steps notified already instead of channels of the checks, since only they have been
+// notified about the down flips
+func (p *poller) closeEscalations(ctx context.Context, flips []entity.FlipUnprocessed) error {
+	var checkIds []string
+	for _, flip := range flips {
+		if flip.To == entity.FlipUp {
+			checkIds = append(checkIds, flip.CheckId)
+		}
+	}
+	if len(checkIds) == 0 {
 		return nil
-	})
+	}
+
+	closed, err := p.r.Escalation.Close(ctx, checkIds)
 	if err != nil {
-		log.Err(err).Msg("Polling transaction error")
+		return err
 	}
-	log.Info().Msg("Poll end")
+	escalated := make(map[string]entity.Channels, len(closed))
+	for _, escalation := range closed {
+		escalated[escalation.CheckId] = escalation.Channels
+	}
+
+	for i, flip := range flips {
+		if channels, ok := escalated[flip.CheckId]; ok && flip.To == entity.FlipUp {
+			flips[i].CheckChannels = channels
+		}
+	}
+
+	return nil
+}
+
+// escalate sends down notifications to channels of escalation steps which delay has passed. Escalations of checks
+// which are not down anymore are closed first, so checks don't escalate further once they are up or paused
+func (p *poller) escalate(ctx context.Context) error {
+	// checks which are not down anymore without up flip, e.g. paused ones, don't escalate further
+	err := p.r.Escalation.CloseStale(ctx)
+	if err != nil {
+		return err
+	}
+
+	steps, err := p.r.Escalation.GetDue(ctx)
+	if err != nil {
+		return err
+	}
+	if len(steps) == 0 {
+		return nil
+	}
+	log.Info().Msgf("Due escalation steps: %+v", steps)
+
+	notifications := make([]entity.Notification, len(steps))
+	next := make(map[int]int)
+	for i, step := range steps {
+		n := entity.Notification{
+			CheckId:       step.CheckId,
+			CheckName:     step.CheckName,
+			FlipTo:        entity.NotificationFlipDown,
+			FlipReason:    step.FlipReason,
+			ExitCode:      step.ExitCode,
+			FlipDate:      step.FlipDate,
+			IncidentId:    step.IncidentId,
+			CheckChannels: step.Channels,
+		}
+		notifications[i] = n
+
+		// steps are ordered by position
+		next[step.EscalationId] = step.Position + 1
+	}
+
+	for id, step := range next {
+		err = p.r.Escalation.Advance(ctx, id, step)
+		if err != nil {
+			return err
+		}
+	}
+
+	return p.publish(ctx, notifications)
 }
 
 func (p *poller) sendToQueue(
 	ctx context.Context,
 	expired []entity.CheckExpired,
 	newFlips []entity.CreateFlip,
+	incidents []int,
 	flips []entity.FlipUnprocessed,
+	escalated []string,
 ) error {
-	notifications := make([][]byte, 0, len(expired)+len(flips))
+	// down flips of escalated checks are sent by escalation steps
+	skip := make(map[string]bool, len(escalated))
+	for _, checkId := range escalated {
+		skip[checkId] = true
+	}
+
+	notifications := make([]entity.Notification, 0, len(expired)+len(flips))
 	for _, flip := range flips {
+		if flip.To == entity.FlipDown && skip[flip.CheckId] {
+			continue
+		}
+
 		n := entity.Notification{
+			CheckId:       flip.CheckId,
 			CheckName:     flip.CheckName,
 			FlipTo:        entity.NotificationFlipStatus(flip.To),
+			FlipReason:    flip.Reason,
+			ExitCode:      flip.ExitCode,
 			FlipDate:      flip.Date,
+			CheckStatus:   flip.CheckStatus,
+			IncidentId:    flip.IncidentId,
 			CheckChannels: flip.CheckChannels,
 		}
-		j, err := json.Marshal(n)
-		if err != nil {
-			return err
-		}
-		notifications = append(notifications, j)
+		notifications = append(notifications, n)
 	}
 	for i, check := range expired {
+		if newFlips[i].Suppressed || skip[check.Id] {
+			continue
+		}
+
 		n := entity.Notification{
+			CheckId:       check.Id,
 			CheckName:     check.Name,
 			FlipTo:        entity.NotificationFlipStatus(newFlips[i].To),
+			FlipReason:    newFlips[i].Reason,
 			FlipDate:      newFlips[i].Date,
+			IncidentId:    &incidents[i],
 			CheckChannels: check.Channels,
 		}
+		notifications = append(notifications, n)
+	}
+
+	return p.publish(ctx, notifications)
+}
+
+// sendLateToQueue sends "running late" notifications of the checks to queue
+func (p *poller) sendLateToQueue(ctx context.Context, checks []entity.CheckRunningLate) error {
+	notifications := make([]entity.Notification, 0, len(checks))
+	for _, check := range checks {
+		if check.InMaintenance {
+			continue
+		}
+
+		n := entity.Notification{
+			CheckId:       check.Id,
+			CheckName:     check.Name,
+			FlipTo:        entity.NotificationFlipLate,
+			FlipDate:      check.NextPing,
+			CheckChannels: check.Channels,
+		}
+		notifications = append(notifications, n)
+	}
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	return p.publish(ctx, notifications)
+}
+
+// remind sends "still down" reminders of down checks which reminder interval has passed to queue
+func (p *poller) remind(ctx context.Context) error {
+	reminders, err := p.r.Check.Remind(ctx)
+	if err != nil {
+		return err
+	}
+	if len(reminders) == 0 {
+		return nil
+	}
+	log.Info().Msgf("Reminders: %+v", reminders)
+
+	notifications := make([]entity.Notification, len(reminders))
+	for i, reminder := range reminders {
+		n := entity.Notification{
+			CheckId:        reminder.CheckId,
+			CheckName:      reminder.Name,
+			FlipTo:         entity.NotificationFlipStillDown,
+			FlipDate:       reminder.DownSince,
+			CheckChannels:  reminder.Channels,
+			Reminder:       reminder.Reminder,
+			RemindersLimit: reminder.Limit,
+		}
+		notifications[i] = n
+	}
+
+	return p.publish(ctx, notifications)
+}
+
+// warnRuns sends warnings about runs which have lasted longer than slow run threshold of their checks or less than
+// their min duration to queue. Runs of checks in maintenance are not warned about
+func (p *poller) warnRuns(ctx context.Context) error {
+	pings, err := p.r.Ping.TakeWarnings(ctx)
+	if err != nil {
+		return err
+	}
+	if len(pings) == 0 {
+		return nil
+	}
+	log.Info().Msgf("Run warnings: %+v", pings)
+
+	notifications := make([]entity.Notification, 0, len(pings))
+	for _, ping := range pings {
+		if ping.InMaintenance {
+			continue
+		}
+
+		n := entity.Notification{
+			CheckId:       ping.CheckId,
+			CheckName:     ping.CheckName,
+			FlipDate:      ping.Date,
+			CheckChannels: ping.Channels,
+			Duration:      ping.Duration,
+		}
+		if ping.SlowThreshold != nil {
+			n.FlipTo = entity.NotificationSlowRun
+			n.SlowThreshold = *ping.SlowThreshold
+		} else {
+			n.FlipTo = entity.NotificationFastRun
+			n.MinDuration = *ping.FastThreshold
+		}
+		notifications = append(notifications, n)
+	}
+
+	return p.publish(ctx, notifications)
+}
+
+// publish sends the notifications to queue, details of their checks and bodies of the last pings of the checks are
+// added to them
+func (p *poller) publish(ctx context.Context, notifications []entity.Notification) error {
+	var checkIds []string
+	for _, n := range notifications {
+		if n.CheckId != "" {
+			checkIds = append(checkIds, n.CheckId)
+		}
+	}
+	bodies := make(map[string]string)
+	details := make(map[string]entity.CheckDetails)
+	if len(checkIds) > 0 {
+		var err error
+		bodies, err = p.r.Ping.GetLastBodies(ctx, checkIds)
+		if err != nil {
+			return err
+		}
+		details, err = p.r.Check.GetDetails(ctx, checkIds)
+		if err != nil {
+			return err
+		}
+	}
+
+	messages := make([][]byte, len(notifications))
+	for i, n := range notifications {
+		n.LastPingBody = bodies[n.CheckId]
+		if d, ok := details[n.CheckId]; ok {
+			n.CheckDescription = d.Description
+			n.CheckTags = d.Tags
+			n.CheckSeverity = d.Severity
+		}
 		j, err := json.Marshal(n)
 		if err != nil {
 			return err
 		}
-		notifications = append(notifications, j)
+		messages[i] = j
 	}
 
-	return p.q.PublishBatch(ctx, notifications)
+	return p.q.PublishBatch(ctx, messages)
 }
 
 func (p *poller) shutdown() {
@@ -9,6 +9,25 @@ import (
 	"gitlab.com/grygoryz/uptime-checker/internal/utility/errors"
 )
 
+// channel is JSON object of channel aliased as c with the fields its notifications are sent by
+const channel = `json_build_object(
+          'id', c.id,
+          'kind', c.kind,
+          'email', c.email,
+          'webhook_url_up', c.webhook_url_up,
+          'webhook_url_down', c.webhook_url_down,
+          'webhook_url', c.webhook_url,
+          'webhook_method', c.webhook_method,
+          'webhook_headers', c.webhook_headers,
+          'webhook_body', c.webhook_body,
+          'webhook_secret', c.webhook_secret,
+          'slack_webhook_url', c.slack_webhook_url,
+          'telegram_bot_token', c.telegram_bot_token,
+          'telegram_chat_id', c.telegram_chat_id,
+          'pagerduty_routing_key', c.pagerduty_routing_key,
+          'notify_late', c.notify_late
+      )`
+
 type channelRepository struct {
 	db *sqlx.DB
 }
@@ -25,11 +44,72 @@ func (r *channelRepository) Create(ctx context.Context, channel entity.CreateCha
 	var id int
 	switch channel.Kind {
 	case entity.EmailChannel:
-		query := "INSERT INTO channels (kind, email, user_id) VALUES ($1, $2, $3) RETURNING id"
-		err = q.QueryRowxContext(ctx, query, channel.Kind, channel.Email, channel.UserId).Scan(&id)
+		query := `INSERT INTO channels (kind, email, notify_late, reminder_interval, user_id)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`
+		err = q.QueryRowxContext(
+			ctx,
+			query,
+			channel.Kind,
+			channel.Email,
+			channel.NotifyLate,
+			channel.ReminderInterval,
+			channel.UserId,
+		).Scan(&id)
 	case entity.WebhookChannel:
-		query := "INSERT INTO channels (kind, webhook_url_up, webhook_url_down, user_id) VALUES ($1, $2, $3, $4) RETURNING id"
-		err = q.QueryRowxContext(ctx, query, channel.Kind, channel.WebhookURLUp, channel.WebhookURLDown, channel.UserId).Scan(&id)
+		query := `INSERT INTO channels (kind, webhook_url_up, webhook_url_down, webhook_url, webhook_method,
+		webhook_headers, webhook_body, notify_late, reminder_interval, user_id)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), NULLIF($4, ''), $5, $6, NULLIF($7, ''), $8, $9, $10) RETURNING id`
+		err = q.QueryRowxContext(
+			ctx,
+			query,
+			channel.Kind,
+			channel.WebhookURLUp,
+			channel.WebhookURLDown,
+			channel.URL,
+			channel.Method,
+			channel.Headers,
+			channel.Body,
+			channel.NotifyLate,
+			channel.ReminderInterval,
+			channel.UserId,
+		).Scan(&id)
+	case entity.SlackChannel:
+		query := `INSERT INTO channels (kind, slack_webhook_url, notify_late, reminder_interval, user_id)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`
+		err = q.QueryRowxContext(
+			ctx,
+			query,
+			channel.Kind,
+			channel.SlackWebhookURL,
+			channel.NotifyLate,
+			channel.ReminderInterval,
+			channel.UserId,
+		).Scan(&id)
+	case entity.TelegramChannel:
+		query := `INSERT INTO channels (kind, telegram_bot_token, telegram_chat_id, notify_late, reminder_interval, user_id)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+		err = q.QueryRowxContext(
+			ctx,
+			query,
+			channel.Kind,
+			channel.TelegramBotToken,
+			channel.TelegramChatId,
+			channel.NotifyLate,
+			channel.ReminderInterval,
+			channel.UserId,
+		).Scan(&id)
+	case entity.PagerDutyChannel:
+		query := `INSERT INTO channels (kind, pagerduty_routing_key, notify_late, reminder_interval, user_id)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`
+		err = q.QueryRowxContext(
+			ctx,
+			query,
+			channel.Kind,
+			channel.PagerDutyRoutingKey,
+			channel.NotifyLate,
+			channel.ReminderInterval,
+			channel.UserId,
+		).Scan(&id)
 	default:
 		return 0, fmt.Errorf("invalid channel kind: %v", channel.Kind)
 	}
@@ -48,11 +128,91 @@ func (r *channelRepository) Update(ctx context.Context, channel entity.Channel)
 	var err error
 	switch channel.Kind {
 	case entity.EmailChannel:
-		query := "UPDATE channels SET kind = $1, email = $2, webhook_url_up = null, webhook_url_down = null WHERE id = $3 AND user_id = $4"
-		result, err = q.ExecContext(ctx, query, channel.Kind, channel.Email, channel.Id, channel.UserId)
+		query := `UPDATE channels SET kind = $1, email = $2, webhook_url_up = null, webhook_url_down = null, webhook_url = null,
+		webhook_method = 'GET', webhook_headers = null, webhook_body = null, slack_webhook_url = null,
+		telegram_bot_token = null, telegram_chat_id = null, pagerduty_routing_key = null, notify_late = $3,
+		reminder_interval = $4
+		WHERE id = $5 AND user_id = $6`
+		result, err = q.ExecContext(
+			ctx,
+			query,
+			channel.Kind,
+			channel.Email,
+			channel.NotifyLate,
+			channel.ReminderInterval,
+			channel.Id,
+			channel.UserId,
+		)
 	case entity.WebhookChannel:
-		query := "UPDATE channels SET kind = $1, webhook_url_up = $2, webhook_url_down = $3, email = null WHERE id = $4 AND user_id = $5"
-		result, err = q.ExecContext(ctx, query, channel.Kind, channel.WebhookURLUp, channel.WebhookURLDown, channel.Id, channel.UserId)
+		query := `UPDATE channels SET kind = $1, webhook_url_up = NULLIF($2, ''), webhook_url_down = NULLIF($3, ''),
+		webhook_url = NULLIF($4, ''), webhook_method = $5, webhook_headers = $6, webhook_body = NULLIF($7, ''),
+		email = null, slack_webhook_url = null, telegram_bot_token = null, telegram_chat_id = null,
+		pagerduty_routing_key = null, notify_late = $8, reminder_interval = $9
+		WHERE id = $10 AND user_id = $11`
+		result, err = q.ExecContext(
+			ctx,
+			query,
+			channel.Kind,
+			channel.WebhookURLUp,
+			channel.WebhookURLDown,
+			channel.URL,
+			channel.Method,
+			channel.Headers,
+			channel.Body,
+			channel.NotifyLate,
+			channel.ReminderInterval,
+			channel.Id,
+			channel.UserId,
+		)
+	case entity.SlackChannel:
+		query := `UPDATE channels SET kind = $1, slack_webhook_url = $2, email = null, webhook_url_up = null,
+		webhook_url_down = null, webhook_url = null, webhook_method = 'GET', webhook_headers = null, webhook_body = null,
+		telegram_bot_token = null, telegram_chat_id = null, pagerduty_routing_key = null, notify_late = $3,
+		reminder_interval = $4
+		WHERE id = $5 AND user_id = $6`
+		result, err = q.ExecContext(
+			ctx,
+			query,
+			channel.Kind,
+			channel.SlackWebhookURL,
+			channel.NotifyLate,
+			channel.ReminderInterval,
+			channel.Id,
+			channel.UserId,
+		)
+	case entity.TelegramChannel:
+		query := `UPDATE channels SET kind = $1, telegram_bot_token = $2, telegram_chat_id = $3, email = null,
+		webhook_url_up = null, webhook_url_down = null, webhook_url = null, webhook_method = 'GET', webhook_headers = null,
+		webhook_body = null, slack_webhook_url = null, pagerduty_routing_key = null, notify_late = $4,
+		reminder_interval = $5
+		WHERE id = $6 AND user_id = $7`
+		result, err = q.ExecContext(
+			ctx,
+			query,
+			channel.Kind,
+			channel.TelegramBotToken,
+			channel.TelegramChatId,
+			channel.NotifyLate,
+			channel.ReminderInterval,
+			channel.Id,
+			channel.UserId,
+		)
+	case entity.PagerDutyChannel:
+		query := `UPDATE channels SET kind = $1, pagerduty_routing_key = $2, email = null, webhook_url_up = null,
+		webhook_url_down = null, webhook_url = null, webhook_method = 'GET', webhook_headers = null, webhook_body = null,
+		slack_webhook_url = null, telegram_bot_token = null, telegram_chat_id = null, notify_late = $3,
+		reminder_interval = $4
+		WHERE id = $5 AND user_id = $6`
+		result, err = q.ExecContext(
+			ctx,
+			query,
+			channel.Kind,
+			channel.PagerDutyRoutingKey,
+			channel.NotifyLate,
+			channel.ReminderInterval,
+			channel.Id,
+			channel.UserId,
+		)
 	default:
 		return fmt.Errorf("invalid channel kind: %v", channel.Kind)
 	}
@@ -73,7 +233,11 @@ func (r *channelRepository) GetMany(ctx context.Context, userId int) ([]entity.C
 	q := getQueryable(ctx, r.db)
 	var channels []entity.ChannelShort
 
-	query := "SELECT id, kind, email, webhook_url_up, webhook_url_down FROM channels WHERE user_id = $1"
+	query := `SELECT id, kind, email, webhook_url_up, webhook_url_down, webhook_url, webhook_method, webhook_headers,
+	webhook_body, webhook_secret, slack_webhook_url, telegram_bot_token, telegram_chat_id, pagerduty_routing_key,
+	notify_late, reminder_interval
+	FROM channels
+	WHERE user_id = $1`
 	err := q.SelectContext(ctx, &channels, query, userId)
 	if err != nil {
 		return nil, err
@@ -82,6 +246,26 @@ func (r *channelRepository) GetMany(ctx context.Context, userId int) ([]entity.C
 	return channels, nil
 }
 
+// RotateWebhookSecret replaces secret of webhook channel with a new random one and returns it
+func (r *channelRepository) RotateWebhookSecret(ctx context.Context, params entity.RotateWebhookSecret) (string, error) {
+	q := getQueryable(ctx, r.db)
+	var secret string
+
+	query := `UPDATE channels
+	SET webhook_secret = replace(gen_random_uuid()::text, '-', '') || replace(gen_random_uuid()::text, '-', '')
+	WHERE id = $1 AND user_id = $2 AND kind = 'webhook'
+	RETURNING webhook_secret`
+	err := q.GetContext(ctx, &secret, query, params.Id, params.UserId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = errors.E(errors.NotExist, "webhook channel not found")
+		}
+		return "", err
+	}
+
+	return secret, nil
+}
+
 // Delete deletes channel by id
 func (r *channelRepository) Delete(ctx context.Context, channel entity.DeleteChannel) error {
 	q := getQueryable(ctx, r.db)
@@ -20,6 +20,50 @@ func NewCheck(db *sqlx.DB) *checkRepository {
 	return &checkRepository{db}
 }
 
+// bodyRules are body rules of check with id column in their order
+const bodyRules = `(SELECT json_agg(json_build_object(
+          'match', match,
+          'pattern', pattern,
+          'outcome', outcome
+      ) ORDER BY position)
+       FROM body_rules
+       WHERE check_id = id)`
+
+// metrics are metrics of check with id column in their order
+const metrics = `(SELECT json_agg(json_build_object(
+          'name', name,
+          'path', path
+      ) ORDER BY position)
+       FROM check_metrics
+       WHERE check_id = id)`
+
+// metricRules are metric rules of check with id column in their order
+const metricRules = `(SELECT json_agg(json_build_object(
+          'metric', metric,
+          'op', op,
+          'threshold', threshold,
+          'outcome', outcome
+      ) ORDER BY position)
+       FROM metric_rules
+       WHERE check_id = id)`
+
+// instances are instances of fleet check with id column ordered by name
+const instances = `(SELECT json_agg(json_build_object(
+          'name', name,
+          'status', status,
+          'last_ping', last_ping,
+          'next_ping', next_ping
+      ) ORDER BY name)
+       FROM check_instances
+       WHERE check_id = id)`
+
+// statusAfterRun is status of check which run has ended without changing it: started check gets back the status
+// it had before the run, others keep theirs
+const statusAfterRun = `CASE status
+       WHEN 'started' THEN coalesce(status_before_start, status)
+       ELSE status
+   END`
+
 // GetMany returns user's checks
 func (r *checkRepository) GetMany(ctx context.Context, userId int) ([]entity.Check, error) {
 	q := getQueryable(ctx, r.db)
@@ -27,23 +71,49 @@ func (r *checkRepository) GetMany(ctx context.Context, userId int) ([]entity.Che
 
 	query := `SELECT id,
       "name",
+      slug,
       description,
+      tags,
+      schedule,
       "interval",
+      cron,
+      timezone,
       grace,
+      max_duration,
+      first_ping_deadline,
+      failure_threshold,
+      failures,
+      flap_threshold,
+      flap_window,
+      flap_stable,
+      flapping_since,
+      escalation_policy_id,
+      reminder_interval,
+      reminder_limit,
+      reminders_stopped_at,
+      slow_duration,
+      slow_factor,
+      min_duration,
+      min_duration_action,
+      fleet,
+      fleet_tolerance,
+      instance_ttl,
+      severity,
+      created_at,
+      pause_policy,
+      resume_at,
       last_ping,
       next_ping,
       last_started,
       status,
-      (SELECT json_agg(json_build_object(
-          'id', id,
-          'kind', kind,
-          'email', email,
-          'webhook_url_up', webhook_url_up,
-          'webhook_url_down', webhook_url_down
-      )) channels
+      (SELECT json_agg(` + channel + `) channels
        FROM checks_channels
-       INNER JOIN channels on checks_channels.channel_id = channels.id
-       WHERE checks_channels.check_id = checks.id) channels
+       INNER JOIN channels c on checks_channels.channel_id = c.id
+       WHERE checks_channels.check_id = checks.id) channels,
+      ` + bodyRules + ` body_rules,
+      ` + metrics + ` metrics,
+      ` + metricRules + ` metric_rules,
+      ` + instances + ` instances
 		FROM checks
 		WHERE used_id = $1`
 	err := q.SelectContext(ctx, &checks, query, userId)
@@ -61,23 +131,49 @@ func (r *checkRepository) Get(ctx context.Context, params entity.GetCheck) (enti
 
 	query := `SELECT id,
       "name",
+      slug,
       description,
+      tags,
+      schedule,
       "interval",
+      cron,
+      timezone,
       grace,
+      max_duration,
+      first_ping_deadline,
+      failure_threshold,
+      failures,
+      flap_threshold,
+      flap_window,
+      flap_stable,
+      flapping_since,
+      escalation_policy_id,
+      reminder_interval,
+      reminder_limit,
+      reminders_stopped_at,
+      slow_duration,
+      slow_factor,
+      min_duration,
+      min_duration_action,
+      fleet,
+      fleet_tolerance,
+      instance_ttl,
+      severity,
+      created_at,
+      pause_policy,
+      resume_at,
       last_ping,
       next_ping,
       last_started,
       status,
-      (SELECT json_agg(json_build_object(
-          'id', id,
-          'kind', kind,
-          'email', email,
-          'webhook_url_up', webhook_url_up,
-          'webhook_url_down', webhook_url_down
-      ))
+      (SELECT json_agg(` + channel + `)
        FROM checks_channels
-       INNER JOIN channels on checks_channels.channel_id = channels.id
-       WHERE checks_channels.check_id = checks.id) channels
+       INNER JOIN channels c on checks_channels.channel_id = c.id
+       WHERE checks_channels.check_id = checks.id) channels,
+      ` + bodyRules + ` body_rules,
+      ` + metrics + ` metrics,
+      ` + metricRules + ` metric_rules,
+      ` + instances + ` instances
 		FROM checks
 		WHERE id = $1 AND used_id = $2`
 	err := q.GetContext(ctx, &check, query, params.Id, params.UserId)
@@ -96,21 +192,53 @@ func (r *checkRepository) Create(ctx context.Context, check entity.CreateCheck)
 	q := getQueryable(ctx, r.db)
 
 	var id string
-	query := `INSERT INTO checks ("name", description, "interval", grace, status, used_id)
-	VALUES ($1, $2, $3, $4, 'new', $5)
+	query := `INSERT INTO checks
+    ("name", slug, description, tags, schedule, "interval", cron, timezone, grace, max_duration, first_ping_deadline,
+     failure_threshold, flap_threshold, flap_window, flap_stable, escalation_policy_id, reminder_interval, reminder_limit,
+     slow_duration, slow_factor, min_duration, min_duration_action, fleet, fleet_tolerance, instance_ttl, severity,
+     pause_policy, status, used_id)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23,
+	        $24, $25, $26, $27, 'new', $28)
 	RETURNING id`
 	err := q.
 		QueryRowxContext(
 			ctx,
 			query,
 			check.Name,
+			check.Slug,
 			check.Description,
+			check.Tags,
+			check.Schedule,
 			check.Interval,
+			check.Cron,
+			check.Timezone,
 			check.Grace,
+			check.MaxDuration,
+			check.FirstPingDeadline,
+			check.FailureThreshold,
+			check.FlapThreshold,
+			check.FlapWindow,
+			check.FlapStable,
+			check.EscalationPolicyId,
+			check.ReminderInterval,
+			check.ReminderLimit,
+			check.SlowDuration,
+			check.SlowFactor,
+			check.MinDuration,
+			check.MinDurationAction,
+			check.Fleet,
+			check.FleetTolerance,
+			check.InstanceTTL,
+			check.Severity,
+			check.PausePolicy,
 			check.UserId,
 		).
 		Scan(&id)
 	if err != nil {
+		if isUniqueViolation(err) {
+			return "", errors.E(errors.Duplicated, "check with this slug exists already")
+		}
+
 		return "", err
 	}
 
@@ -121,11 +249,73 @@ func (r *checkRepository) Create(ctx context.Context, check entity.CreateCheck)
 func (r *checkRepository) Update(ctx context.Context, check entity.UpdateCheck) error {
 	q := getQueryable(ctx, r.db)
 
-	query := `UPDATE checks SET "name" = $1, description = $2, "interval" = $3, grace = $4 WHERE id = $5 AND used_id = $6`
+	query := `UPDATE checks
+	SET "name"               = $1,
+	    slug                 = $2,
+	    description          = $3,
+	    tags                 = $4,
+	    schedule             = $5,
+	    "interval"           = $6,
+	    cron                 = $7,
+	    timezone             = $8,
+	    grace                = $9,
+	    max_duration         = $10,
+	    first_ping_deadline  = $11,
+	    failure_threshold    = $12,
+	    flap_threshold       = $13,
+	    flap_window          = $14,
+	    flap_stable          = $15,
+	    escalation_policy_id = $16,
+	    reminder_interval    = $17,
+	    reminder_limit       = $18,
+	    slow_duration        = $19,
+	    slow_factor          = $20,
+	    min_duration         = $21,
+	    min_duration_action  = $22,
+	    fleet                = $23,
+	    fleet_tolerance      = $24,
+	    instance_ttl         = $25,
+	    severity             = $26,
+	    pause_policy         = $27
+	WHERE id = $28 AND used_id = $29`
 	result, err := q.ExecContext(
-		ctx, query, check.Name, check.Description, check.Interval, check.Grace, check.Id, check.UserId,
+		ctx,
+		query,
+		check.Name,
+		check.Slug,
+		check.Description,
+		check.Tags,
+		check.Schedule,
+		check.Interval,
+		check.Cron,
+		check.Timezone,
+		check.Grace,
+		check.MaxDuration,
+		check.FirstPingDeadline,
+		check.FailureThreshold,
+		check.FlapThreshold,
+		check.FlapWindow,
+		check.FlapStable,
+		check.EscalationPolicyId,
+		check.ReminderInterval,
+		check.ReminderLimit,
+		check.SlowDuration,
+		check.SlowFactor,
+		check.MinDuration,
+		check.MinDurationAction,
+		check.Fleet,
+		check.FleetTolerance,
+		check.InstanceTTL,
+		check.Severity,
+		check.PausePolicy,
+		check.Id,
+		check.UserId,
 	)
 	if err != nil {
+		if isUniqueViolation(err) {
+			return errors.E(errors.Duplicated, "check with this slug exists already")
+		}
+
 		return err
 	}
 
@@ -161,6 +351,26 @@ func (r *checkRepository) Delete(ctx context.Context, check entity.DeleteCheck)
 	return nil
 }
 
+// GetIdBySlug returns id of check with the slug which belongs to the user with the ping key
+func (r *checkRepository) GetIdBySlug(ctx context.Context, params entity.GetCheckBySlug) (string, error) {
+	q := getQueryable(ctx, r.db)
+	var id string
+
+	query := `SELECT ch.id
+	FROM checks ch
+	INNER JOIN users u on u.id = ch.used_id
+	WHERE u.ping_key = $1 AND ch.slug = $2`
+	err := q.GetContext(ctx, &id, query, params.PingKey, params.Slug)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = errors.E(errors.NotExist, "check not found")
+		}
+		return "", err
+	}
+
+	return id, nil
+}
+
 // GetStatus returns check's status
 func (r *checkRepository) GetStatus(ctx context.Context, checkId string) (entity.CheckStatus, error) {
 	q := getQueryable(ctx, r.db)
@@ -178,6 +388,69 @@ func (r *checkRepository) GetStatus(ctx context.Context, checkId string) (entity
 	return status, nil
 }
 
+// GetDetails returns details of the checks added to their notifications by check id
+func (r *checkRepository) GetDetails(ctx context.Context, checkIds []string) (map[string]entity.CheckDetails, error) {
+	q := getQueryable(ctx, r.db)
+	var checks []entity.CheckDetails
+
+	query, args, err := sqlx.In(`SELECT id, description, tags, severity FROM checks WHERE id IN (?)`, checkIds)
+	if err != nil {
+		return nil, err
+	}
+	query = r.db.Rebind(query)
+	err = q.SelectContext(ctx, &checks, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	details := make(map[string]entity.CheckDetails, len(checks))
+	for _, check := range checks {
+		details[check.Id] = check
+	}
+	return details, nil
+}
+
+// GetForPing returns check's data required to apply a ping
+func (r *checkRepository) GetForPing(ctx context.Context, checkId string) (entity.CheckForPing, error) {
+	q := getQueryable(ctx, r.db)
+	var check entity.CheckForPing
+
+	query := `SELECT status,
+       ` + statusAfterRun + ` status_before_run,
+       pause_policy,
+       failure_threshold,
+       failures,
+       schedule,
+       "interval",
+       cron,
+       timezone,
+       flap_threshold,
+       flap_window,
+       flapping_since,
+       slow_duration,
+       slow_factor,
+       min_duration,
+       min_duration_action,
+       fleet,
+       fleet_tolerance,
+       instance_ttl,
+       ` + bodyRules + ` body_rules,
+       ` + metrics + ` metrics,
+       ` + metricRules + ` metric_rules,
+       ` + inMaintenance + ` in_maintenance
+	FROM checks ch
+	WHERE id = $1`
+	err := q.GetContext(ctx, &check, query, checkId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = errors.E(errors.NotExist, "check not found")
+		}
+		return check, err
+	}
+
+	return check, nil
+}
+
 // SetStatus sets check's status
 func (r *checkRepository) SetStatus(ctx context.Context, check entity.SetCheckStatus) error {
 	q := getQueryable(ctx, r.db)
@@ -199,6 +472,86 @@ func (r *checkRepository) SetStatus(ctx context.Context, check entity.SetCheckSt
 	return nil
 }
 
+// Pause pauses check until it's resumed manually, by ping or at resume time if it's set
+func (r *checkRepository) Pause(ctx context.Context, check entity.PauseCheck) error {
+	q := getQueryable(ctx, r.db)
+
+	query := `UPDATE checks SET status = 'paused', resume_at = $1 WHERE id = $2 AND used_id = $3`
+	result, err := q.ExecContext(ctx, query, check.ResumeAt, check.Id, check.UserId)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected != 1 {
+		return errors.E(errors.NotExist, "check not found")
+	}
+
+	return nil
+}
+
+// Resume resumes paused check. Resumed check is considered up and expects next ping at nextPing
+func (r *checkRepository) Resume(ctx context.Context, checkId string, nextPing time.Time) error {
+	q := getQueryable(ctx, r.db)
+
+	query := `UPDATE checks
+	SET next_ping    = $1,
+   	last_started = NULL,
+   	resume_at    = NULL,
+   	status       = 'up'
+	WHERE id = $2 AND status = 'paused'`
+	result, err := q.ExecContext(ctx, query, nextPing, checkId)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected != 1 {
+		return errors.E(errors.NotExist, "check not found or it's not paused")
+	}
+
+	return nil
+}
+
+// SetNextPing sets time of the next expected ping of check. Late check is up again, since its deadline is moved
+func (r *checkRepository) SetNextPing(ctx context.Context, checkId string, next time.Time) error {
+	q := getQueryable(ctx, r.db)
+
+	query := `UPDATE checks
+	SET next_ping = $1,
+	    status    = CASE WHEN status = 'late' THEN 'up' ELSE status END
+	WHERE id = $2`
+	_, err := q.ExecContext(ctx, query, next, checkId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetToResume returns paused checks which resume time has come
+func (r *checkRepository) GetToResume(ctx context.Context) ([]entity.CheckToResume, error) {
+	q := getQueryable(ctx, r.db)
+	var checks []entity.CheckToResume
+
+	query := `SELECT id, schedule, "interval", cron, timezone
+	FROM checks
+	WHERE status = 'paused' AND current_timestamp >= resume_at
+	FOR UPDATE SKIP LOCKED`
+	err := q.SelectContext(ctx, &checks, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return checks, nil
+}
+
 type checkChannel struct {
 	CheckId   string `db:"check_id"`
 	ChannelId int    `db:"channel_id"`
@@ -227,6 +580,62 @@ func (r *checkRepository) AddChannels(ctx context.Context, params entity.AddChan
 	return nil
 }
 
+// SetBodyRules replaces check's body rules
+func (r *checkRepository) SetBodyRules(ctx context.Context, params entity.SetBodyRules) error {
+	q := getQueryable(ctx, r.db)
+
+	_, err := q.ExecContext(ctx, "DELETE FROM body_rules WHERE check_id = $1", params.CheckId)
+	if err != nil {
+		return err
+	}
+
+	query := "INSERT INTO body_rules (check_id, position, match, pattern, outcome) VALUES ($1, $2, $3, $4, $5)"
+	for i, rule := range params.Rules {
+		_, err = q.ExecContext(ctx, query, params.CheckId, i, rule.Match, rule.Pattern, rule.Outcome)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetMetrics replaces check's metrics and metric rules
+func (r *checkRepository) SetMetrics(ctx context.Context, params entity.SetMetrics) error {
+	q := getQueryable(ctx, r.db)
+
+	_, err := q.ExecContext(ctx, "DELETE FROM check_metrics WHERE check_id = $1", params.CheckId)
+	if err != nil {
+		return err
+	}
+	_, err = q.ExecContext(ctx, "DELETE FROM metric_rules WHERE check_id = $1", params.CheckId)
+	if err != nil {
+		return err
+	}
+
+	query := "INSERT INTO check_metrics (check_id, position, name, path) VALUES ($1, $2, $3, $4)"
+	for i, metric := range params.Metrics {
+		_, err = q.ExecContext(ctx, query, params.CheckId, i, metric.Name, metric.Path)
+		if err != nil {
+			if isUniqueViolation(err) {
+				return errors.E(errors.Validation, "metric names must be unique")
+			}
+			return err
+		}
+	}
+
+	query = `INSERT INTO metric_rules (check_id, position, metric, op, threshold, outcome)
+	VALUES ($1, $2, $3, $4, $5, $6)`
+	for i, rule := range params.Rules {
+		_, err = q.ExecContext(ctx, query, params.CheckId, i, rule.Metric, rule.Op, rule.Threshold, rule.Outcome)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // DeleteChannels deletes check's channels
 func (r *checkRepository) DeleteChannels(ctx context.Context, checkId string) error {
 	q := getQueryable(ctx, r.db)
@@ -247,17 +656,50 @@ func (r *checkRepository) DeleteChannels(ctx context.Context, checkId string) er
 	return nil
 }
 
-// PingSuccess applies success ping to check
-func (r *checkRepository) PingSuccess(ctx context.Context, checkId string, t time.Time) error {
+// PingSuccess applies success ping to check. Next is the time of the next expected ping calculated from
+// the check's schedule
+func (r *checkRepository) PingSuccess(ctx context.Context, checkId string, t time.Time, next time.Time) error {
 	q := getQueryable(ctx, r.db)
 
 	query := `UPDATE checks
 	SET last_ping    = $1,
-   	next_ping    = $1::timestamptz + (concat(interval, 's'))::interval,
+   	next_ping    = $2,
    	last_started = NULL,
+   	resume_at    = NULL,
+   	failures     = 0,
    	status       = 'up'
-	WHERE id = $2`
-	result, err := q.ExecContext(ctx, query, t, checkId)
+	WHERE id = $3`
+	result, err := q.ExecContext(ctx, query, t, next, checkId)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected != 1 {
+		return errors.E(errors.NotExist, "check not found")
+	}
+
+	return nil
+}
+
+// PingFailBelowThreshold applies fail ping which hasn't reached failure threshold to check. The check keeps its
+// status, started one gets back the status it had before the run and paused one is resumed. Its failure is counted
+// and next is the time of the next expected ping
+func (r *checkRepository) PingFailBelowThreshold(ctx context.Context, checkId string, t time.Time, next time.Time) error {
+	q := getQueryable(ctx, r.db)
+
+	query := `UPDATE checks
+	SET last_ping    = $1,
+   	next_ping    = $2,
+   	last_started = NULL,
+   	resume_at    = NULL,
+   	failures     = failures + 1,
+   	status       = CASE status WHEN 'paused' THEN 'up' ELSE ` + statusAfterRun + ` END
+	WHERE id = $3`
+	result, err := q.ExecContext(ctx, query, t, next, checkId)
 	if err != nil {
 		return err
 	}
@@ -267,7 +709,7 @@ func (r *checkRepository) PingSuccess(ctx context.Context, checkId string, t tim
 		return err
 	}
 	if affected != 1 {
-		return errors.E(errors.NotExist, "check not found or it's paused")
+		return errors.E(errors.NotExist, "check not found")
 	}
 
 	return nil
@@ -278,8 +720,14 @@ func (r *checkRepository) PingStart(ctx context.Context, checkId string, t time.
 	q := getQueryable(ctx, r.db)
 
 	query := `UPDATE checks
-	SET last_started = $1,
-   	status       = 'started'
+	SET last_started        = $1,
+   	resume_at           = NULL,
+   	status_before_start = CASE status
+   	    WHEN 'started' THEN status_before_start
+   	    WHEN 'paused' THEN 'up'
+   	    ELSE status
+   	END,
+   	status              = 'started'
 	WHERE id = $2`
 	result, err := q.ExecContext(ctx, query, t, checkId)
 	if err != nil {
@@ -291,7 +739,7 @@ func (r *checkRepository) PingStart(ctx context.Context, checkId string, t time.
 		return err
 	}
 	if affected != 1 {
-		return errors.E(errors.NotExist, "check not found or it's paused")
+		return errors.E(errors.NotExist, "check not found")
 	}
 
 	return nil
@@ -305,6 +753,8 @@ func (r *checkRepository) PingFail(ctx context.Context, checkId string, t time.T
 	SET last_ping    = $1,
 	    next_ping    = NULL,
    	last_started = NULL,
+   	resume_at    = NULL,
+   	failures     = failures + 1,
    	status       = 'down'
 	WHERE id = $2`
 	result, err := q.ExecContext(ctx, query, t, checkId)
@@ -317,13 +767,17 @@ func (r *checkRepository) PingFail(ctx context.Context, checkId string, t time.T
 		return err
 	}
 	if affected != 1 {
-		return errors.E(errors.NotExist, "check not found or it's paused")
+		return errors.E(errors.NotExist, "check not found")
 	}
 
 	return nil
 }
 
-// GetExpired returns expired checks
+// GetExpired returns expired checks. Next ping of a check is calculated from its schedule when the check
+// receives a success ping, so a check is expired when its next ping plus grace period has passed. A started
+// check is expired when its run lasts longer than max duration, and a new check is expired when it hasn't
+// received any ping before its first ping deadline, or before its next ping if failures below the threshold
+// have been counted for it. Only the check that has never been pinged is expired for no first ping
 func (r *checkRepository) GetExpired(ctx context.Context) ([]entity.CheckExpired, error) {
 	q := getQueryable(ctx, r.db)
 	var checks []entity.CheckExpired
@@ -331,19 +785,42 @@ func (r *checkRepository) GetExpired(ctx context.Context) ([]entity.CheckExpired
 	query := `SELECT
    ch.id,
    "name",
-   next_ping, 
-   grace,
-   (SELECT json_agg(json_build_object(
-          'kind', kind,
-          'email', email,
-          'webhook_url_up', webhook_url_up,
-          'webhook_url_down', webhook_url_down
-   )) channels
+   CASE
+       WHEN status = 'started' THEN 'timeout'
+       WHEN status = 'new' AND last_ping IS NULL THEN 'no_first_ping'
+       ELSE 'missed'
+   END reason,
+   CASE status
+       WHEN 'started' THEN last_started + (concat(max_duration, 's'))::interval
+       WHEN 'new' THEN coalesce(
+           next_ping + (concat(grace, 's'))::interval,
+           created_at + (concat(first_ping_deadline, 's'))::interval
+       )
+       ELSE next_ping + (concat(grace, 's'))::interval
+   END expired_at,
+   next_ping,
+   failure_threshold,
+   failures,
+   schedule,
+   "interval",
+   cron,
+   timezone,
+   flap_threshold,
+   flap_window,
+   flapping_since,
+   ` + inMaintenance + ` in_maintenance,
+   (SELECT json_agg(` + channel + `) channels
    FROM checks_channels
-   INNER JOIN channels on checks_channels.channel_id = channels.id
+   INNER JOIN channels c on checks_channels.channel_id = c.id
    WHERE checks_channels.check_id = ch.id) channels
    FROM checks ch
-	WHERE status = 'up' AND current_timestamp > (next_ping + (concat(grace, 's'))::interval)
+	WHERE (status IN ('up', 'late') AND current_timestamp > (next_ping + (concat(grace, 's'))::interval))
+	   OR (status = 'started' AND max_duration IS NOT NULL
+	       AND current_timestamp > (last_started + (concat(max_duration, 's'))::interval))
+	   OR (status = 'new' AND current_timestamp > coalesce(
+	       next_ping + (concat(grace, 's'))::interval,
+	       created_at + (concat(first_ping_deadline, 's'))::interval
+	   ))
 	FOR UPDATE SKIP LOCKED`
 	err := q.SelectContext(ctx, &checks, query)
 	if err != nil {
@@ -353,12 +830,45 @@ func (r *checkRepository) GetExpired(ctx context.Context) ([]entity.CheckExpired
 	return checks, nil
 }
 
-// SetDown sets checks status to down and next ping to status "down"
+// SetLate sets status of up checks which next ping has passed to late and returns them. It should be called
+// after expired checks are set down, so checks which grace period has expired too don't become late
+func (r *checkRepository) SetLate(ctx context.Context) ([]entity.CheckRunningLate, error) {
+	q := getQueryable(ctx, r.db)
+	var checks []entity.CheckRunningLate
+
+	query := `UPDATE checks ch
+	SET status = 'late'
+	WHERE id IN (
+	    SELECT id
+	    FROM checks
+	    WHERE status = 'up' AND current_timestamp > next_ping
+	    FOR UPDATE SKIP LOCKED
+	)
+	RETURNING
+	ch.id,
+	ch.name,
+	ch.next_ping,
+	` + inMaintenance + ` in_maintenance,
+	(SELECT json_agg(` + channel + `) channels
+	FROM checks_channels
+	INNER JOIN channels c on checks_channels.channel_id = c.id
+	WHERE checks_channels.check_id = ch.id) channels`
+	err := q.SelectContext(ctx, &checks, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return checks, nil
+}
+
+// SetDown sets checks status to down and resets next ping and last start
 func (r *checkRepository) SetDown(ctx context.Context, checkIds []string) error {
 	q := getQueryable(ctx, r.db)
 
 	query, args, err := sqlx.In(`UPDATE checks
 	SET next_ping    = NULL,
+   	last_started = NULL,
+   	failures     = failures + 1,
    	status       = 'down'
 	WHERE id IN (?);`, checkIds)
 	query = r.db.Rebind(query)
@@ -369,3 +879,138 @@ func (r *checkRepository) SetDown(ctx context.Context, checkIds []string) error
 
 	return nil
 }
+
+// CountMissed counts expired check's failure which hasn't reached failure threshold. The check keeps its status,
+// timed out one gets back the status it had before the run, and next is the time of the next expected ping
+func (r *checkRepository) CountMissed(ctx context.Context, checkId string, next time.Time) error {
+	q := getQueryable(ctx, r.db)
+
+	query := `UPDATE checks
+	SET next_ping    = $1,
+   	last_started = NULL,
+   	failures     = failures + 1,
+   	status       = ` + statusAfterRun + `
+	WHERE id = $2`
+	_, err := q.ExecContext(ctx, query, next, checkId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetFlapping marks check as flapping since the time
+func (r *checkRepository) SetFlapping(ctx context.Context, checkId string, since time.Time) error {
+	q := getQueryable(ctx, r.db)
+
+	_, err := q.ExecContext(ctx, "UPDATE checks SET flapping_since = $1 WHERE id = $2", since, checkId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetStable unmarks flapping checks which status hasn't changed for their stable period and returns them
+func (r *checkRepository) SetStable(ctx context.Context) ([]entity.CheckStable, error) {
+	q := getQueryable(ctx, r.db)
+	var checks []entity.CheckStable
+
+	query := `UPDATE checks ch
+	SET flapping_since = NULL
+	WHERE id IN (
+	    SELECT id
+	    FROM checks c
+	    WHERE flapping_since IS NOT NULL
+	      AND current_timestamp > (concat(flap_stable, 's'))::interval + COALESCE(
+	          (SELECT max("date") FROM flips WHERE check_id = c.id AND "to" IN ('up', 'down')),
+	          flapping_since
+	      )
+	    FOR UPDATE SKIP LOCKED
+	)
+	RETURNING ch.id, ch.status`
+	err := q.SelectContext(ctx, &checks, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return checks, nil
+}
+
+// StopReminders stops "still down" reminders of the check until it goes down next time
+func (r *checkRepository) StopReminders(ctx context.Context, params entity.StopReminders) error {
+	q := getQueryable(ctx, r.db)
+
+	query := "UPDATE checks SET reminders_stopped_at = current_timestamp WHERE id = $1 AND used_id = $2"
+	result, err := q.ExecContext(ctx, query, params.Id, params.UserId)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected != 1 {
+		return errors.E(errors.NotExist, "check not found")
+	}
+
+	return nil
+}
+
+// Remind counts "still down" reminders of down checks which reminder interval has passed since they went down
+// or since the last reminder, and returns them. Reminder interval of the check takes precedence over interval
+// of the channel, reminders of flapping checks and checks in maintenance are not sent
+func (r *checkRepository) Remind(ctx context.Context) ([]entity.CheckReminder, error) {
+	q := getQueryable(ctx, r.db)
+	var reminders []entity.CheckReminder
+
+	query := `UPDATE checks_channels
+	SET reminders     = due.sent + 1,
+	    last_reminder = current_timestamp
+	FROM (
+	    SELECT l.check_id, l.channel_id, d.down_since, s.sent
+	    FROM checks_channels l
+	    INNER JOIN checks ch on ch.id = l.check_id
+	    INNER JOIN channels c on c.id = l.channel_id
+	    CROSS JOIN LATERAL (
+	        SELECT max("date") down_since FROM flips WHERE check_id = ch.id AND "to" = 'down'
+	    ) d
+	    CROSS JOIN LATERAL (
+	        -- reminders sent before the check went down last time are not counted
+	        SELECT CASE WHEN l.last_reminder >= d.down_since THEN l.reminders ELSE 0 END sent,
+	               CASE WHEN l.last_reminder >= d.down_since THEN l.last_reminder ELSE d.down_since END last_sent
+	    ) s
+	    WHERE ch.status = 'down'
+	      AND ch.flapping_since IS NULL
+	      AND (ch.reminders_stopped_at IS NULL OR ch.reminders_stopped_at < d.down_since)
+	      AND s.sent < ch.reminder_limit
+	      AND current_timestamp >= s.last_sent +
+	          (concat(COALESCE(ch.reminder_interval, c.reminder_interval), 's'))::interval
+	      AND NOT ` + inMaintenance + `
+	    FOR UPDATE OF l SKIP LOCKED
+	) due, checks, channels c
+	WHERE checks_channels.check_id = due.check_id
+	  AND checks_channels.channel_id = due.channel_id
+	  AND checks.id = due.check_id
+	  AND c.id = due.channel_id
+	RETURNING
+	checks.id,
+	checks.name,
+	due.down_since,
+	checks_channels.reminders,
+	checks.reminder_limit,
+	json_build_array(` + channel + `) channels`
+	err := q.SelectContext(ctx, &reminders, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return reminders, nil
+}
+
+// isUniqueViolation reports whether err is caused by unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return goerrors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation
+}
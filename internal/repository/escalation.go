@@ -0,0 +1,270 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/jmoiron/sqlx"
+	"gitlab.com/grygoryz/uptime-checker/internal/entity"
+	"gitlab.com/grygoryz/uptime-checker/internal/utility/errors"
+)
+
+type escalationRepository struct {
+	db *sqlx.DB
+}
+
+func NewEscalation(db *sqlx.DB) *escalationRepository {
+	return &escalationRepository{db}
+}
+
+// GetPolicies returns user's escalation policies
+func (r *escalationRepository) GetPolicies(ctx context.Context, userId int) ([]entity.EscalationPolicy, error) {
+	q := getQueryable(ctx, r.db)
+	var policies []entity.EscalationPolicy
+
+	query := `SELECT id,
+      "name",
+      (SELECT json_agg(json_build_object(
+          'delay', delay,
+          'channel_id', channel_id
+      ) ORDER BY position)
+       FROM escalation_steps
+       WHERE policy_id = p.id) steps
+	FROM escalation_policies p
+	WHERE user_id = $1
+	ORDER BY id`
+	err := q.SelectContext(ctx, &policies, query, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+// GetPolicy returns user's escalation policy by id
+func (r *escalationRepository) GetPolicy(ctx context.Context, params entity.GetEscalationPolicy) (entity.EscalationPolicy, error) {
+	q := getQueryable(ctx, r.db)
+	var policy entity.EscalationPolicy
+
+	query := `SELECT id,
+      "name",
+      (SELECT json_agg(json_build_object(
+          'delay', delay,
+          'channel_id', channel_id
+      ) ORDER BY position)
+       FROM escalation_steps
+       WHERE policy_id = p.id) steps
+	FROM escalation_policies p
+	WHERE id = $1 AND user_id = $2`
+	err := q.GetContext(ctx, &policy, query, params.Id, params.UserId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = errors.E(errors.NotExist, "escalation policy not found")
+		}
+		return policy, err
+	}
+
+	return policy, nil
+}
+
+// CreatePolicy creates escalation policy and returns its id
+func (r *escalationRepository) CreatePolicy(ctx context.Context, policy entity.CreateEscalationPolicy) (int, error) {
+	q := getQueryable(ctx, r.db)
+
+	var id int
+	query := `INSERT INTO escalation_policies ("name", user_id) VALUES ($1, $2) RETURNING id`
+	err := q.QueryRowxContext(ctx, query, policy.Name, policy.UserId).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// UpdatePolicy updates escalation policy
+func (r *escalationRepository) UpdatePolicy(ctx context.Context, policy entity.UpdateEscalationPolicy) error {
+	q := getQueryable(ctx, r.db)
+
+	query := `UPDATE escalation_policies SET "name" = $1 WHERE id = $2 AND user_id = $3`
+	result, err := q.ExecContext(ctx, query, policy.Name, policy.Id, policy.UserId)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected != 1 {
+		return errors.E(errors.NotExist, "escalation policy not found")
+	}
+
+	return nil
+}
+
+// DeletePolicy deletes escalation policy. Its open escalations are deleted too
+func (r *escalationRepository) DeletePolicy(ctx context.Context, policy entity.DeleteEscalationPolicy) error {
+	q := getQueryable(ctx, r.db)
+
+	query := "DELETE FROM escalation_policies WHERE id = $1 AND user_id = $2"
+	result, err := q.ExecContext(ctx, query, policy.Id, policy.UserId)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected != 1 {
+		return errors.E(errors.NotExist, "escalation policy not found")
+	}
+
+	return nil
+}
+
+// SetSteps replaces steps of escalation policy. Channels of the steps must belong to the policy's user
+func (r *escalationRepository) SetSteps(ctx context.Context, params entity.SetEscalationSteps) error {
+	q := getQueryable(ctx, r.db)
+
+	_, err := q.ExecContext(ctx, "DELETE FROM escalation_steps WHERE policy_id = $1", params.Id)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO escalation_steps (policy_id, position, delay, channel_id)
+	SELECT $1, $2, $3, id
+	FROM channels
+	WHERE id = $4 AND user_id = $5`
+	for i, step := range params.Steps {
+		result, err := q.ExecContext(ctx, query, params.Id, i, step.Delay, step.ChannelId, params.UserId)
+		if err != nil {
+			return err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected != 1 {
+			return errors.E(errors.NotExist, "channel not found")
+		}
+	}
+
+	return nil
+}
+
+// Open opens escalations for down flips of checks with escalation policy and returns ids of the checks. Flips of
+// checks which are not down anymore are skipped
+func (r *escalationRepository) Open(ctx context.Context, flipIds []int) ([]string, error) {
+	q := getQueryable(ctx, r.db)
+	var checkIds []string
+
+	query, args, err := sqlx.In(`INSERT INTO escalations (check_id, flip_id, policy_id, started_at)
+	SELECT f.check_id, f.id, ch.escalation_policy_id, f.date
+	FROM flips f
+	INNER JOIN checks ch on ch.id = f.check_id
+	WHERE f.id IN (?)
+	  AND f."to" = 'down' AND f.suppressed = false
+	  AND ch.status = 'down' AND ch.escalation_policy_id IS NOT NULL
+	RETURNING check_id`, flipIds)
+	if err != nil {
+		return nil, err
+	}
+	query = r.db.Rebind(query)
+	err = q.SelectContext(ctx, &checkIds, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return checkIds, nil
+}
+
+// GetDue returns steps of open escalations which delay has passed and which have not been notified yet
+func (r *escalationRepository) GetDue(ctx context.Context) ([]entity.EscalationStepDue, error) {
+	q := getQueryable(ctx, r.db)
+	var steps []entity.EscalationStepDue
+
+	query := `SELECT e.id escalation_id,
+      s.position,
+      e.check_id,
+      ch.name,
+      COALESCE(f.reason::text, '') reason,
+      f.exit_code,
+      f.date,
+      (SELECT id FROM incidents WHERE check_id = e.check_id AND started_at = f.date) incident_id,
+      json_build_array(` + channel + `) channels
+	FROM escalations e
+	INNER JOIN checks ch on ch.id = e.check_id
+	INNER JOIN flips f on f.id = e.flip_id
+	INNER JOIN escalation_steps s on s.policy_id = e.policy_id AND s.position >= e.next_step
+	INNER JOIN channels c on c.id = s.channel_id
+	WHERE e.closed = false
+	  AND ch.status = 'down'
+	  AND current_timestamp >= e.started_at + (concat(s.delay, 's'))::interval
+	ORDER BY e.id, s.position
+	FOR UPDATE OF e SKIP LOCKED`
+	err := q.SelectContext(ctx, &steps, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return steps, nil
+}
+
+// Advance sets the next step of escalation which is going to be notified
+func (r *escalationRepository) Advance(ctx context.Context, id int, nextStep int) error {
+	q := getQueryable(ctx, r.db)
+
+	_, err := q.ExecContext(ctx, "UPDATE escalations SET next_step = $1 WHERE id = $2", nextStep, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Close closes open escalations of the checks, so their pending steps are cancelled. Returns the closed escalations
+// with channels of the steps that were notified already, which are empty if no step has been notified
+func (r *escalationRepository) Close(ctx context.Context, checkIds []string) ([]entity.EscalationClosed, error) {
+	q := getQueryable(ctx, r.db)
+	var closed []entity.EscalationClosed
+
+	query, args, err := sqlx.In(`WITH closed AS (
+		UPDATE escalations
+		SET closed = true
+		WHERE closed = false AND check_id IN (?)
+		RETURNING check_id, policy_id, next_step
+	)
+	SELECT cl.check_id,
+	  COALESCE(json_agg(`+channel+`) FILTER (WHERE c.id IS NOT NULL), '[]') channels
+	FROM closed cl
+	LEFT JOIN escalation_steps s on s.policy_id = cl.policy_id AND s.position < cl.next_step
+	LEFT JOIN channels c on c.id = s.channel_id
+	GROUP BY cl.check_id`, checkIds)
+	if err != nil {
+		return nil, err
+	}
+	query = r.db.Rebind(query)
+	err = q.SelectContext(ctx, &closed, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return closed, nil
+}
+
+// CloseStale closes open escalations of checks which are not down anymore, e.g. paused ones
+func (r *escalationRepository) CloseStale(ctx context.Context) error {
+	q := getQueryable(ctx, r.db)
+
+	query := `UPDATE escalations e
+	SET closed = true
+	FROM checks ch
+	WHERE ch.id = e.check_id AND e.closed = false AND ch.status <> 'down'`
+	_, err := q.ExecContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/jmoiron/sqlx"
 	"gitlab.com/grygoryz/uptime-checker/internal/entity"
 	"strings"
+	"time"
 )
 
 type flipRepository struct {
@@ -19,8 +20,10 @@ func NewFlip(db *sqlx.DB) *flipRepository {
 func (r *flipRepository) Create(ctx context.Context, flip entity.CreateFlip) error {
 	q := getQueryable(ctx, r.db)
 
-	query := `INSERT INTO flips ("to", "date", check_id) VALUES ($1, $2, $3)`
-	_, err := q.ExecContext(ctx, query, flip.To, flip.Date, flip.CheckId)
+	// suppressed flips are not going to be sent, so they are processed already
+	query := `INSERT INTO flips ("to", reason, exit_code, suppressed, processed, "date", check_id)
+	VALUES ($1, NULLIF($2::text, '')::flip_reason, $3, $4, $4, $5, $6)`
+	_, err := q.ExecContext(ctx, query, flip.To, flip.Reason, flip.ExitCode, flip.Suppressed, flip.Date, flip.CheckId)
 	if err != nil {
 		return err
 	}
@@ -49,7 +52,7 @@ func (r *flipRepository) GetMany(ctx context.Context, params entity.GetFlips) ([
 	q := getQueryable(ctx, r.db)
 	var flips []entity.Flip
 
-	query := `SELECT "date", "to"
+	query := `SELECT "date", "to", COALESCE(reason::text, '') reason, exit_code, suppressed
     FROM flips
 	WHERE check_id = $1 AND date >= $2 AND date <= $3
 	ORDER BY date DESC
@@ -71,19 +74,19 @@ func (r *flipRepository) GetUnprocessed(ctx context.Context) ([]entity.FlipUnpro
     f.id,
     "date",
     "to",
+    COALESCE(reason::text, '') reason,
+    exit_code,
+    f.check_id,
     ch.name,
-    (SELECT json_agg(json_build_object(
-           'kind', kind,
-           'email', email,
-           'webhook_url_up', webhook_url_up,
-           'webhook_url_down', webhook_url_down
-    )) channels
+    ch.status,
+    (SELECT id FROM incidents WHERE check_id = f.check_id AND started_at = f.date AND f."to" = 'down') incident_id,
+    (SELECT json_agg(` + channel + `) channels
     FROM checks_channels
-    INNER JOIN channels on checks_channels.channel_id = channels.id
+    INNER JOIN channels c on checks_channels.channel_id = c.id
     WHERE checks_channels.check_id = ch.id) channels
     FROM flips f
     INNER JOIN checks ch on ch.id = f.check_id
-	WHERE processed = false AND "to" IN ('up', 'down')
+	WHERE processed = false AND suppressed = false AND "to" IN ('up', 'down', 'flapping', 'stable')
 	ORDER BY date
 	FOR UPDATE SKIP LOCKED`
 	err := q.SelectContext(ctx, &flips, query)
@@ -98,12 +101,22 @@ func (r *flipRepository) GetUnprocessed(ctx context.Context) ([]entity.FlipUnpro
 func (r *flipRepository) CreateMany(ctx context.Context, flips []entity.CreateFlip) ([]int, error) {
 	q := getQueryable(ctx, r.db)
 
+	// suppressed flips are not going to be sent, so they are processed already
 	var qb strings.Builder
-	qb.WriteString(`INSERT INTO flips ("to", "date", check_id) VALUES `)
-	params := make([]interface{}, 0, len(flips)*3)
+	qb.WriteString(`INSERT INTO flips ("to", reason, exit_code, suppressed, processed, "date", check_id) VALUES `)
+	params := make([]interface{}, 0, len(flips)*7)
 	for _, flip := range flips {
-		qb.WriteString(`(?, ?, ?),`)
-		params = append(params, flip.To, flip.Date, flip.CheckId)
+		qb.WriteString(`(?, NULLIF(?::text, '')::flip_reason, ?, ?, ?, ?, ?),`)
+		params = append(
+			params,
+			flip.To,
+			flip.Reason,
+			flip.ExitCode,
+			flip.Suppressed,
+			flip.Suppressed,
+			flip.Date,
+			flip.CheckId,
+		)
 	}
 	query := qb.String()
 	// rebind and remove trailing comma
@@ -128,6 +141,22 @@ func (r *flipRepository) CreateMany(ctx context.Context, flips []entity.CreateFl
 	return ids, nil
 }
 
+// CountChanges returns number of check's flips to up or down since the time
+func (r *flipRepository) CountChanges(ctx context.Context, checkId string, since time.Time) (int, error) {
+	q := getQueryable(ctx, r.db)
+	var total int
+
+	query := `SELECT count(*)
+    FROM flips
+	WHERE check_id = $1 AND "to" IN ('up', 'down') AND date > $2`
+	err := q.GetContext(ctx, &total, query, checkId, since)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
 func (r *flipRepository) SetProcessed(ctx context.Context, flipIds []int) error {
 	q := getQueryable(ctx, r.db)
 
@@ -142,3 +171,33 @@ func (r *flipRepository) SetProcessed(ctx context.Context, flipIds []int) error
 
 	return nil
 }
+
+// GetChanges returns flips which change state of the checks within the period, preceded by the last such flip of
+// each check before the period, which defines check's state at its start. Flips are ordered by check and date
+func (r *flipRepository) GetChanges(ctx context.Context, params entity.GetFlipChanges) ([]entity.FlipChange, error) {
+	q := getQueryable(ctx, r.db)
+	var flips []entity.FlipChange
+
+	query, args, err := sqlx.In(`SELECT check_id, "to", "date"
+	FROM (
+		(SELECT DISTINCT ON (check_id) id, check_id, "to", "date"
+		FROM flips
+		WHERE check_id IN (?) AND "to" IN ('up', 'down', 'paused', 'resumed') AND date < ?
+		ORDER BY check_id, date DESC, id DESC)
+		UNION ALL
+		SELECT id, check_id, "to", "date"
+		FROM flips
+		WHERE check_id IN (?) AND "to" IN ('up', 'down', 'paused', 'resumed') AND date >= ? AND date < ?
+	) f
+	ORDER BY check_id, date, id`, params.CheckIds, params.From, params.CheckIds, params.From, params.To)
+	if err != nil {
+		return nil, err
+	}
+	query = r.db.Rebind(query)
+	err = q.SelectContext(ctx, &flips, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return flips, nil
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/jmoiron/sqlx"
+	"gitlab.com/grygoryz/uptime-checker/internal/entity"
+	"gitlab.com/grygoryz/uptime-checker/internal/utility/errors"
+)
+
+type incidentRepository struct {
+	db *sqlx.DB
+}
+
+func NewIncident(db *sqlx.DB) *incidentRepository {
+	return &incidentRepository{db}
+}
+
+// incidentColumns are columns of incident aliased as i of check aliased as ch
+const incidentColumns = `i.id,
+      i.check_id,
+      ch.name check_name,
+      ch.used_id user_id,
+      COALESCE(i.cause::text, '') cause,
+      i.started_at,
+      i.ended_at,
+      EXTRACT(EPOCH FROM i.ended_at - i.started_at)::int duration,
+      i.acknowledged_at,
+      i.acknowledged_by,
+      (SELECT json_agg(json_build_object(
+          'text', n.text,
+          'author', n.author,
+          'created_at', n.created_at
+      ) ORDER BY n.created_at)
+       FROM incident_notes n
+       WHERE n.incident_id = i.id) notes`
+
+// Open opens incident of the check unless the check has open incident already, and returns id of the open
+// incident
+func (r *incidentRepository) Open(ctx context.Context, incident entity.OpenIncident) (int, error) {
+	q := getQueryable(ctx, r.db)
+
+	var id int
+	query := `WITH opened AS (
+		INSERT INTO incidents (check_id, cause, started_at)
+		SELECT $1, NULLIF($2::text, '')::flip_reason, $3
+		WHERE NOT EXISTS (SELECT 1 FROM incidents WHERE check_id = $1 AND ended_at IS NULL)
+		RETURNING id
+	)
+	SELECT id FROM opened
+	UNION ALL
+	SELECT id FROM incidents WHERE check_id = $1 AND ended_at IS NULL
+	LIMIT 1`
+	err := q.QueryRowxContext(ctx, query, incident.CheckId, incident.Cause, incident.StartedAt).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Close ends open incident of the check
+func (r *incidentRepository) Close(ctx context.Context, incident entity.CloseIncident) error {
+	q := getQueryable(ctx, r.db)
+
+	query := "UPDATE incidents SET ended_at = $1 WHERE check_id = $2 AND ended_at IS NULL"
+	_, err := q.ExecContext(ctx, query, incident.EndedAt, incident.CheckId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Get returns incident by id
+func (r *incidentRepository) Get(ctx context.Context, id int) (entity.Incident, error) {
+	q := getQueryable(ctx, r.db)
+	var incident entity.Incident
+
+	query := `SELECT ` + incidentColumns + `
+	FROM incidents i
+	INNER JOIN checks ch on ch.id = i.check_id
+	WHERE i.id = $1`
+	err := q.GetContext(ctx, &incident, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = errors.E(errors.NotExist, "incident not found")
+		}
+		return incident, err
+	}
+
+	return incident, nil
+}
+
+// GetTotal returns number of user's incidents which started within the period
+func (r *incidentRepository) GetTotal(ctx context.Context, params entity.GetIncidentsTotal) (int, error) {
+	q := getQueryable(ctx, r.db)
+	var total int
+
+	query := `SELECT count(*)
+	FROM incidents i
+	INNER JOIN checks ch on ch.id = i.check_id
+	WHERE ch.used_id = $1 AND ($2 = '' OR i.check_id::text = $2) AND i.started_at >= $3 AND i.started_at <= $4`
+	err := q.GetContext(ctx, &total, query, params.UserId, params.CheckId, params.From, params.To)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// GetMany returns user's incidents which started within the period, the latest first
+func (r *incidentRepository) GetMany(ctx context.Context, params entity.GetIncidents) ([]entity.Incident, error) {
+	q := getQueryable(ctx, r.db)
+	var incidents []entity.Incident
+
+	query := `SELECT ` + incidentColumns + `
+	FROM incidents i
+	INNER JOIN checks ch on ch.id = i.check_id
+	WHERE ch.used_id = $1 AND ($2 = '' OR i.check_id::text = $2) AND i.started_at >= $3 AND i.started_at <= $4
+	ORDER BY i.started_at DESC
+	LIMIT $5 OFFSET $6`
+	err := q.SelectContext(
+		ctx,
+		&incidents,
+		query,
+		params.UserId,
+		params.CheckId,
+		params.From,
+		params.To,
+		params.Limit,
+		params.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return incidents, nil
+}
+
+// Acknowledge records who acknowledged incident and when
+func (r *incidentRepository) Acknowledge(ctx context.Context, params entity.AcknowledgeIncident) error {
+	q := getQueryable(ctx, r.db)
+
+	query := "UPDATE incidents SET acknowledged_at = current_timestamp, acknowledged_by = $1 WHERE id = $2"
+	_, err := q.ExecContext(ctx, query, params.By, params.Id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AddNote adds note to incident
+func (r *incidentRepository) AddNote(ctx context.Context, params entity.AddIncidentNote) error {
+	q := getQueryable(ctx, r.db)
+
+	query := `INSERT INTO incident_notes (incident_id, "text", author) VALUES ($1, $2, $3)`
+	_, err := q.ExecContext(ctx, query, params.Id, params.Text, params.Author)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"github.com/jmoiron/sqlx"
+	"gitlab.com/grygoryz/uptime-checker/internal/entity"
+)
+
+type instanceRepository struct {
+	db *sqlx.DB
+}
+
+func NewInstance(db *sqlx.DB) *instanceRepository {
+	return &instanceRepository{db}
+}
+
+// Ping creates instance of fleet check or updates its state after the ping
+func (r *instanceRepository) Ping(ctx context.Context, instance entity.PingInstance) error {
+	q := getQueryable(ctx, r.db)
+
+	query := `INSERT INTO check_instances (check_id, name, status, last_ping, next_ping)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (check_id, name) DO UPDATE
+	SET status    = excluded.status,
+	    last_ping = excluded.last_ping,
+	    next_ping = excluded.next_ping`
+	_, err := q.ExecContext(
+		ctx,
+		query,
+		instance.CheckId,
+		instance.Name,
+		instance.Status,
+		instance.Date,
+		instance.NextPing,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetMany returns instances of fleet check ordered by name
+func (r *instanceRepository) GetMany(ctx context.Context, checkId string) (entity.Instances, error) {
+	q := getQueryable(ctx, r.db)
+	var instances entity.Instances
+
+	query := `SELECT name, status, last_ping, next_ping
+	FROM check_instances
+	WHERE check_id = $1
+	ORDER BY name`
+	err := q.SelectContext(ctx, &instances, query, checkId)
+	if err != nil {
+		return nil, err
+	}
+
+	return instances, nil
+}
+
+// SetMissed sets up instances which next ping plus grace period of their check has passed down and returns them.
+// Instances of checks which don't expect pings, e.g. paused or down ones, are skipped
+func (r *instanceRepository) SetMissed(ctx context.Context) ([]entity.InstanceMissed, error) {
+	q := getQueryable(ctx, r.db)
+	var instances []entity.InstanceMissed
+
+	query := `UPDATE check_instances i
+	SET status    = 'down',
+	    next_ping = NULL
+	FROM (
+	    SELECT ci.check_id, ci.name, ci.next_ping + (concat(ch.grace, 's'))::interval expired_at
+	    FROM check_instances ci
+	    INNER JOIN checks ch ON ch.id = ci.check_id
+	    WHERE ch.fleet AND ch.status IN ('up', 'late', 'started') AND ci.status = 'up'
+	      AND current_timestamp > ci.next_ping + (concat(ch.grace, 's'))::interval
+	    FOR UPDATE OF ci SKIP LOCKED
+	) missed
+	WHERE i.check_id = missed.check_id AND i.name = missed.name
+	RETURNING i.check_id, i.name, missed.expired_at`
+	err := q.SelectContext(ctx, &instances, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return instances, nil
+}
+
+// DeleteExpired deletes instances which haven't pinged their check for its instance TTL
+func (r *instanceRepository) DeleteExpired(ctx context.Context) error {
+	q := getQueryable(ctx, r.db)
+
+	query := `DELETE FROM check_instances i
+	USING checks ch
+	WHERE ch.id = i.check_id AND current_timestamp > i.last_ping + (concat(ch.instance_ttl, 's'))::interval`
+	_, err := q.ExecContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
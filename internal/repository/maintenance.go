@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"github.com/jmoiron/sqlx"
+	"gitlab.com/grygoryz/uptime-checker/internal/entity"
+	"gitlab.com/grygoryz/uptime-checker/internal/utility/errors"
+	"time"
+)
+
+// inMaintenance is SQL condition which is true when check aliased as ch is in active maintenance window
+const inMaintenance = `EXISTS (
+	SELECT 1
+	FROM maintenance_windows w
+	WHERE w.user_id = ch.used_id
+	  AND current_timestamp >= w.next_start AND current_timestamp < w.next_end
+	  AND (string_to_array(ch.tags, ' ') && string_to_array(w.tags, ' ')
+	       OR EXISTS (SELECT 1 FROM maintenance_windows_checks WHERE window_id = w.id AND check_id = ch.id))
+)`
+
+type maintenanceRepository struct {
+	db *sqlx.DB
+}
+
+func NewMaintenance(db *sqlx.DB) *maintenanceRepository {
+	return &maintenanceRepository{db}
+}
+
+// GetMany returns user's maintenance windows
+func (r *maintenanceRepository) GetMany(ctx context.Context, userId int) ([]entity.MaintenanceWindow, error) {
+	q := getQueryable(ctx, r.db)
+	var windows []entity.MaintenanceWindow
+
+	query := `SELECT id,
+      "name",
+      starts_at,
+      cron,
+      timezone,
+      duration,
+      tags,
+      next_start,
+      next_end,
+      (SELECT json_agg(check_id) FROM maintenance_windows_checks WHERE window_id = w.id) checks
+	FROM maintenance_windows w
+	WHERE user_id = $1
+	ORDER BY id`
+	err := q.SelectContext(ctx, &windows, query, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return windows, nil
+}
+
+// Create creates maintenance window and returns its id
+func (r *maintenanceRepository) Create(ctx context.Context, window entity.CreateMaintenanceWindow) (int, error) {
+	q := getQueryable(ctx, r.db)
+
+	var id int
+	query := `INSERT INTO maintenance_windows
+    ("name", starts_at, cron, timezone, duration, tags, next_start, next_end, user_id)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	RETURNING id`
+	err := q.
+		QueryRowxContext(
+			ctx,
+			query,
+			window.Name,
+			window.StartsAt,
+			window.Cron,
+			window.Timezone,
+			window.Duration,
+			window.Tags,
+			window.NextStart,
+			window.NextEnd,
+			window.UserId,
+		).
+		Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Update updates maintenance window
+func (r *maintenanceRepository) Update(ctx context.Context, window entity.UpdateMaintenanceWindow) error {
+	q := getQueryable(ctx, r.db)
+
+	query := `UPDATE maintenance_windows
+	SET "name"     = $1,
+	    starts_at  = $2,
+	    cron       = $3,
+	    timezone   = $4,
+	    duration   = $5,
+	    tags       = $6,
+	    next_start = $7,
+	    next_end   = $8
+	WHERE id = $9 AND user_id = $10`
+	result, err := q.ExecContext(
+		ctx,
+		query,
+		window.Name,
+		window.StartsAt,
+		window.Cron,
+		window.Timezone,
+		window.Duration,
+		window.Tags,
+		window.NextStart,
+		window.NextEnd,
+		window.Id,
+		window.UserId,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected != 1 {
+		return errors.E(errors.NotExist, "maintenance window not found")
+	}
+
+	return nil
+}
+
+// Delete deletes maintenance window
+func (r *maintenanceRepository) Delete(ctx context.Context, window entity.DeleteMaintenanceWindow) error {
+	q := getQueryable(ctx, r.db)
+
+	query := "DELETE FROM maintenance_windows WHERE id = $1 AND user_id = $2"
+	result, err := q.ExecContext(ctx, query, window.Id, window.UserId)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected != 1 {
+		return errors.E(errors.NotExist, "maintenance window not found")
+	}
+
+	return nil
+}
+
+// SetChecks replaces checks of the maintenance window. All the checks must belong to the window's user
+func (r *maintenanceRepository) SetChecks(ctx context.Context, params entity.SetMaintenanceChecks) error {
+	q := getQueryable(ctx, r.db)
+
+	_, err := q.ExecContext(ctx, "DELETE FROM maintenance_windows_checks WHERE window_id = $1", params.Id)
+	if err != nil {
+		return err
+	}
+	if len(params.Checks) == 0 {
+		return nil
+	}
+
+	query, args, err := sqlx.In(`INSERT INTO maintenance_windows_checks (window_id, check_id)
+	SELECT ?, id
+	FROM checks
+	WHERE used_id = ? AND id IN (?)`, params.Id, params.UserId, params.Checks)
+	if err != nil {
+		return err
+	}
+	query = r.db.Rebind(query)
+	result, err := q.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if int(affected) != len(params.Checks) {
+		return errors.E(errors.NotExist, "these checks does not exist")
+	}
+
+	return nil
+}
+
+// GetEnded returns maintenance windows which current period has ended with flips suppressed during the period
+func (r *maintenanceRepository) GetEnded(ctx context.Context) ([]entity.MaintenanceWindowEnded, error) {
+	q := getQueryable(ctx, r.db)
+	var windows []entity.MaintenanceWindowEnded
+
+	query := `SELECT w.id,
+      w.name,
+      w.starts_at,
+      w.cron,
+      w.timezone,
+      w.duration,
+      w.tags,
+      w.next_start,
+      w.next_end,
+      u.email,
+      (SELECT json_agg(json_build_object(
+          'check_name', ch.name,
+          'to', f."to",
+          'date', f.date
+      ) ORDER BY f.date)
+       FROM flips f
+       INNER JOIN checks ch on ch.id = f.check_id
+       WHERE ch.used_id = w.user_id
+         AND f.suppressed = true AND f."to" IN ('up', 'down')
+         AND f.date >= w.next_start AND f.date < w.next_end
+         AND (string_to_array(ch.tags, ' ') && string_to_array(w.tags, ' ')
+              OR EXISTS (SELECT 1 FROM maintenance_windows_checks WHERE window_id = w.id AND check_id = ch.id))
+      ) flips
+	FROM maintenance_windows w
+	INNER JOIN users u on u.id = w.user_id
+	WHERE current_timestamp >= w.next_end
+	FOR UPDATE OF w SKIP LOCKED`
+	err := q.SelectContext(ctx, &windows, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return windows, nil
+}
+
+// SetNext sets the next period of maintenance window, nil start and end mean that the window has ended
+func (r *maintenanceRepository) SetNext(ctx context.Context, id int, start *time.Time, end *time.Time) error {
+	q := getQueryable(ctx, r.db)
+
+	query := "UPDATE maintenance_windows SET next_start = $1, next_end = $2 WHERE id = $3"
+	_, err := q.ExecContext(ctx, query, start, end, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
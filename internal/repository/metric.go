@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"github.com/jmoiron/sqlx"
+	"gitlab.com/grygoryz/uptime-checker/internal/entity"
+)
+
+// maxMetricPoints limits metric history returned at once
+const maxMetricPoints = 10000
+
+type metricRepository struct {
+	db *sqlx.DB
+}
+
+func NewMetric(db *sqlx.DB) *metricRepository {
+	return &metricRepository{db}
+}
+
+// CreateValues stores values of check's metrics
+func (r *metricRepository) CreateValues(ctx context.Context, params entity.CreateMetricValues) error {
+	q := getQueryable(ctx, r.db)
+
+	query := "INSERT INTO metric_values (check_id, name, value, date) VALUES ($1, $2, $3, $4)"
+	for name, value := range params.Values {
+		_, err := q.ExecContext(ctx, query, params.CheckId, name, value, params.Date)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetHistory returns values of check's metric within the period, the earliest first
+func (r *metricRepository) GetHistory(ctx context.Context, params entity.GetMetricHistory) ([]entity.MetricPoint, error) {
+	q := getQueryable(ctx, r.db)
+	var points []entity.MetricPoint
+
+	query := `SELECT "date", value
+	FROM metric_values
+	WHERE check_id = $1 AND name = $2 AND date >= $3 AND date <= $4
+	ORDER BY date
+	LIMIT $5`
+	err := q.SelectContext(ctx, &points, query, params.CheckId, params.Name, params.From, params.To, maxMetricPoints)
+	if err != nil {
+		return nil, err
+	}
+
+	return points, nil
+}
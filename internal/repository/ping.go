@@ -21,8 +21,9 @@ func (r *pingRepository) Create(ctx context.Context, ping entity.CreatePing) err
 	q := getQueryable(ctx, r.db)
 
 	query := `INSERT INTO pings 
-   ("type", source, user_agent, duration, body, check_id, "date")
-	VALUES ($1, $2, $3, $4, $5, $6, $7)`
+   ("type", source, user_agent, duration, exit_code, run_id, body, check_id, "date", slow_threshold,
+    fast_threshold, body_rule, metric_rule, instance)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NULLIF($14, ''))`
 
 	_, err := q.ExecContext(
 		ctx,
@@ -31,9 +32,16 @@ func (r *pingRepository) Create(ctx context.Context, ping entity.CreatePing) err
 		ping.Source,
 		ping.UserAgent,
 		ping.Duration,
+		ping.ExitCode,
+		ping.RunId,
 		ping.Body,
 		ping.CheckId,
 		ping.Date,
+		ping.SlowThreshold,
+		ping.FastThreshold,
+		ping.BodyRule,
+		ping.MetricRule,
+		ping.Instance,
 	)
 	if err != nil {
 		return err
@@ -42,17 +50,22 @@ func (r *pingRepository) Create(ctx context.Context, ping entity.CreatePing) err
 	return nil
 }
 
-// GetLastTypeAndDate returns last ping's type and date
-func (r *pingRepository) GetLastTypeAndDate(ctx context.Context, checkId string) (*entity.PingTypeAndDate, error) {
+// GetLastTypeAndDate returns type and date of the last ping with the run id. Pings without run id
+// are looked up among pings without it
+func (r *pingRepository) GetLastTypeAndDate(
+	ctx context.Context,
+	checkId string,
+	runId *string,
+) (*entity.PingTypeAndDate, error) {
 	q := getQueryable(ctx, r.db)
 	var ping entity.PingTypeAndDate
 
 	query := `SELECT "type", "date"
     FROM pings
-	WHERE check_id = $1
+	WHERE check_id = $1 AND run_id IS NOT DISTINCT FROM $2::uuid
 	ORDER BY date DESC
 	LIMIT 1`
-	err := q.GetContext(ctx, &ping, query, checkId)
+	err := q.GetContext(ctx, &ping, query, checkId, runId)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			err = errors.E(errors.NotExist, "ping not found")
@@ -63,6 +76,35 @@ func (r *pingRepository) GetLastTypeAndDate(ctx context.Context, checkId string)
 	return &ping, nil
 }
 
+// maxBodyExcerpt limits length of ping bodies added to notifications
+const maxBodyExcerpt = 10000
+
+// GetLastBodies returns bodies of the last pings of the checks by check id, truncated to maxBodyExcerpt
+// characters. Checks without pings are omitted
+func (r *pingRepository) GetLastBodies(ctx context.Context, checkIds []string) (map[string]string, error) {
+	q := getQueryable(ctx, r.db)
+	var pings []entity.PingBody
+
+	query, args, err := sqlx.In(`SELECT DISTINCT ON (check_id) check_id, left(body, ?) body
+	FROM pings
+	WHERE check_id IN (?)
+	ORDER BY check_id, date DESC`, maxBodyExcerpt, checkIds)
+	if err != nil {
+		return nil, err
+	}
+	query = r.db.Rebind(query)
+	err = q.SelectContext(ctx, &pings, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	bodies := make(map[string]string, len(pings))
+	for _, ping := range pings {
+		bodies[ping.CheckId] = ping.Body
+	}
+	return bodies, nil
+}
+
 // GetTotal returns check's pings total number for specified period
 func (r *pingRepository) GetTotal(ctx context.Context, params entity.GetPingsTotal) (int, error) {
 	q := getQueryable(ctx, r.db)
@@ -84,7 +126,8 @@ func (r *pingRepository) GetMany(ctx context.Context, params entity.GetPings) ([
 	q := getQueryable(ctx, r.db)
 	var pings []entity.Ping
 
-	query := `SELECT id, "type", "date", source, user_agent, duration, body
+	query := `SELECT id, "type", "date", source, user_agent, duration, exit_code, run_id, body, slow_threshold,
+    fast_threshold, body_rule, metric_rule, instance
     FROM pings
 	WHERE check_id = $1 AND date >= $2 AND date <= $3
 	ORDER BY date DESC
@@ -96,3 +139,126 @@ func (r *pingRepository) GetMany(ctx context.Context, params entity.GetPings) ([
 
 	return pings, nil
 }
+
+// GetRunsTotal returns check's runs total number for specified period
+func (r *pingRepository) GetRunsTotal(ctx context.Context, params entity.GetRunsTotal) (int, error) {
+	q := getQueryable(ctx, r.db)
+	var total int
+
+	query := `SELECT count(*)
+    FROM pings
+	WHERE check_id = $1 AND "type" = 'start' AND date >= $2 AND date <= $3`
+	err := q.GetContext(ctx, &total, query, params.CheckId, params.From, params.To)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// GetRuns returns check's runs started in specified period. A run is finished by the next ping with the same
+// run id if it is success or fail one
+func (r *pingRepository) GetRuns(ctx context.Context, params entity.GetRuns) ([]entity.Run, error) {
+	q := getQueryable(ctx, r.db)
+	var runs []entity.Run
+
+	query := `SELECT
+    s.run_id,
+    s."date" "start",
+    f."date" "end",
+    f."type" outcome,
+    f.duration
+    FROM pings s
+    LEFT JOIN LATERAL (
+        SELECT "type", "date", duration
+        FROM pings
+        WHERE check_id = s.check_id AND run_id IS NOT DISTINCT FROM s.run_id AND "date" > s."date"
+        ORDER BY "date"
+        LIMIT 1
+    ) f ON f."type" <> 'start'
+	WHERE s.check_id = $1 AND s."type" = 'start' AND s.date >= $2 AND s.date <= $3
+	ORDER BY s.date DESC
+	LIMIT $4 OFFSET $5`
+	err := q.SelectContext(ctx, &runs, query, params.CheckId, params.From, params.To, params.Limit, params.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+// durationStats are statistics of durations of runs finished by pings
+const durationStats = `count(duration) runs,
+      COALESCE(min(duration), 0) "min",
+      COALESCE(avg(duration), 0)::float8 "avg",
+      COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY duration), 0) p50,
+      COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY duration), 0) p95,
+      COALESCE(max(duration), 0) "max"`
+
+// GetDurationStats returns statistics of durations of check's runs finished within the period
+func (r *pingRepository) GetDurationStats(ctx context.Context, params entity.GetDurationStats) (entity.DurationStats, error) {
+	q := getQueryable(ctx, r.db)
+	var stats entity.DurationStats
+
+	query := `SELECT ` + durationStats + `
+	FROM pings
+	WHERE check_id = $1 AND duration IS NOT NULL AND date >= $2 AND date < $3`
+	err := q.GetContext(ctx, &stats, query, params.CheckId, params.From, params.To)
+	if err != nil {
+		return stats, err
+	}
+
+	return stats, nil
+}
+
+// GetDurationSeries returns statistics of durations of check's runs finished within the period for every UTC
+// hour, day, week or month of the period which has runs
+func (r *pingRepository) GetDurationSeries(ctx context.Context, params entity.GetDurations) ([]entity.DurationStats, error) {
+	q := getQueryable(ctx, r.db)
+	var series []entity.DurationStats
+
+	query := `SELECT date_trunc($4, "date", 'UTC') start,
+      ` + durationStats + `
+	FROM pings
+	WHERE check_id = $1 AND duration IS NOT NULL AND date >= $2 AND date < $3
+	GROUP BY start
+	ORDER BY start`
+	err := q.SelectContext(ctx, &series, query, params.CheckId, params.From, params.To, params.Bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	return series, nil
+}
+
+// TakeWarnings marks unprocessed success pings which have finished slow or too fast runs as processed and returns
+// them. Too fast runs which have failed the check are notified by their flips
+func (r *pingRepository) TakeWarnings(ctx context.Context) ([]entity.PingWarning, error) {
+	q := getQueryable(ctx, r.db)
+	var pings []entity.PingWarning
+
+	query := `UPDATE pings
+	SET warning_processed = true
+	FROM checks ch
+	WHERE ch.id = pings.check_id
+	  AND (pings.slow_threshold IS NOT NULL OR (pings.fast_threshold IS NOT NULL AND pings."type" = 'success'))
+	  AND NOT pings.warning_processed
+	RETURNING
+	pings.check_id,
+	ch.name,
+	pings.duration,
+	pings.slow_threshold,
+	pings.fast_threshold,
+	pings.date,
+	` + inMaintenance + ` in_maintenance,
+	(SELECT json_agg(` + channel + `)
+       FROM checks_channels l
+       INNER JOIN channels c on l.channel_id = c.id
+       WHERE l.check_id = ch.id) channels`
+	err := q.SelectContext(ctx, &pings, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return pings, nil
+}
@@ -6,22 +6,32 @@ import (
 )
 
 type Registry struct {
-	db      *sqlx.DB
-	User    *userRepository
-	Channel *channelRepository
-	Check   *checkRepository
-	Ping    *pingRepository
-	Flip    *flipRepository
+	db          *sqlx.DB
+	User        *userRepository
+	Channel     *channelRepository
+	Check       *checkRepository
+	Ping        *pingRepository
+	Flip        *flipRepository
+	Maintenance *maintenanceRepository
+	Escalation  *escalationRepository
+	Incident    *incidentRepository
+	Metric      *metricRepository
+	Instance    *instanceRepository
 }
 
 func NewRegistry(db *sqlx.DB) *Registry {
 	return &Registry{
-		db:      db,
-		User:    NewUser(db),
-		Channel: NewChannel(db),
-		Check:   NewCheck(db),
-		Ping:    NewPing(db),
-		Flip:    NewFlip(db),
+		db:          db,
+		User:        NewUser(db),
+		Channel:     NewChannel(db),
+		Check:       NewCheck(db),
+		Ping:        NewPing(db),
+		Flip:        NewFlip(db),
+		Maintenance: NewMaintenance(db),
+		Escalation:  NewEscalation(db),
+		Incident:    NewIncident(db),
+		Metric:      NewMetric(db),
+		Instance:    NewInstance(db),
 	}
 }
 
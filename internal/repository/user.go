@@ -51,3 +51,49 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (entity.U
 
 	return user, nil
 }
+
+// GetIdByPingKey returns id of user with the ping key
+func (r *userRepository) GetIdByPingKey(ctx context.Context, pingKey string) (int, error) {
+	q := getQueryable(ctx, r.db)
+	var id int
+	err := q.GetContext(ctx, &id, "SELECT id FROM users WHERE ping_key = $1", pingKey)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = errors.E(errors.NotExist, "user not found")
+		}
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetPingKey returns user's ping key
+func (r *userRepository) GetPingKey(ctx context.Context, userId int) (string, error) {
+	q := getQueryable(ctx, r.db)
+	var pingKey string
+	err := q.GetContext(ctx, &pingKey, "SELECT ping_key FROM users WHERE id = $1", userId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = errors.E(errors.NotExist, "user not found")
+		}
+		return "", err
+	}
+
+	return pingKey, nil
+}
+
+// RegeneratePingKey replaces user's ping key with a new random one and returns it
+func (r *userRepository) RegeneratePingKey(ctx context.Context, userId int) (string, error) {
+	q := getQueryable(ctx, r.db)
+	var pingKey string
+	query := `UPDATE users SET ping_key = replace(gen_random_uuid()::text, '-', '') WHERE id = $1 RETURNING ping_key`
+	err := q.QueryRowxContext(ctx, query, userId).Scan(&pingKey)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = errors.E(errors.NotExist, "user not found")
+		}
+		return "", err
+	}
+
+	return pingKey, nil
+}
@@ -0,0 +1,41 @@
+// Package schedule calculates when the next ping of a check is expected according to its schedule.
+package schedule
+
+import (
+	"fmt"
+	"github.com/robfig/cron/v3"
+	"gitlab.com/grygoryz/uptime-checker/internal/entity"
+	"time"
+)
+
+// parser parses standard five-field cron expressions and descriptors like @daily
+var parser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// ValidCron reports whether expr is a valid cron expression
+func ValidCron(expr string) bool {
+	_, err := parser.Parse(expr)
+	return err == nil
+}
+
+// Next returns the time of the next expected ping after t. Cron expressions are evaluated in the schedule's
+// time zone, so runs keep their wall clock time across DST changes.
+func Next(s entity.Schedule, t time.Time) (time.Time, error) {
+	switch s.Kind {
+	case entity.ScheduleSimple:
+		return t.Add(time.Second * time.Duration(s.Interval)), nil
+	case entity.ScheduleCron:
+		sched, err := parser.Parse(s.Cron)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		loc, err := time.LoadLocation(s.Timezone)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		return sched.Next(t.In(loc)), nil
+	default:
+		return time.Time{}, fmt.Errorf("invalid schedule kind: %v", s.Kind)
+	}
+}
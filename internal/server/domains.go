@@ -5,6 +5,9 @@ import (
 	"gitlab.com/grygoryz/uptime-checker/internal/domain/auth"
 	"gitlab.com/grygoryz/uptime-checker/internal/domain/channel"
 	"gitlab.com/grygoryz/uptime-checker/internal/domain/check"
+	"gitlab.com/grygoryz/uptime-checker/internal/domain/escalation"
+	"gitlab.com/grygoryz/uptime-checker/internal/domain/incident"
+	"gitlab.com/grygoryz/uptime-checker/internal/domain/maintenance"
 	"gitlab.com/grygoryz/uptime-checker/internal/domain/ping"
 	"gitlab.com/grygoryz/uptime-checker/internal/repository"
 	"gitlab.com/grygoryz/uptime-checker/internal/session"
@@ -18,6 +21,9 @@ func (s *Server) initDomains() {
 	s.initChannel(registry, sessionRepo)
 	s.initCheck(registry, sessionRepo)
 	s.initPing(registry)
+	s.initMaintenance(registry, sessionRepo)
+	s.initEscalation(registry, sessionRepo)
+	s.initIncident(registry, sessionRepo)
 	s.initSwagger()
 }
 
@@ -41,6 +47,21 @@ func (s *Server) initPing(registry *repository.Registry) {
 	ping.RegisterHandler(s.router, service, s.validator)
 }
 
+func (s *Server) initMaintenance(registry *repository.Registry, sessionRepo *session.Repository) {
+	service := maintenance.NewService(registry)
+	maintenance.RegisterHandler(s.router, service, s.validator, sessionRepo)
+}
+
+func (s *Server) initEscalation(registry *repository.Registry, sessionRepo *session.Repository) {
+	service := escalation.NewService(registry)
+	escalation.RegisterHandler(s.router, service, s.validator, sessionRepo)
+}
+
+func (s *Server) initIncident(registry *repository.Registry, sessionRepo *session.Repository) {
+	service := incident.NewService(registry, s.cfg.Links.Secret)
+	incident.RegisterHandler(s.router, service, s.validator, sessionRepo)
+}
+
 func (s *Server) initSwagger() {
 	s.router.Get("/swagger/*", httpSwagger.Handler())
 }
@@ -0,0 +1,80 @@
+// Package uptime reconstructs check's states from its flips and reports how long the check stayed in each of them.
+package uptime
+
+import (
+	"gitlab.com/grygoryz/uptime-checker/internal/entity"
+	"time"
+)
+
+// Buckets splits the period into buckets of the kind. Buckets are aligned to UTC days, weeks starting on monday
+// and months, the first and the last buckets are cut to the period
+func Buckets(kind entity.UptimeBucket, from, to time.Time) []entity.Uptime {
+	var buckets []entity.Uptime
+	from, to = from.UTC(), to.UTC()
+
+	start := from
+	for start.Before(to) {
+		end := next(kind, start)
+		if end.After(to) {
+			end = to
+		}
+		buckets = append(buckets, entity.Uptime{Start: start, End: end})
+		start = end
+	}
+
+	return buckets
+}
+
+// next returns start of the bucket which follows the bucket containing t
+func next(kind entity.UptimeBucket, t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch kind {
+	case entity.UptimeWeek:
+		// weekday of sunday is 0, but week starts on monday
+		return day.AddDate(0, 0, 7-(int(day.Weekday())+6)%7)
+	case entity.UptimeMonth:
+		return time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day.AddDate(0, 0, 1)
+	}
+}
+
+// Add adds time which the check spent in up, down and paused states to the buckets. Changes are check's flips
+// ordered by date, the first of them may precede the buckets and define check's state at their start. Time before
+// the first flip, after the check is resumed until it goes up or down, and after now is not counted
+func Add(buckets []entity.Uptime, changes []entity.FlipChange, now time.Time) {
+	for i, change := range changes {
+		end := now
+		if i+1 < len(changes) && changes[i+1].Date.Before(now) {
+			end = changes[i+1].Date
+		}
+
+		for j := range buckets {
+			d := overlap(change.Date, end, buckets[j].Start, buckets[j].End)
+			if d <= 0 {
+				continue
+			}
+
+			switch change.To {
+			case entity.FlipUp:
+				buckets[j].Up += d
+			case entity.FlipDown:
+				buckets[j].Down += d
+			case entity.FlipPaused:
+				buckets[j].Paused += d
+			}
+		}
+	}
+}
+
+// overlap returns duration of the intersection of two periods
+func overlap(start1, end1, start2, end2 time.Time) time.Duration {
+	if start2.After(start1) {
+		start1 = start2
+	}
+	if end2.Before(end1) {
+		end1 = end2
+	}
+
+	return end1.Sub(start1)
+}
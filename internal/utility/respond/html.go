@@ -0,0 +1,27 @@
+package respond
+
+import (
+	"bytes"
+	"context"
+	"gitlab.com/grygoryz/uptime-checker/internal/utility/logger"
+	"html/template"
+	"net/http"
+)
+
+func HTML(ctx context.Context, w http.ResponseWriter, statusCode int, tmpl *template.Template, data interface{}) {
+	var body bytes.Buffer
+	err := tmpl.Execute(&body, data)
+	if err != nil {
+		Error(ctx, w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(statusCode)
+
+	_, err = w.Write(body.Bytes())
+	if err != nil {
+		log := logger.LogEntry(ctx)
+		log.Error().Err(err).Send()
+	}
+}
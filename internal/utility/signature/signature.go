@@ -0,0 +1,37 @@
+// Package signature signs data sent to users, so it can be trusted when they send it back, e.g. in links.
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sign returns hex encoded HMAC-SHA256 signature of the parts
+func Sign(secret string, parts ...string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is a valid signature of the parts
+func Verify(secret string, signature string, parts ...string) bool {
+	return hmac.Equal([]byte(signature), []byte(Sign(secret, parts...)))
+}
+
+// SignExpiring returns signature of the parts which is valid until the expiry time in unix seconds, the expiry is
+// signed along with the parts
+func SignExpiring(secret string, expires int64, parts ...string) string {
+	return Sign(secret, append(parts, strconv.FormatInt(expires, 10))...)
+}
+
+// VerifyExpiring reports whether signature is a valid expiring signature of the parts which hasn't expired yet
+func VerifyExpiring(secret string, signature string, expires int64, parts ...string) bool {
+	if time.Now().Unix() > expires {
+		return false
+	}
+	return Verify(secret, signature, append(parts, strconv.FormatInt(expires, 10))...)
+}
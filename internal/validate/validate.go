@@ -6,11 +6,22 @@ import (
 	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
 	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	"gitlab.com/grygoryz/uptime-checker/internal/schedule"
 	"gitlab.com/grygoryz/uptime-checker/internal/utility/errors"
 	"reflect"
+	"regexp"
 	"strings"
 )
 
+// slugRegexp matches lowercase slugs like "nightly-backup"
+var slugRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// telegramTokenRegexp matches telegram bot tokens like "123456:ABC-DEF1234ghIkl"
+var telegramTokenRegexp = regexp.MustCompile(`^[0-9]+:[A-Za-z0-9_-]+$`)
+
+// telegramChatRegexp matches numeric ids of telegram chats and usernames of public channels like "@uptime_alerts"
+var telegramChatRegexp = regexp.MustCompile(`^(-?[0-9]+|@[A-Za-z][A-Za-z0-9_]{4,31})$`)
+
 type Validator struct {
 	validator *validator.Validate
 	ut        ut.Translator
@@ -36,6 +47,43 @@ func New() *Validator {
 		panic(err)
 	}
 
+	err = v.RegisterValidation("cron", func(fl validator.FieldLevel) bool {
+		return schedule.ValidCron(fl.Field().String())
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	err = v.RegisterValidation("slug", func(fl validator.FieldLevel) bool {
+		return slugRegexp.MatchString(fl.Field().String())
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	err = v.RegisterValidation("telegram_token", func(fl validator.FieldLevel) bool {
+		return telegramTokenRegexp.MatchString(fl.Field().String())
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	err = v.RegisterValidation("telegram_chat", func(fl validator.FieldLevel) bool {
+		return telegramChatRegexp.MatchString(fl.Field().String())
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	registerTranslation(v, trans, "required_if", "{0} is a required field")
+	registerTranslation(v, trans, "required_unless", "{0} is a required field")
+	registerTranslation(v, trans, "required_without", "{0} is a required field")
+	registerTranslation(v, trans, "cron", "{0} must be a valid cron expression")
+	registerTranslation(v, trans, "timezone", "{0} must be a valid IANA time zone")
+	registerTranslation(v, trans, "slug", "{0} must contain only lowercase letters, digits, hyphens and underscores")
+	registerTranslation(v, trans, "telegram_token", "{0} must be a valid telegram bot token")
+	registerTranslation(v, trans, "telegram_chat", "{0} must be a numeric chat id or @username of a channel")
+
 	return &Validator{validator: v, ut: trans}
 }
 
@@ -53,3 +101,21 @@ func (v *Validator) Struct(s interface{}) error {
 
 	return nil
 }
+
+// registerTranslation registers english translation for the tag which has no default one
+func registerTranslation(v *validator.Validate, trans ut.Translator, tag string, text string) {
+	err := v.RegisterTranslation(
+		tag,
+		trans,
+		func(ut ut.Translator) error {
+			return ut.Add(tag, text, true)
+		},
+		func(ut ut.Translator, fe validator.FieldError) string {
+			t, _ := ut.T(tag, fe.Field())
+			return t
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,81 @@
+// Package webhook builds requests of webhook channels from notifications, the requests are signed with secrets of
+// the channels as described in package webhooksig. Body templates of the channels are text/templates which get
+// Data of the notification, e.g. {"text": {{json .CheckName}}, "status": "{{.Status}}"}, where json function
+// encodes the value as JSON string.
+package webhook
+
+import (
+	"encoding/json"
+	"gitlab.com/grygoryz/uptime-checker/internal/entity"
+	"gitlab.com/grygoryz/uptime-checker/pkg/webhooksig"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Data is data of the notification available to body templates
+type Data struct {
+	CheckId      string
+	CheckName    string
+	Status       entity.NotificationFlipStatus // up, down, late or still_down
+	Reason       entity.FlipReason             // reason of down notifications
+	FlipDate     string                        // RFC 3339 date of the flip
+	LastPingBody string
+}
+
+var funcs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// Parse parses body template
+func Parse(body string) (*template.Template, error) {
+	return template.New("body").Funcs(funcs).Option("missingkey=error").Parse(body)
+}
+
+// NewRequest returns request of the webhook channel to the url for the notification
+func NewRequest(
+	channel entity.ChannelShort,
+	url string,
+	notification entity.Notification,
+) (*http.Request, error) {
+	body := ""
+	if channel.WebhookBody != nil && *channel.WebhookBody != "" {
+		tmpl, err := Parse(*channel.WebhookBody)
+		if err != nil {
+			return nil, err
+		}
+
+		var sb strings.Builder
+		err = tmpl.Execute(&sb, Data{
+			CheckId:      notification.CheckId,
+			CheckName:    notification.CheckName,
+			Status:       notification.Status(),
+			Reason:       notification.FlipReason,
+			FlipDate:     notification.FlipDate.UTC().Format(time.RFC3339),
+			LastPingBody: notification.LastPingBody,
+		})
+		if err != nil {
+			return nil, err
+		}
+		body = sb.String()
+	}
+
+	method := channel.WebhookMethod
+	if method == "" {
+		method = http.MethodGet
+	}
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range channel.WebhookHeaders {
+		req.Header.Set(name, value)
+	}
+	webhooksig.SignRequest(req, channel.WebhookSecret, time.Now(), []byte(body))
+
+	return req, nil
+}
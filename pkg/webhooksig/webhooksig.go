@@ -0,0 +1,85 @@
+// Package webhooksig signs requests of webhook channels and lets their receivers verify them. A signed request
+// carries unix time it was sent at in TimestampHeader and hex encoded HMAC-SHA256 signature in SignatureHeader.
+// The signature is computed with the secret of the channel over the timestamp, method, URL and body of the
+// request joined with new lines.
+//
+// Receivers verify requests with the URL of the webhook as configured in the channel:
+//
+//	err := webhooksig.Verify(r, secret, "https://example.com/hooks/uptime", 5*time.Minute)
+//	if err != nil {
+//		w.WriteHeader(http.StatusUnauthorized)
+//		return
+//	}
+package webhooksig
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	TimestampHeader = "X-Uptime-Checker-Timestamp"
+	SignatureHeader = "X-Uptime-Checker-Signature"
+)
+
+var (
+	ErrNotSigned        = errors.New("webhooksig: request is not signed")
+	ErrExpired          = errors.New("webhooksig: request was signed too long ago")
+	ErrInvalidSignature = errors.New("webhooksig: invalid signature")
+)
+
+// Sign returns signature of the request with the body sent at the time to the url
+func Sign(secret string, timestamp time.Time, method string, url string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.Join([]string{
+		strconv.FormatInt(timestamp.Unix(), 10),
+		method,
+		url,
+		string(body),
+	}, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignRequest sets signature headers of the request with the body sent at the time
+func SignRequest(req *http.Request, secret string, timestamp time.Time, body []byte) {
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp.Unix(), 10))
+	req.Header.Set(SignatureHeader, Sign(secret, timestamp, req.Method, req.URL.String(), body))
+}
+
+// Verify returns error if the request received at the url is not signed with the secret or was signed more than
+// maxAge ago. The body of the request is read, so it's replaced with a copy which handlers can read again
+func Verify(r *http.Request, secret string, url string, maxAge time.Duration) error {
+	sig := r.Header.Get(SignatureHeader)
+	unix, err := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+	if sig == "" || err != nil {
+		return ErrNotSigned
+	}
+
+	timestamp := time.Unix(unix, 0)
+	if age := time.Since(timestamp); age > maxAge || age < -maxAge {
+		return ErrExpired
+	}
+
+	var body []byte
+	if r.Body != nil {
+		body, err = io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	if !hmac.Equal([]byte(sig), []byte(Sign(secret, timestamp, r.Method, url, body))) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package webhooksig_test
+
+import (
+	"gitlab.com/grygoryz/uptime-checker/pkg/webhooksig"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+const url = "https://example.com/hooks/uptime"
+
+func signedRequest(t *testing.T, secret string, timestamp time.Time, body string) *http.Request {
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	webhooksig.SignRequest(req, secret, timestamp, []byte(body))
+	return req
+}
+
+func TestVerify(t *testing.T) {
+	body := `{"status": "down"}`
+	req := signedRequest(t, "secret", time.Now(), body)
+
+	err := webhooksig.Verify(req, "secret", url, time.Minute)
+	if err != nil {
+		t.Fatalf("want request to be verified, got %v", err)
+	}
+	b, err := io.ReadAll(req.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != body {
+		t.Errorf("want body to be readable after verification, got %q", b)
+	}
+}
+
+func TestVerify_Invalid(t *testing.T) {
+	unsigned, _ := http.NewRequest(http.MethodPost, url, nil)
+	tampered := signedRequest(t, "secret", time.Now(), `{"status": "down"}`)
+	tampered.Body = io.NopCloser(strings.NewReader(`{"status": "up"}`))
+
+	cases := []struct {
+		name string
+		req  *http.Request
+		url  string
+		want error
+	}{
+		{name: "unsigned", req: unsigned, url: url, want: webhooksig.ErrNotSigned},
+		{name: "expired", req: signedRequest(t, "secret", time.Now().Add(-time.Hour), ""), url: url, want: webhooksig.ErrExpired},
+		{name: "wrong secret", req: signedRequest(t, "other", time.Now(), ""), url: url, want: webhooksig.ErrInvalidSignature},
+		{name: "wrong url", req: signedRequest(t, "secret", time.Now(), ""), url: url + "/other", want: webhooksig.ErrInvalidSignature},
+		{name: "tampered body", req: tampered, url: url, want: webhooksig.ErrInvalidSignature},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := webhooksig.Verify(c.req, "secret", c.url, time.Minute)
+			if err != c.want {
+				t.Errorf("want error %v, got %v", c.want, err)
+			}
+		})
+	}
+}